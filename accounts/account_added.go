@@ -9,6 +9,9 @@ import (
 type AccountAddedPayload struct {
 	Address                   flow.Address
 	InitializedFungibleTokens []templates.Token
+	// NonCustodial is set for watchlist accounts, whose tokens are discovered
+	// from chain instead of being initialized by the wallet
+	NonCustodial bool
 }
 
 type accountAddedHandler interface {
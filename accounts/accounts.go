@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -13,12 +14,71 @@ type AccountType string
 const AccountTypeCustodial = "custodial"
 const AccountTypeNonCustodial = "non-custodial"
 
+// AccountTypePooled is a pre-created custodial account in the account pool
+// which has not yet been handed out.
+const AccountTypePooled = "pooled"
+
 // Account struct represents a storable account.
 type Account struct {
-	Address   string          `json:"address" gorm:"primaryKey"`
-	Keys      []keys.Storable `json:"keys" gorm:"foreignKey:AccountAddress;references:Address;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	Type      AccountType     `json:"type" gorm:"default:custodial"`
-	CreatedAt time.Time       `json:"createdAt" `
-	UpdatedAt time.Time       `json:"updatedAt"`
-	DeletedAt gorm.DeletedAt  `json:"-" gorm:"index"`
+	Address    string          `json:"address" gorm:"primaryKey"`
+	ExternalID *string         `json:"externalId,omitempty" gorm:"uniqueIndex"` // Unique ID of the account holder in an external system
+	Labels     datatypes.JSON  `json:"labels,omitempty"`                        // Free-form JSON object
+	Keys       []keys.Storable `json:"keys" gorm:"foreignKey:AccountAddress;references:Address;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Type       AccountType     `json:"type" gorm:"default:custodial"`
+	ClosedAt   *time.Time      `json:"closedAt,omitempty"` // Set when the account is decommissioned
+	CreatedAt  time.Time       `json:"createdAt" `
+	UpdatedAt  time.Time       `json:"updatedAt"`
+	DeletedAt  gorm.DeletedAt  `json:"-" gorm:"index"`
+	Warnings   []string        `json:"warnings,omitempty" gorm:"-"` // Issues while creating the account, not stored
+}
+
+// AfterFind clears labels stored as NULL.
+func (a *Account) AfterFind(tx *gorm.DB) error {
+	if isNullJSON(a.Labels) {
+		a.Labels = nil
+	}
+	return nil
+}
+
+// IsClosed tells whether the account has been decommissioned.
+func (a Account) IsClosed() bool {
+	return a.ClosedAt != nil
+}
+
+// Metadata returns the metadata of the account.
+func (a Account) Metadata() Metadata {
+	return Metadata{ExternalID: a.ExternalID, Labels: a.Labels}
+}
+
+// Metadata links an account to a record in an external system.
+// Omitted fields are left unchanged when updating the metadata of an
+// account, an empty external ID or null labels clear them.
+type Metadata struct {
+	ExternalID *string        `json:"externalId,omitempty"`
+	Labels     datatypes.JSON `json:"labels,omitempty"`
+}
+
+// ListFilter filters listed accounts by metadata.
+type ListFilter struct {
+	ExternalID string
+	// Labels holds label keys and the string values they should equal.
+	// An empty value matches any account which has the label.
+	Labels map[string]string
+}
+
+// KeySpec describes a single logical key of a new custodial account.
+// Type defaults to the configured default key type and Weight to the
+// configured default key weight.
+type KeySpec struct {
+	Type   string `json:"type,omitempty"`
+	Weight int    `json:"weight,omitempty"`
+}
+
+// ImportKey describes an existing key of an account being imported into custody.
+// Value is the hex encoded private key for local keys or the key resource id
+// (or ARN) for keys in a remote key management system. The signature and hash
+// algorithms are taken from the matching on-chain key.
+type ImportKey struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
 }
@@ -0,0 +1,409 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/templates/template_strings"
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/access/grpc"
+	flow_templates "github.com/onflow/flow-go-sdk/templates"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+)
+
+const AccountCreateBatchJobType = "account_create_batch"
+
+type accountCreateBatchJobAttributes struct {
+	Count int `json:"count"`
+	// Batch transaction which has been signed but whose accounts have not
+	// been stored yet
+	Pending *pendingAccountBatch `json:"pending,omitempty"`
+}
+
+// pendingAccountBatch is a signed batch transaction. It is stored in the job
+// attributes, which are not exposed by the API, before the transaction is
+// sent, so a retried job stores the accounts created by the transaction
+// instead of sending a new one.
+type pendingAccountBatch struct {
+	TransactionID             string            `json:"transactionId"`
+	ReferenceBlockID          string            `json:"referenceBlockId"`
+	Keys                      [][]pendingKey    `json:"keys"`
+	InitializedFungibleTokens []templates.Token `json:"initializedFungibleTokens,omitempty"`
+}
+
+// pendingKey is a storable key including its encrypted value.
+type pendingKey struct {
+	keys.Storable
+	Value []byte `json:"value"`
+}
+
+// BatchProgress is the progress of a batch account creation. It is stored as
+// the metadata of the batch job.
+type BatchProgress struct {
+	Requested      int      `json:"requested"`
+	Created        int      `json:"created"`
+	Addresses      []string `json:"addresses"`
+	TransactionIDs []string `json:"transactionIds"`
+}
+
+// CreateBatch schedules a job creating count new custodial accounts,
+// AccountBatchSize accounts per transaction. The progress of the job,
+// including the addresses of the created accounts, is stored in the metadata
+// of the job.
+func (s *ServiceImpl) CreateBatch(ctx context.Context, count int) (*jobs.Job, error) {
+	log.WithFields(log.Fields{"count": count}).Trace("Create account batch")
+
+	if count < 1 || count > int(s.cfg.MaxAccountBatchCount) {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("count should be between 1 and %d", s.cfg.MaxAccountBatchCount),
+		}
+	}
+
+	attrBytes, err := json.Marshal(accountCreateBatchJobAttributes{Count: count})
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.wp.CreateJob(AccountCreateBatchJobType, "", jobs.WithAttributes(attrBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.wp.Schedule(job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (s *ServiceImpl) executeAccountCreateBatchJob(ctx context.Context, j *jobs.Job) error {
+	if j.Type != AccountCreateBatchJobType {
+		return jobs.ErrInvalidJobType
+	}
+
+	j.ShouldSendNotification = true
+
+	var attrs accountCreateBatchJobAttributes
+	if err := json.Unmarshal(j.Attributes, &attrs); err != nil {
+		return err
+	}
+
+	// A retried job continues from the progress of the previous execution
+	progress := BatchProgress{Requested: attrs.Count}
+	if len(j.Metadata) > 0 && string(j.Metadata) != "null" {
+		if err := json.Unmarshal(j.Metadata, &progress); err != nil {
+			return err
+		}
+	}
+
+	batchSize := int(s.cfg.AccountBatchSize)
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	for progress.Created < progress.Requested {
+		var flowTx *flow.Transaction
+
+		if attrs.Pending == nil {
+			n := progress.Requested - progress.Created
+			if n > batchSize {
+				n = batchSize
+			}
+
+			var err error
+			flowTx, attrs.Pending, err = s.signAccountBatch(ctx, n)
+			if err != nil {
+				return err
+			}
+
+			// Store the transaction before sending it
+			if err := s.saveAccountBatchJob(j, attrs, progress); err != nil {
+				return err
+			}
+		}
+
+		addresses, err := s.createAccountBatch(ctx, flowTx, attrs.Pending)
+		if err != nil {
+			return err
+		}
+
+		// No addresses if the transaction of a previous execution did not
+		// create the accounts, in which case a new transaction is sent
+		if addresses != nil {
+			progress.Created += len(addresses)
+			progress.Addresses = append(progress.Addresses, addresses...)
+			progress.TransactionIDs = append(progress.TransactionIDs, attrs.Pending.TransactionID)
+			j.TransactionID = attrs.Pending.TransactionID
+		}
+
+		attrs.Pending = nil
+
+		if err := s.saveAccountBatchJob(j, attrs, progress); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveAccountBatchJob stores the attributes and progress of a batch job.
+func (s *ServiceImpl) saveAccountBatchJob(j *jobs.Job, attrs accountCreateBatchJobAttributes, progress BatchProgress) error {
+	attrBytes, err := json.Marshal(attrs)
+	if err != nil {
+		return err
+	}
+
+	progressBytes, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+
+	j.Attributes = attrBytes
+	j.Metadata = progressBytes
+	j.Result = strconv.Itoa(progress.Created)
+
+	return s.wp.SaveProgress(j)
+}
+
+// signAccountBatch generates the keys of count accounts with the default key
+// spec and signs a transaction creating them. Admin account is used to pay
+// for the transaction.
+func (s *ServiceImpl) signAccountBatch(ctx context.Context, count int) (*flow.Transaction, *pendingAccountBatch, error) {
+	s.txRateLimiter.Take()
+
+	payer, err := s.km.AdminAuthorizer(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	specs := []KeySpec{{Type: s.cfg.DefaultKeyType, Weight: s.cfg.DefaultKeyWeight}}
+
+	publicKeyLists := make([][]*flow.AccountKey, count)
+	pendingKeyLists := make([][]pendingKey, count)
+	for i := 0; i < count; i++ {
+		publicKeys, storableKeys, err := s.generateAccountKeys(ctx, specs)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		publicKeyLists[i] = publicKeys
+		for _, k := range storableKeys {
+			pendingKeyLists[i] = append(pendingKeyLists[i], pendingKey{Storable: k, Value: k.Value})
+		}
+	}
+
+	flowTx, initializedFungibleTokens, err := s.generateCreateAccountsTransaction(publicKeyLists, payer.Address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.signAdminTransaction(ctx, flowTx, payer, s.cfg.ComputeLimit(AccountBatchComputeLimit)); err != nil {
+		return nil, nil, err
+	}
+
+	pending := &pendingAccountBatch{
+		TransactionID:             flowTx.ID().String(),
+		ReferenceBlockID:          flowTx.ReferenceBlockID.String(),
+		Keys:                      pendingKeyLists,
+		InitializedFungibleTokens: initializedFungibleTokens,
+	}
+
+	return flowTx, pending, nil
+}
+
+// createAccountBatch sends a signed batch transaction, or resolves the result
+// of a transaction sent by a previous execution of the job if flowTx is nil,
+// and stores the created accounts.
+//
+// Returns the addresses of the created accounts, or nil if the transaction
+// of a previous execution failed or expired.
+func (s *ServiceImpl) createAccountBatch(ctx context.Context, flowTx *flow.Transaction, pending *pendingAccountBatch) ([]string, error) {
+	var (
+		result *flow.TransactionResult
+		err    error
+	)
+
+	if flowTx != nil {
+		result, err = s.sendSignedAdminTransaction(ctx, flowTx)
+	} else {
+		result, err = s.pendingAccountBatchResult(ctx, pending)
+	}
+	if err != nil || result == nil {
+		return nil, err
+	}
+
+	// Accounts are created in the order of the key lists
+	newAddresses := createdAddresses(result.Events)
+	if len(newAddresses) != len(pending.Keys) {
+		return nil, jobs.PermanentFailure(
+			fmt.Errorf("expected %d created accounts in transaction %s, got %d", len(pending.Keys), pending.TransactionID, len(newAddresses)),
+		)
+	}
+
+	addresses := make([]string, len(newAddresses))
+	newAccounts := []*Account{}
+
+	for i, newAddress := range newAddresses {
+		addresses[i] = flow_helpers.FormatAddress(newAddress)
+
+		// Already stored by a previous execution of the job
+		if _, err := s.store.Account(addresses[i]); err == nil {
+			continue
+		}
+
+		storableKeys := make([]keys.Storable, len(pending.Keys[i]))
+		for k, pk := range pending.Keys[i] {
+			storableKeys[k] = pk.Storable
+			storableKeys[k].Value = pk.Value
+		}
+
+		newAccounts = append(newAccounts, &Account{
+			Address: addresses[i],
+			Type:    AccountTypeCustodial,
+			Keys:    storableKeys,
+		})
+	}
+
+	if err := s.store.InsertAccounts(newAccounts); err != nil {
+		log.
+			WithFields(log.Fields{"transactionId": pending.TransactionID, "error": err}).
+			Error("Could not store accounts created in batch")
+		return nil, err
+	}
+
+	for _, a := range newAccounts {
+		AccountAdded.Trigger(AccountAddedPayload{
+			Address:                   flow.HexToAddress(a.Address),
+			InitializedFungibleTokens: pending.InitializedFungibleTokens,
+		})
+	}
+
+	log.
+		WithFields(log.Fields{"count": len(addresses), "transactionId": pending.TransactionID, "initialized-fungible-tokens": pending.InitializedFungibleTokens}).
+		Info("Account batch created")
+
+	return addresses, nil
+}
+
+// pendingAccountBatchResult returns the sealed result of a batch transaction
+// of a previous execution of the job, or nil if the transaction failed or
+// can no longer be sealed.
+func (s *ServiceImpl) pendingAccountBatchResult(ctx context.Context, pending *pendingAccountBatch) (*flow.TransactionResult, error) {
+	txID := flow.HexToID(pending.TransactionID)
+
+	result, err := s.fc.GetTransactionResult(ctx, txID)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		switch {
+		case result.Error != nil || result.Status == flow.TransactionStatusExpired:
+			log.
+				WithFields(log.Fields{"transactionId": pending.TransactionID, "status": result.Status, "error": result.Error}).
+				Warn("Batch transaction did not create accounts, sending a new one")
+			return nil, nil
+		case result.Status == flow.TransactionStatusSealed:
+			return result, nil
+		case result.Status != flow.TransactionStatusUnknown && result.Status != flow.TransactionStatusPending:
+			// Included in a block, will be sealed
+			result, err := flow_helpers.WaitForSeal(ctx, s.fc, txID, s.cfg.TransactionTimeout)
+			if err != nil && result != nil {
+				return nil, nil
+			}
+			return result, err
+		}
+	}
+
+	// The transaction may not have been sent, it can be discarded once it
+	// can no longer be included in a block
+	sealed, err := s.fc.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	expired, err := flow_helpers.IsReferenceBlockExpired(ctx, s.fc, flow.HexToID(pending.ReferenceBlockID), sealed.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	if expired {
+		log.
+			WithFields(log.Fields{"transactionId": pending.TransactionID}).
+			Warn("Batch transaction expired, sending a new one")
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("batch transaction %s is pending", pending.TransactionID)
+}
+
+// generateCreateAccountsTransaction generates a transaction which creates an
+// account for each list of public keys. Enabled fungible token vaults are
+// initialized if configured.
+func (s *ServiceImpl) generateCreateAccountsTransaction(
+	publicKeyLists [][]*flow.AccountKey,
+	payerAddress flow.Address,
+) (
+	*flow.Transaction,
+	[]templates.Token,
+	error,
+) {
+	var initializedTokens []templates.Token
+	tokensInfo := []template_strings.FungibleTokenInfo{}
+
+	if s.cfg.InitFungibleTokenVaultsOnAccountCreation {
+		tokens, err := s.temps.ListTokensFull(templates.FT)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, t := range tokens {
+			if t.Name != "FlowToken" {
+				tokensInfo = append(tokensInfo, templates.NewFungibleTokenInfo(t))
+				initializedTokens = append(initializedTokens, t)
+			}
+		}
+	}
+
+	txScript, err := templates.CreateAccountsAndInitFungibleTokenVaultsCode(s.cfg.ChainID, tokensInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Encode the public key lists
+	keyLists := make([]cadence.Value, len(publicKeyLists))
+	for i, publicKeys := range publicKeyLists {
+		keyList := make([]cadence.Value, len(publicKeys))
+		for k, key := range publicKeys {
+			keyList[k], err = flow_templates.AccountKeyToCadenceCryptoKey(key)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		keyLists[i] = cadence.NewArray(keyList)
+	}
+
+	flowTx := flow.NewTransaction().
+		SetScript([]byte(txScript)).
+		AddAuthorizer(payerAddress).
+		AddRawArgument(jsoncdc.MustEncode(cadence.NewArray(keyLists)))
+
+	return flowTx, initializedTokens, nil
+}
+
+func isNotFound(err error) bool {
+	rpcErr, ok := err.(grpc.RPCError)
+	return ok && rpcErr.GRPCStatus().Code() == codes.NotFound
+}
@@ -0,0 +1,209 @@
+package accounts
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/templates/template_strings"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+)
+
+var validContractName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Contract is a Cadence contract deployed to an account.
+type Contract struct {
+	Name string `json:"name"`
+	Code string `json:"code"`
+}
+
+// Contracts returns the contracts deployed to an account, sorted by name.
+func (s *ServiceImpl) Contracts(ctx context.Context, address string) ([]Contract, error) {
+	log.WithFields(log.Fields{"address": address}).Trace("Account contracts")
+
+	deployed, err := s.deployedContracts(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	contracts := make([]Contract, 0, len(deployed))
+	for name, code := range deployed {
+		contracts = append(contracts, Contract{Name: name, Code: string(code)})
+	}
+
+	sort.Slice(contracts, func(i, j int) bool {
+		return contracts[i].Name < contracts[j].Name
+	})
+
+	return contracts, nil
+}
+
+// Contract returns a contract deployed to an account.
+func (s *ServiceImpl) Contract(ctx context.Context, address, name string) (*Contract, error) {
+	log.WithFields(log.Fields{"address": address, "name": name}).Trace("Account contract")
+
+	deployed, err := s.deployedContracts(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	code, ok := deployed[name]
+	if !ok {
+		return nil, contractNotFoundError(name)
+	}
+
+	return &Contract{Name: name, Code: string(code)}, nil
+}
+
+// DeployContract deploys a new contract to a custodial account.
+func (s *ServiceImpl) DeployContract(ctx context.Context, sync bool, address string, c Contract) (*jobs.Job, *transactions.Transaction, error) {
+	log.WithFields(log.Fields{"sync": sync, "address": address, "name": c.Name}).Trace("Deploy account contract")
+
+	if err := validateContract(c); err != nil {
+		return nil, nil, err
+	}
+
+	address, deployed, err := s.custodialContracts(ctx, address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, ok := deployed[c.Name]; ok {
+		return nil, nil, &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("contract %s is already deployed to the account", c.Name),
+		}
+	}
+
+	// The template transaction is trusted, the contract code is not
+	if err := s.txs.CheckCode([]string{address}, c.Code); err != nil {
+		return nil, nil, err
+	}
+
+	args := []transactions.Argument{
+		cadence.String(c.Name),
+		cadence.String(hex.EncodeToString([]byte(c.Code))),
+	}
+
+	return s.txs.Create(ctx, sync, address, template_strings.AddAccountContractTransaction, args, transactions.ContractDeploy, transactions.TrustedCode())
+}
+
+// UpdateContract updates the code of a contract deployed to a custodial account.
+func (s *ServiceImpl) UpdateContract(ctx context.Context, sync bool, address string, c Contract) (*jobs.Job, *transactions.Transaction, error) {
+	log.WithFields(log.Fields{"sync": sync, "address": address, "name": c.Name}).Trace("Update account contract")
+
+	if err := validateContract(c); err != nil {
+		return nil, nil, err
+	}
+
+	address, deployed, err := s.custodialContracts(ctx, address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, ok := deployed[c.Name]; !ok {
+		return nil, nil, contractNotFoundError(c.Name)
+	}
+
+	// The template transaction is trusted, the contract code is not
+	if err := s.txs.CheckCode([]string{address}, c.Code); err != nil {
+		return nil, nil, err
+	}
+
+	args := []transactions.Argument{
+		cadence.String(c.Name),
+		cadence.String(hex.EncodeToString([]byte(c.Code))),
+	}
+
+	return s.txs.Create(ctx, sync, address, template_strings.UpdateAccountContractTransaction, args, transactions.ContractUpdate, transactions.TrustedCode())
+}
+
+// RemoveContract removes a contract from a custodial account.
+func (s *ServiceImpl) RemoveContract(ctx context.Context, sync bool, address, name string) (*jobs.Job, *transactions.Transaction, error) {
+	log.WithFields(log.Fields{"sync": sync, "address": address, "name": name}).Trace("Remove account contract")
+
+	address, deployed, err := s.custodialContracts(ctx, address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, ok := deployed[name]; !ok {
+		return nil, nil, contractNotFoundError(name)
+	}
+
+	args := []transactions.Argument{cadence.String(name)}
+
+	return s.txs.Create(ctx, sync, address, template_strings.RemoveAccountContractTransaction, args, transactions.ContractRemove, transactions.TrustedCode())
+}
+
+// deployedContracts reads the contracts of an account known to the wallet
+// from chain.
+func (s *ServiceImpl) deployedContracts(ctx context.Context, address string) (map[string][]byte, error) {
+	account, err := s.Details(address)
+	if err != nil {
+		return nil, err
+	}
+
+	flowAccount, err := s.fc.GetAccount(ctx, flow.HexToAddress(account.Address))
+	if err != nil {
+		return nil, err
+	}
+
+	return flowAccount.Contracts, nil
+}
+
+// custodialContracts checks that the wallet holds the keys of an account and
+// returns the formatted address and the deployed contracts of the account.
+func (s *ServiceImpl) custodialContracts(ctx context.Context, address string) (string, map[string][]byte, error) {
+	account, err := s.Details(address)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if account.Type != AccountTypeCustodial {
+		return "", nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("contracts can only be managed on custodial accounts"),
+		}
+	}
+
+	flowAccount, err := s.fc.GetAccount(ctx, flow.HexToAddress(account.Address))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return account.Address, flowAccount.Contracts, nil
+}
+
+func validateContract(c Contract) error {
+	if !validContractName.MatchString(c.Name) {
+		return &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid contract name: %q", c.Name),
+		}
+	}
+
+	if c.Code == "" {
+		return &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("contract code is empty"),
+		}
+	}
+
+	return nil
+}
+
+func contractNotFoundError(name string) error {
+	return &errors.RequestError{
+		StatusCode: http.StatusNotFound,
+		Err:        fmt.Errorf("contract %s is not deployed to the account", name),
+	}
+}
@@ -0,0 +1,44 @@
+package accounts
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/onflow/flow-go-sdk"
+	"gorm.io/gorm"
+)
+
+// ClosedAccountGuard rejects new transactions authorized by closed accounts.
+// It implements transactions.AccountGuard.
+type ClosedAccountGuard struct {
+	store Store
+}
+
+// NewClosedAccountGuard initiates a new guard for closed accounts.
+func NewClosedAccountGuard(store Store) *ClosedAccountGuard {
+	return &ClosedAccountGuard{store}
+}
+
+func (g *ClosedAccountGuard) CheckAuthorizer(address string) error {
+	address = flow_helpers.FormatAddress(flow.HexToAddress(address))
+
+	a, err := g.store.Account(address)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			// Not an account managed by the service, e.g. the admin account
+			return nil
+		}
+		return err
+	}
+
+	if a.IsClosed() {
+		return &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("account %s is closed", address),
+		}
+	}
+
+	return nil
+}
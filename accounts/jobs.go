@@ -12,6 +12,12 @@ import (
 
 const AccountCreateJobType = "account_create"
 
+// accountCreateJobMetadata is the metadata of an account creation job.
+type accountCreateJobMetadata struct {
+	Metadata
+	Warnings []string `json:"warnings,omitempty"`
+}
+
 func (s *ServiceImpl) executeAccountCreateJob(ctx context.Context, j *jobs.Job) error {
 	if j.Type != AccountCreateJobType {
 		return jobs.ErrInvalidJobType
@@ -19,7 +25,14 @@ func (s *ServiceImpl) executeAccountCreateJob(ctx context.Context, j *jobs.Job)
 
 	j.ShouldSendNotification = true
 
-	a, txID, err := s.createAccount(ctx)
+	var params createAccountParams
+	if len(j.Attributes) > 0 {
+		if err := json.Unmarshal(j.Attributes, &params); err != nil {
+			return err
+		}
+	}
+
+	a, txID, err := s.createAccount(ctx, params)
 	if err != nil {
 		return err
 	}
@@ -27,6 +40,14 @@ func (s *ServiceImpl) executeAccountCreateJob(ctx context.Context, j *jobs.Job)
 	j.TransactionID = txID
 	j.Result = a.Address
 
+	if m := a.Metadata(); !m.isEmpty() || len(a.Warnings) > 0 {
+		b, err := json.Marshal(accountCreateJobMetadata{Metadata: m, Warnings: a.Warnings})
+		if err != nil {
+			return err
+		}
+		j.Metadata = b
+	}
+
 	return nil
 }
 
@@ -0,0 +1,164 @@
+package accounts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const maxExternalIDLength = 255
+
+var validLabelKey = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+func isNullJSON(j datatypes.JSON) bool {
+	return len(j) == 0 || string(j) == "null"
+}
+
+func (m Metadata) isEmpty() bool {
+	return m.ExternalID == nil && len(m.Labels) == 0
+}
+
+// validateMetadata checks that the external ID is not too long and that
+// labels are a JSON object.
+func validateMetadata(m Metadata) error {
+	if m.ExternalID != nil && len(*m.ExternalID) > maxExternalIDLength {
+		return &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("external id is longer than %d characters", maxExternalIDLength),
+		}
+	}
+
+	if !isNullJSON(m.Labels) {
+		var labels map[string]interface{}
+		if err := json.Unmarshal(m.Labels, &labels); err != nil {
+			return &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("labels should be a JSON object"),
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyMetadata sets the given metadata on an account. An empty external ID
+// and null labels clear the current values, omitted fields are left as they are.
+func applyMetadata(a *Account, m Metadata) {
+	if m.ExternalID != nil {
+		if *m.ExternalID == "" {
+			a.ExternalID = nil
+		} else {
+			id := *m.ExternalID
+			a.ExternalID = &id
+		}
+	}
+
+	if len(m.Labels) > 0 {
+		if isNullJSON(m.Labels) {
+			a.Labels = nil
+		} else {
+			a.Labels = m.Labels
+		}
+	}
+}
+
+// Label returns the value of a top-level label of the account. String values
+// are returned as is, other values as JSON.
+func (a Account) Label(key string) (string, bool) {
+	for _, l := range labelIndex(a.Address, a.Labels) {
+		if l.Key == key {
+			return l.Value, true
+		}
+	}
+	return "", false
+}
+
+// ParseLabelFilter parses "key:value" label filters. A filter without a
+// value matches any account which has the label.
+func ParseLabelFilter(filters []string) (map[string]string, error) {
+	labels := make(map[string]string, len(filters))
+	for _, f := range filters {
+		key, value := f, ""
+		if i := strings.Index(f, ":"); i >= 0 {
+			key, value = f[:i], f[i+1:]
+		}
+		if !validLabelKey.MatchString(key) {
+			return nil, &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("invalid label filter: %q", f),
+			}
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// AccountLabel indexes a top-level label of an account so accounts can be
+// filtered by their labels. String values are stored as is, other values as
+// JSON.
+type AccountLabel struct {
+	ID             uint64 `gorm:"column:id;primaryKey"`
+	AccountAddress string `gorm:"column:account_address;index;not null"`
+	Key            string `gorm:"column:label_key;index:idx_account_labels_key_value;not null"`
+	Value          string `gorm:"column:label_value;index:idx_account_labels_key_value"`
+}
+
+func (AccountLabel) TableName() string {
+	return "account_labels"
+}
+
+// AfterSave updates the label index of the account.
+func (a *Account) AfterSave(tx *gorm.DB) error {
+	db := tx.Session(&gorm.Session{NewDB: true})
+
+	if err := db.Where("account_address = ?", a.Address).Delete(&AccountLabel{}).Error; err != nil {
+		return err
+	}
+
+	labels := labelIndex(a.Address, a.Labels)
+	if len(labels) == 0 {
+		return nil
+	}
+
+	return db.Create(&labels).Error
+}
+
+// AfterDelete removes the label index of the account.
+func (a *Account) AfterDelete(tx *gorm.DB) error {
+	return tx.Session(&gorm.Session{NewDB: true}).
+		Where("account_address = ?", a.Address).
+		Delete(&AccountLabel{}).Error
+}
+
+func labelIndex(address string, j datatypes.JSON) []AccountLabel {
+	if isNullJSON(j) {
+		return nil
+	}
+
+	var labels map[string]json.RawMessage
+	if err := json.Unmarshal(j, &labels); err != nil {
+		return nil
+	}
+
+	index := make([]AccountLabel, 0, len(labels))
+	for k, raw := range labels {
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			var b bytes.Buffer
+			if err := json.Compact(&b, raw); err != nil {
+				continue
+			}
+			value = b.String()
+		}
+		index = append(index, AccountLabel{AccountAddress: address, Key: k, Value: value})
+	}
+
+	return index
+}
@@ -9,3 +9,20 @@ func WithTxRatelimiter(limiter ratelimit.Limiter) ServiceOption {
 		svc.txRateLimiter = limiter
 	}
 }
+
+type CreateOption func(*createAccountParams)
+
+// WithMetadata creates the account with an external ID and labels.
+func WithMetadata(m Metadata) CreateOption {
+	return func(p *createAccountParams) {
+		p.Metadata = m
+	}
+}
+
+// WithKeys creates the account with the given keys instead of a single
+// default key, e.g. to split signing authority across key management backends.
+func WithKeys(specs []KeySpec) CreateOption {
+	return func(p *createAccountParams) {
+		p.Keys = specs
+	}
+}
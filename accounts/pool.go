@@ -0,0 +1,85 @@
+package accounts
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// AccountPool keeps the pool of pre-created accounts filled in the
+// background so account creation can hand out accounts without waiting
+// for a transaction to be sealed.
+type AccountPool struct {
+	svc      Service
+	interval time.Duration
+
+	ticker      *time.Ticker
+	tickerMutex sync.Mutex
+	stopChan    chan struct{}
+	stopOnce    sync.Once
+}
+
+// NewAccountPool initiates a new account pool refilled every interval.
+func NewAccountPool(svc Service, interval time.Duration) *AccountPool {
+	return &AccountPool{
+		svc:      svc,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+func (p *AccountPool) Start() {
+	p.tickerMutex.Lock()
+	defer p.tickerMutex.Unlock()
+
+	if p.ticker != nil {
+		// Already started
+		return
+	}
+
+	p.ticker = time.NewTicker(p.interval)
+
+	go func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		entry := log.WithFields(log.Fields{
+			"package":  "accounts",
+			"function": "AccountPool.Start.goroutine",
+		})
+
+		for {
+			select {
+			case <-p.stopChan:
+				return
+			case <-p.ticker.C:
+				created, err := p.svc.RefillAccountPool(ctx)
+				if err != nil {
+					entry.
+						WithFields(log.Fields{"error": err}).
+						Warn("Error while refilling account pool")
+				}
+				if created > 0 {
+					entry.
+						WithFields(log.Fields{"created": created}).
+						Debug("Refilled account pool")
+				}
+			}
+		}
+	}()
+}
+
+func (p *AccountPool) Stop() {
+	p.tickerMutex.Lock()
+	defer p.tickerMutex.Unlock()
+
+	p.stopOnce.Do(func() {
+		close(p.stopChan)
+	})
+
+	if p.ticker != nil {
+		p.ticker.Stop()
+	}
+}
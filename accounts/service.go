@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/flow-hydraulics/flow-wallet-api/configs"
 	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
 	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
 	"github.com/flow-hydraulics/flow-wallet-api/jobs"
 	"github.com/flow-hydraulics/flow-wallet-api/keys"
@@ -25,16 +28,42 @@ import (
 	"go.uber.org/ratelimit"
 )
 
-const maxGasLimit = 9999
+// Kinds of account transactions whose compute limit can be configured.
+const (
+	AccountCreationComputeLimit = "accountcreation"
+	AccountBatchComputeLimit    = "accountbatch"
+	ProposalKeysComputeLimit    = "proposalkeys"
+)
+
+// Number of pooled accounts tried when handing out an account from the pool.
+const maxPoolClaimCandidates = 5
 
 type Service interface {
-	List(limit, offset int) (result []Account, err error)
-	Create(ctx context.Context, sync bool) (*jobs.Job, *Account, error)
+	List(limit, offset int, filter ListFilter) (result []Account, err error)
+	Create(ctx context.Context, sync bool, opts ...CreateOption) (*jobs.Job, *Account, error)
+	// CreateBatch creates count accounts asynchronously, several accounts
+	// per transaction.
+	CreateBatch(ctx context.Context, count int) (*jobs.Job, error)
+	Import(ctx context.Context, address string, importKeys []ImportKey) (*Account, error)
 	AddNonCustodialAccount(address string) (*Account, error)
 	DeleteNonCustodialAccount(address string) error
 	SyncAccountKeyCount(ctx context.Context, address flow.Address) (*jobs.Job, error)
 	Details(address string) (Account, error)
+	// UpdateMetadata updates the external ID and labels of an account.
+	UpdateMetadata(address string, m Metadata) (*Account, error)
+	KeySets(address string) ([]keys.KeySet, error)
+	// Contracts lists the contracts deployed to an account.
+	Contracts(ctx context.Context, address string) ([]Contract, error)
+	Contract(ctx context.Context, address, name string) (*Contract, error)
+	// DeployContract, UpdateContract and RemoveContract send a transaction
+	// managing a contract of a custodial account.
+	DeployContract(ctx context.Context, sync bool, address string, c Contract) (*jobs.Job, *transactions.Transaction, error)
+	UpdateContract(ctx context.Context, sync bool, address string, c Contract) (*jobs.Job, *transactions.Transaction, error)
+	RemoveContract(ctx context.Context, sync bool, address, name string) (*jobs.Job, *transactions.Transaction, error)
 	InitAdminAccount(ctx context.Context) error
+	// RefillAccountPool creates accounts until the account pool is full,
+	// at most the configured refill rate at a time.
+	RefillAccountPool(ctx context.Context) (int, error)
 }
 
 // ServiceImpl defines the API for account management.
@@ -75,26 +104,60 @@ func NewService(
 
 	// Register asynchronous job executors
 	wp.RegisterExecutor(AccountCreateJobType, svc.executeAccountCreateJob)
+	wp.RegisterExecutor(AccountCreateBatchJobType, svc.executeAccountCreateBatchJob)
 	wp.RegisterExecutor(SyncAccountKeyCountJobType, svc.executeSyncAccountKeyCountJob)
 
 	return svc
 }
 
-// List returns all accounts in the datastore.
-func (s *ServiceImpl) List(limit, offset int) (result []Account, err error) {
+// List returns the accounts in the datastore matching the filter.
+func (s *ServiceImpl) List(limit, offset int, filter ListFilter) (result []Account, err error) {
 	o := datastore.ParseListOptions(limit, offset)
-	return s.store.Accounts(o)
+	return s.store.Accounts(o, filter)
+}
+
+// createAccountParams holds the optional parameters of an account creation.
+// It is also stored as the attributes of an asynchronous account create job.
+type createAccountParams struct {
+	Keys []KeySpec `json:"keys,omitempty"`
+	Metadata
 }
 
 // Create calls account.New to generate a new account.
 // It receives a new account with a corresponding private key or resource ID
 // and stores both in datastore.
 // It returns a job, the new account and a possible error.
-func (s *ServiceImpl) Create(ctx context.Context, sync bool) (*jobs.Job, *Account, error) {
+func (s *ServiceImpl) Create(ctx context.Context, sync bool, opts ...CreateOption) (*jobs.Job, *Account, error) {
 	log.WithFields(log.Fields{"sync": sync}).Trace("Create account")
 
+	params := createAccountParams{}
+	for _, opt := range opts {
+		opt(&params)
+	}
+
+	if err := s.validateKeySpecs(params.Keys); err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateMetadata(params.Metadata); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.checkExternalID(params.ExternalID, ""); err != nil {
+		return nil, nil, err
+	}
+
 	if !sync {
-		job, err := s.wp.CreateJob(AccountCreateJobType, "")
+		jobOpts := []jobs.JobOption{}
+		if len(params.Keys) > 0 || !params.Metadata.isEmpty() {
+			attrBytes, err := json.Marshal(params)
+			if err != nil {
+				return nil, nil, err
+			}
+			jobOpts = append(jobOpts, jobs.WithAttributes(attrBytes))
+		}
+
+		job, err := s.wp.CreateJob(AccountCreateJobType, "", jobOpts...)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -107,7 +170,7 @@ func (s *ServiceImpl) Create(ctx context.Context, sync bool) (*jobs.Job, *Accoun
 		return job, nil, err
 	}
 
-	account, _, err := s.createAccount(ctx)
+	account, _, err := s.createAccount(ctx, params)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -115,6 +178,183 @@ func (s *ServiceImpl) Create(ctx context.Context, sync bool) (*jobs.Job, *Accoun
 	return nil, account, nil
 }
 
+// Import takes an existing Flow account into custody. The given keys are
+// verified against the on-chain account: each key must be present and not
+// revoked, and the combined weight of the keys must meet the signing threshold.
+// An existing non-custodial (watchlist) account is converted to custodial.
+func (s *ServiceImpl) Import(ctx context.Context, address string, importKeys []ImportKey) (*Account, error) {
+	log.WithFields(log.Fields{"address": address}).Trace("Import account")
+
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	if flow.HexToAddress(address) == flow.HexToAddress(s.cfg.AdminAddress) {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("admin account can not be imported"),
+		}
+	}
+
+	if len(importKeys) == 0 {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("at least one key is required"),
+		}
+	}
+
+	account, err := s.store.Account(address)
+	if err == nil && account.Type != AccountTypeNonCustodial {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("account already exists"),
+		}
+	}
+	if err != nil && !strings.Contains(err.Error(), "record not found") {
+		return nil, err
+	}
+
+	flowAccount, err := s.fc.GetAccount(ctx, flow.HexToAddress(address))
+	if err != nil {
+		return nil, err
+	}
+
+	storableKeys := []keys.Storable{}
+	imported := map[string]bool{}
+	totalWeight := 0
+
+	for _, ik := range importKeys {
+		if !keys.IsValidKeyType(ik.Type) {
+			return nil, &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("invalid key type: %s", ik.Type),
+			}
+		}
+
+		pbk, privateKey, matching, err := s.importKey(ctx, flowAccount, ik)
+		if err != nil {
+			return nil, err
+		}
+
+		// A key given more than once only counts once towards the threshold
+		if imported[pbk.String()] {
+			continue
+		}
+		imported[pbk.String()] = true
+
+		// Use the lowest weight of the clones when checking the threshold
+		setWeight := matching[0].Weight
+		for _, k := range matching {
+			if k.Weight < setWeight {
+				setWeight = k.Weight
+			}
+		}
+		totalWeight += setWeight
+
+		// Sign with the algorithms registered on chain
+		privateKey.SignAlgo = matching[0].SigAlgo
+		privateKey.HashAlgo = matching[0].HashAlgo
+
+		// Convert the key to storable form (encrypt it)
+		encryptedAccountKey, err := s.km.Save(*privateKey)
+		if err != nil {
+			return nil, err
+		}
+		encryptedAccountKey.AccountAddress = address
+		encryptedAccountKey.PublicKey = pbk.String()
+
+		for _, k := range matching {
+			clonedEncryptedAccountKey := encryptedAccountKey
+			clonedEncryptedAccountKey.Index = k.Index
+			clonedEncryptedAccountKey.Weight = k.Weight
+			storableKeys = append(storableKeys, clonedEncryptedAccountKey)
+		}
+	}
+
+	if totalWeight < flow.AccountKeyWeightThreshold {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("combined key weight %d is below the signing threshold %d", totalWeight, flow.AccountKeyWeightThreshold),
+		}
+	}
+
+	account.Address = address
+	account.Type = AccountTypeCustodial
+	account.Keys = storableKeys
+
+	if account.CreatedAt.IsZero() {
+		err = s.store.InsertAccount(&account)
+	} else {
+		err = s.store.SaveAccount(&account)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	AccountAdded.Trigger(AccountAddedPayload{
+		Address: flow.HexToAddress(account.Address),
+	})
+
+	log.
+		WithFields(log.Fields{"address": account.Address, "keys": len(storableKeys)}).
+		Info("Account imported")
+
+	// Strip the private keys
+	for i := range account.Keys {
+		account.Keys[i].Value = make([]byte, 0)
+	}
+
+	return &account, nil
+}
+
+// importKey resolves the public key of an imported key and the non-revoked
+// on-chain keys (and their clones) matching it. Local keys are decoded with
+// the signature algorithms of the on-chain keys, so the algorithm registered
+// on chain is always used.
+func (s *ServiceImpl) importKey(ctx context.Context, flowAccount *flow.Account, ik ImportKey) (flow_crypto.PublicKey, *keys.Private, []*flow.AccountKey, error) {
+	algos := []flow_crypto.SignatureAlgorithm{flow_crypto.UnknownSignatureAlgorithm}
+	if ik.Type == keys.AccountKeyTypeLocal {
+		algos = []flow_crypto.SignatureAlgorithm{}
+		seen := map[flow_crypto.SignatureAlgorithm]bool{}
+		for _, k := range flowAccount.Keys {
+			if !k.Revoked && !seen[k.SigAlgo] {
+				seen[k.SigAlgo] = true
+				algos = append(algos, k.SigAlgo)
+			}
+		}
+	}
+
+	var err error = fmt.Errorf("account has no keys which are not revoked")
+
+	for _, algo := range algos {
+		pbk, privateKey, importErr := s.km.Import(ctx, ik.Type, ik.Value, algo)
+		if importErr != nil {
+			err = fmt.Errorf("unable to resolve public key of %s key: %w", ik.Type, importErr)
+			continue
+		}
+
+		matching := []*flow.AccountKey{}
+		for _, k := range flowAccount.Keys {
+			if !k.Revoked && k.PublicKey.Equals(pbk) {
+				matching = append(matching, k)
+			}
+		}
+
+		if len(matching) > 0 {
+			return pbk, privateKey, matching, nil
+		}
+
+		err = fmt.Errorf("public key %s not found on account or revoked", pbk)
+	}
+
+	return nil, nil, nil, &errors.RequestError{
+		StatusCode: http.StatusBadRequest,
+		Err:        err,
+	}
+}
+
 func (s *ServiceImpl) AddNonCustodialAccount(address string) (*Account, error) {
 	log.WithFields(log.Fields{"address": address}).Trace("Add non-custodial account")
 
@@ -128,6 +368,11 @@ func (s *ServiceImpl) AddNonCustodialAccount(address string) (*Account, error) {
 		return nil, err
 	}
 
+	AccountAdded.Trigger(AccountAddedPayload{
+		Address:      flow.HexToAddress(a.Address),
+		NonCustodial: true,
+	})
+
 	return a, nil
 }
 
@@ -174,6 +419,110 @@ func (s *ServiceImpl) Details(address string) (Account, error) {
 	return account, nil
 }
 
+// UpdateMetadata updates the external ID and labels of an account.
+func (s *ServiceImpl) UpdateMetadata(address string, m Metadata) (*Account, error) {
+	log.WithFields(log.Fields{"address": address}).Trace("Update account metadata")
+
+	if err := validateMetadata(m); err != nil {
+		return nil, err
+	}
+
+	account, err := s.Details(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkExternalID(m.ExternalID, account.Address); err != nil {
+		return nil, err
+	}
+
+	applyMetadata(&account, m)
+
+	if err := s.store.UpdateAccountMetadata(&account); err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// checkExternalID returns a 409 error if the external ID is used by an
+// account other than the one at address.
+func (s *ServiceImpl) checkExternalID(externalID *string, address string) error {
+	if externalID == nil || *externalID == "" {
+		return nil
+	}
+
+	existing, err := s.store.AccountByExternalID(*externalID)
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			return nil
+		}
+		return err
+	}
+
+	if existing.Address != address {
+		return &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("external id %q is already in use", *externalID),
+		}
+	}
+
+	return nil
+}
+
+// KeySets returns the key sets of a specific account. Each key set groups
+// the stored clones of a single logical key.
+func (s *ServiceImpl) KeySets(address string) ([]keys.KeySet, error) {
+	log.WithFields(log.Fields{"address": address}).Trace("Account key sets")
+
+	account, err := s.Details(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys.GroupKeySets(account.Keys), nil
+}
+
+// validateKeySpecs checks that the given key specs are supported and that
+// their combined weight is enough to sign transactions for the account.
+func (s *ServiceImpl) validateKeySpecs(specs []KeySpec) error {
+	if len(specs) == 0 {
+		return nil
+	}
+
+	totalWeight := 0
+	for _, spec := range specs {
+		if spec.Type != "" && !keys.IsValidKeyType(spec.Type) {
+			return &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("invalid key type: %s", spec.Type),
+			}
+		}
+
+		if spec.Weight < 0 || spec.Weight > flow.AccountKeyWeightThreshold {
+			return &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("invalid key weight: %d, expected 1-%d", spec.Weight, flow.AccountKeyWeightThreshold),
+			}
+		}
+
+		if spec.Weight == 0 {
+			totalWeight += s.cfg.DefaultKeyWeight
+		} else {
+			totalWeight += spec.Weight
+		}
+	}
+
+	if totalWeight < flow.AccountKeyWeightThreshold {
+		return &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("combined key weight %d is below the signing threshold %d", totalWeight, flow.AccountKeyWeightThreshold),
+		}
+	}
+
+	return nil
+}
+
 // SyncKeyCount syncs number of keys for given account
 func (s *ServiceImpl) SyncAccountKeyCount(ctx context.Context, address flow.Address) (*jobs.Job, error) {
 	// Validate address, they might be legit addresses but for the wrong chain
@@ -224,6 +573,11 @@ func (s *ServiceImpl) syncAccountKeyCount(ctx context.Context, address flow.Addr
 		return 0, "", err
 	}
 
+	// Cloning keys is only supported for accounts with a single logical key
+	if len(keys.GroupKeySets(dbAccount.Keys)) > 1 {
+		return 0, "", fmt.Errorf("key count sync is not supported for multi-signature accounts")
+	}
+
 	// Pick a source key that will be used to create the new keys & decode public key
 	sourceKey := dbAccount.Keys[0] // NOTE: Only valid (not revoked) keys should be stored in the database
 	sourceKeyPbkString := strings.TrimPrefix(sourceKey.PublicKey, "0x")
@@ -293,7 +647,7 @@ func (s *ServiceImpl) syncAccountKeyCount(ctx context.Context, address flow.Addr
 		entry.WithFields(log.Fields{"args": args}).Debug("args prepared")
 
 		// NOTE: sync, so will wait for transaction to be sent & sealed
-		_, tx, err := s.txs.Create(ctx, true, dbAccount.Address, code, args, transactions.General)
+		_, tx, err := s.txs.Create(ctx, true, dbAccount.Address, code, args, transactions.General, transactions.TrustedCode())
 		if err != nil {
 			entry.WithFields(log.Fields{"err": err}).Error("failed to create transaction")
 			return 0, tx.TransactionId, err
@@ -318,49 +672,138 @@ func (s *ServiceImpl) syncAccountKeyCount(ctx context.Context, address flow.Addr
 	return 0, "", nil
 }
 
-// createAccount creates a new account on the flow blockchain. It generates a
-// fresh key pair and constructs a flow transaction to create the account with
-// generated key. Admin account is used to pay for the transaction.
+// createAccount hands out an account from the account pool if the pool is
+// enabled and no custom keys were requested. Otherwise, or if the pool is
+// empty, a new account is created on chain.
 //
-// Returns created account and the flow transaction ID of the account creation.
-func (s *ServiceImpl) createAccount(ctx context.Context) (*Account, string, error) {
-	account := &Account{Type: AccountTypeCustodial}
+// Returns the account and the flow transaction ID of the account creation,
+// which is empty for pooled accounts.
+func (s *ServiceImpl) createAccount(ctx context.Context, params createAccountParams) (*Account, string, error) {
+	if s.cfg.AccountPoolSize > 0 && len(params.Keys) == 0 {
+		account, err := s.claimPooledAccount(params.Metadata)
+		if err != nil {
+			return nil, "", err
+		}
+		if account != nil {
+			return account, "", nil
+		}
+		log.Warn("Account pool is empty, creating a new account")
+	}
 
-	// Important to ratelimit all the way up here so the keys and reference blocks
-	// are "fresh" when the transaction is actually sent
-	s.txRateLimiter.Take()
+	return s.newAccount(ctx, params, AccountTypeCustodial)
+}
 
-	payer, err := s.km.AdminAuthorizer(ctx)
+// claimPooledAccount takes the oldest account out of the account pool and
+// stores the given metadata for it. Returns nil if the pool is empty.
+func (s *ServiceImpl) claimPooledAccount(m Metadata) (*Account, error) {
+	// Another request (or instance) may claim a candidate first, so try a few
+	candidates, err := s.store.PooledAccounts(maxPoolClaimCandidates)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	proposer, err := s.km.AdminProposalKey(ctx)
-	if err != nil {
-		return nil, "", err
+	for i := range candidates {
+		account := &candidates[i]
+		applyMetadata(account, m)
+
+		claimed, err := s.store.ClaimPooledAccount(account)
+		if err != nil {
+			return nil, err
+		}
+		if !claimed {
+			continue
+		}
+
+		log.
+			WithFields(log.Fields{"address": account.Address}).
+			Info("Account handed out from account pool")
+
+		// Reload the account with its keys
+		claimedAccount, err := s.store.Account(account.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		return &claimedAccount, nil
 	}
 
-	// Get latest blocks blockID as reference blockID
-	referenceBlockID, err := flow_helpers.LatestBlockId(ctx, s.fc)
+	return nil, nil
+}
+
+// RefillAccountPool creates accounts until the account pool is full, at most
+// AccountPoolRefillRate at a time. Returns the number of accounts created.
+func (s *ServiceImpl) RefillAccountPool(ctx context.Context) (int, error) {
+	if s.cfg.AccountPoolSize == 0 {
+		return 0, nil
+	}
+
+	count, err := s.store.PooledAccountCount()
 	if err != nil {
-		return nil, "", err
+		return 0, err
+	}
+
+	missing := int64(s.cfg.AccountPoolSize) - count
+	if missing <= 0 {
+		return 0, nil
+	}
+	if rate := int64(s.cfg.AccountPoolRefillRate); missing > rate {
+		missing = rate
 	}
 
-	// Generate a new key pair
-	accountKey, newPrivateKey, err := s.km.GenerateDefault(ctx)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		created  int
+		firstErr error
+	)
+
+	for i := int64(0); i < missing; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := s.newAccount(ctx, createAccountParams{}, AccountTypePooled)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			created++
+		}()
+	}
+	wg.Wait()
+
+	return created, firstErr
+}
+
+// newAccount creates a new account on the flow blockchain. It generates a
+// fresh key pair (or one per key spec in params) and constructs a flow
+// transaction to create the account with generated key(s). Admin account is
+// used to pay for the transaction.
+//
+// Returns created account and the flow transaction ID of the account creation.
+func (s *ServiceImpl) newAccount(ctx context.Context, params createAccountParams, accountType AccountType) (*Account, string, error) {
+	account := &Account{Type: accountType}
+
+	// Important to ratelimit all the way up here so the keys and reference blocks
+	// are "fresh" when the transaction is actually sent
+	s.txRateLimiter.Take()
+
+	payer, err := s.km.AdminAuthorizer(ctx)
 	if err != nil {
 		return nil, "", err
 	}
 
-	// Public keys for creating the account
-	publicKeys := []*flow.AccountKey{}
-
-	// Create copies based on the configured key count, changing just the index
-	for i := 0; i < int(s.cfg.DefaultAccountKeyCount); i++ {
-		clonedAccountKey := *accountKey
-		clonedAccountKey.Index = i
+	specs := params.Keys
+	if len(specs) == 0 {
+		specs = []KeySpec{{Type: s.cfg.DefaultKeyType, Weight: s.cfg.DefaultKeyWeight}}
+	}
 
-		publicKeys = append(publicKeys, &clonedAccountKey)
+	publicKeys, storableKeys, err := s.generateAccountKeys(ctx, specs)
+	if err != nil {
+		return nil, "", err
 	}
 
 	var flowTx *flow.Transaction
@@ -388,12 +831,6 @@ func (s *ServiceImpl) createAccount(ctx context.Context) (*Account, string, erro
 
 	}
 
-	flowTx.
-		SetReferenceBlockID(*referenceBlockID).
-		SetProposalKey(proposer.Address, proposer.Key.Index, proposer.Key.SequenceNumber).
-		SetPayer(payer.Address).
-		SetGasLimit(maxGasLimit)
-
 	// Check if we want to use a custom account create script
 	if s.cfg.ScriptPathCreateAccount != "" {
 		bytes, err := os.ReadFile(s.cfg.ScriptPathCreateAccount)
@@ -404,60 +841,41 @@ func (s *ServiceImpl) createAccount(ctx context.Context) (*Account, string, erro
 		flowTx.SetScript(bytes)
 	}
 
-	// Proposer signs the payload (unless proposer == payer).
-	if !proposer.Equals(payer) {
-		if err := flowTx.SignPayload(proposer.Address, proposer.Key.Index, proposer.Signer); err != nil {
-			return nil, "", err
-		}
-	}
-
-	// Payer signs the envelope
-	if err := flowTx.SignEnvelope(payer.Address, payer.Key.Index, payer.Signer); err != nil {
-		return nil, "", err
-	}
-
-	// Send and wait for the transaction to be sealed
-	result, err := flow_helpers.SendAndWait(ctx, s.fc, *flowTx, s.cfg.TransactionTimeout)
+	result, err := s.sendAdminTransaction(ctx, flowTx, payer, s.cfg.ComputeLimit(AccountCreationComputeLimit))
 	if err != nil {
 		return nil, "", err
 	}
 
 	// Grab the new address from transaction events
-	var newAddress flow.Address
-	for _, event := range result.Events {
-		if event.Type == flow.EventAccountCreated {
-			accountCreatedEvent := flow.AccountCreatedEvent(event)
-			newAddress = accountCreatedEvent.Address()
-			break
-		}
-	}
+	newAddresses := createdAddresses(result.Events)
 
 	// Check that we actually got a new address
-	if newAddress == flow.EmptyAddress {
+	if len(newAddresses) == 0 {
 		return nil, "", fmt.Errorf("something went wrong when waiting for address")
 	}
 
-	account.Address = flow_helpers.FormatAddress(newAddress)
-
-	// Convert the key to storable form (encrypt it)
-	encryptedAccountKey, err := s.km.Save(*newPrivateKey)
-	if err != nil {
-		return nil, "", err
-	}
-	encryptedAccountKey.PublicKey = accountKey.PublicKey.String()
+	account.Address = flow_helpers.FormatAddress(newAddresses[0])
 
 	// Store account and key(s)
-	// Looping through accountKeys to get the correct Index values
-	storableKeys := []keys.Storable{}
-	for _, pbk := range publicKeys {
-		clonedEncryptedAccountKey := encryptedAccountKey
-		clonedEncryptedAccountKey.Index = pbk.Index
-		storableKeys = append(storableKeys, clonedEncryptedAccountKey)
-	}
-
 	account.Keys = storableKeys
+	applyMetadata(account, params.Metadata)
 	if err := s.store.InsertAccount(account); err != nil {
-		return nil, "", err
+		if account.ExternalID == nil {
+			return nil, "", err
+		}
+		// The external ID may have been taken while the account was being
+		// created. Store the account without it so its keys are not lost,
+		// and report the conflict to the caller.
+		log.
+			WithFields(log.Fields{"address": account.Address, "externalId": *account.ExternalID, "error": err}).
+			Warn("Could not store account with external ID, storing it without")
+		account.Warnings = append(account.Warnings, fmt.Sprintf(
+			"external ID %q is already in use, the account was created without it", *account.ExternalID,
+		))
+		account.ExternalID = nil
+		if err := s.store.InsertAccount(account); err != nil {
+			return nil, "", err
+		}
 	}
 
 	AccountAdded.Trigger(AccountAddedPayload{
@@ -472,6 +890,140 @@ func (s *ServiceImpl) createAccount(ctx context.Context) (*Account, string, erro
 	return account, flowTx.ID().String(), nil
 }
 
+// generateAccountKeys generates a new key pair for each key spec. Each key
+// is cloned according to the configured key count. Returns the public keys
+// for creating the account and their storable counterparts.
+func (s *ServiceImpl) generateAccountKeys(ctx context.Context, specs []KeySpec) ([]*flow.AccountKey, []keys.Storable, error) {
+	publicKeys := []*flow.AccountKey{}
+	storableKeys := []keys.Storable{}
+
+	for _, spec := range specs {
+		keyType, weight := spec.Type, spec.Weight
+		if keyType == "" {
+			keyType = s.cfg.DefaultKeyType
+		}
+		if weight == 0 {
+			weight = s.cfg.DefaultKeyWeight
+		}
+
+		// Generate a new key pair
+		accountKey, newPrivateKey, err := s.km.GenerateOfType(ctx, keyType, len(publicKeys), weight)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Convert the key to storable form (encrypt it)
+		encryptedAccountKey, err := s.km.Save(*newPrivateKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		encryptedAccountKey.PublicKey = accountKey.PublicKey.String()
+		encryptedAccountKey.Weight = weight
+
+		// Create copies based on the configured key count, changing just the index
+		for i := 0; i < int(s.cfg.DefaultAccountKeyCount); i++ {
+			clonedAccountKey := *accountKey
+			clonedAccountKey.Index = len(publicKeys)
+
+			clonedEncryptedAccountKey := encryptedAccountKey
+			clonedEncryptedAccountKey.Index = clonedAccountKey.Index
+
+			publicKeys = append(publicKeys, &clonedAccountKey)
+			storableKeys = append(storableKeys, clonedEncryptedAccountKey)
+		}
+	}
+
+	return publicKeys, storableKeys, nil
+}
+
+// sendAdminTransaction sets the reference block, an admin proposal key and
+// the payer of a transaction, signs it and waits for it to be sealed.
+func (s *ServiceImpl) sendAdminTransaction(ctx context.Context, flowTx *flow.Transaction, payer keys.Authorizer, computeLimit uint64) (*flow.TransactionResult, error) {
+	if err := s.signAdminTransaction(ctx, flowTx, payer, computeLimit); err != nil {
+		return nil, err
+	}
+
+	return s.sendSignedAdminTransaction(ctx, flowTx)
+}
+
+// signAdminTransaction sets the reference block, an admin proposal key and
+// the payer of a transaction and signs it.
+func (s *ServiceImpl) signAdminTransaction(ctx context.Context, flowTx *flow.Transaction, payer keys.Authorizer, computeLimit uint64) error {
+	// Get latest blocks blockID as reference blockID
+	referenceBlockID, err := flow_helpers.LatestBlockId(ctx, s.fc)
+	if err != nil {
+		return err
+	}
+
+	// Allocated last so a failure above does not leave a sequence number unused
+	proposer, err := s.km.AdminProposalKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	flowTx.
+		SetReferenceBlockID(*referenceBlockID).
+		SetProposalKey(proposer.Address, proposer.Key.Index, proposer.Key.SequenceNumber).
+		SetPayer(payer.Address).
+		SetGasLimit(computeLimit)
+
+	// Proposer signs the payload (unless proposer == payer).
+	if !proposer.Equals(payer) {
+		if err := flowTx.SignPayload(proposer.Address, proposer.Key.Index, proposer.Signer); err != nil {
+			keys.ReleaseProposalKey(s.km, flowTx)
+			return err
+		}
+	}
+
+	// Payer signs the envelope
+	if err := flowTx.SignEnvelope(payer.Address, payer.Key.Index, payer.Signer); err != nil {
+		keys.ReleaseProposalKey(s.km, flowTx)
+		return err
+	}
+
+	return nil
+}
+
+// sendSignedAdminTransaction sends a transaction signed with
+// signAdminTransaction and waits for it to be sealed.
+func (s *ServiceImpl) sendSignedAdminTransaction(ctx context.Context, flowTx *flow.Transaction) (*flow.TransactionResult, error) {
+	if err := s.fc.SendTransaction(ctx, *flowTx); err != nil {
+		keys.ReleaseProposalKey(s.km, flowTx)
+		return nil, err
+	}
+
+	// Wait for the transaction to be sealed
+	result, err := flow_helpers.WaitForSeal(ctx, s.fc, flowTx.ID(), s.cfg.TransactionTimeout)
+	if err != nil {
+		keys.ResyncOnSequenceNumberError(ctx, s.km, flowTx, err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// createdAddresses returns the addresses of the AccountCreated events in
+// the order the accounts were created.
+func createdAddresses(events []flow.Event) []flow.Address {
+	created := []flow.Event{}
+	for _, event := range events {
+		if event.Type == flow.EventAccountCreated {
+			created = append(created, event)
+		}
+	}
+
+	sort.SliceStable(created, func(i, j int) bool {
+		return created[i].EventIndex < created[j].EventIndex
+	})
+
+	addresses := make([]flow.Address, len(created))
+	for i, event := range created {
+		addresses[i] = flow.AccountCreatedEvent(event).Address()
+	}
+
+	return addresses
+}
+
 // generateCreateAccountTransactionWithEnabledFungibleTokenVaults is a helper function that generates a templated
 // account creation transaction that initializes all enabled fungible tokens.
 func (s *ServiceImpl) generateCreateAccountTransactionWithEnabledFungibleTokenVaults(
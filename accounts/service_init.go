@@ -82,32 +82,55 @@ func (s *ServiceImpl) addAdminProposalKeys(ctx context.Context, count uint16) er
 		return err
 	}
 
+	// Allocated last so a failure above does not leave a sequence number unused
+	proposer, err := s.km.AdminProposalKey(ctx)
+	if err != nil {
+		return err
+	}
+
 	code := template_strings.AddProposalKeyTransaction
 
 	flowTx := flow.NewTransaction()
 	flowTx.
 		SetReferenceBlockID(*referenceBlockID).
-		SetProposalKey(payer.Address, payer.Key.Index, payer.Key.SequenceNumber).
+		SetProposalKey(proposer.Address, proposer.Key.Index, proposer.Key.SequenceNumber).
 		SetPayer(payer.Address).
-		SetGasLimit(maxGasLimit).
+		SetGasLimit(s.cfg.ComputeLimit(ProposalKeysComputeLimit)).
 		SetScript([]byte(code))
 
 	if err := flowTx.AddArgument(cadence.NewInt(s.cfg.AdminKeyIndex)); err != nil {
+		keys.ReleaseProposalKey(s.km, flowTx)
 		return err
 	}
 
 	if err := flowTx.AddArgument(cadence.NewUInt16(count)); err != nil {
+		keys.ReleaseProposalKey(s.km, flowTx)
 		return err
 	}
 
 	flowTx.AddAuthorizer(payer.Address)
 
+	// Proposer signs the payload (unless proposer == payer).
+	if !proposer.Equals(payer) {
+		if err := flowTx.SignPayload(proposer.Address, proposer.Key.Index, proposer.Signer); err != nil {
+			keys.ReleaseProposalKey(s.km, flowTx)
+			return err
+		}
+	}
+
 	if err := flowTx.SignEnvelope(payer.Address, payer.Key.Index, payer.Signer); err != nil {
+		keys.ReleaseProposalKey(s.km, flowTx)
+		return err
+	}
+
+	if err := s.fc.SendTransaction(ctx, *flowTx); err != nil {
+		keys.ReleaseProposalKey(s.km, flowTx)
 		return err
 	}
 
-	// Send and wait for the transaction to be sealed
-	if _, err := flow_helpers.SendAndWait(ctx, s.fc, *flowTx, s.cfg.TransactionTimeout); err != nil {
+	// Wait for the transaction to be sealed
+	if _, err := flow_helpers.WaitForSeal(ctx, s.fc, flowTx.ID(), s.cfg.TransactionTimeout); err != nil {
+		keys.ResyncOnSequenceNumberError(ctx, s.km, flowTx, err)
 		return err
 	}
 
@@ -1,23 +1,47 @@
 package accounts
 
 import (
+	"time"
+
 	"github.com/flow-hydraulics/flow-wallet-api/datastore"
 )
 
 // Store manages data regarding accounts.
 type Store interface {
-	// List all accounts.
-	Accounts(datastore.ListOptions) ([]Account, error)
+	// List accounts matching a filter.
+	Accounts(datastore.ListOptions, ListFilter) ([]Account, error)
 
 	// Get account details.
 	Account(address string) (Account, error)
 
+	// Get account details by external ID.
+	AccountByExternalID(externalID string) (Account, error)
+
 	// Insert a new account.
 	InsertAccount(a *Account) error
 
+	// Insert new accounts in a single database transaction.
+	InsertAccounts(aa []*Account) error
+
 	// Update an existing account.
 	SaveAccount(a *Account) error
 
+	// Update the external ID and labels of an account.
+	UpdateAccountMetadata(a *Account) error
+
+	// Mark an account closed.
+	CloseAccount(address string, closedAt time.Time) error
+
+	// Count the accounts in the account pool.
+	PooledAccountCount() (int64, error)
+
+	// List the oldest accounts in the account pool.
+	PooledAccounts(limit int) ([]Account, error)
+
+	// Hand out a pooled account as a custodial account, storing its metadata.
+	// Returns false if the account is no longer in the pool.
+	ClaimPooledAccount(a *Account) (bool, error)
+
 	// Permanently delete an account, despite of `DeletedAt` field.
 	HardDeleteAccount(a *Account) error
 }
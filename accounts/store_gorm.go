@@ -1,6 +1,9 @@
 package accounts
 
 import (
+	"sort"
+	"time"
+
 	"github.com/flow-hydraulics/flow-wallet-api/datastore"
 	"gorm.io/gorm"
 )
@@ -13,8 +16,28 @@ func NewGormStore(db *gorm.DB) Store {
 	return &GormStore{db}
 }
 
-func (s *GormStore) Accounts(o datastore.ListOptions) (aa []Account, err error) {
-	err = s.db.
+func (s *GormStore) Accounts(o datastore.ListOptions, f ListFilter) (aa []Account, err error) {
+	q := s.db.Where("type <> ?", AccountTypePooled)
+	if f.ExternalID != "" {
+		q = q.Where("external_id = ?", f.ExternalID)
+	}
+
+	// Sort the label keys to keep the query stable
+	labelKeys := make([]string, 0, len(f.Labels))
+	for k := range f.Labels {
+		labelKeys = append(labelKeys, k)
+	}
+	sort.Strings(labelKeys)
+
+	for _, k := range labelKeys {
+		sub := s.db.Model(&AccountLabel{}).Select("account_address").Where("label_key = ?", k)
+		if v := f.Labels[k]; v != "" {
+			sub = sub.Where("label_value = ?", v)
+		}
+		q = q.Where("address IN (?)", sub)
+	}
+
+	err = q.
 		Order("created_at desc").
 		Limit(o.Limit).
 		Offset(o.Offset).
@@ -27,14 +50,81 @@ func (s *GormStore) Account(address string) (a Account, err error) {
 	return
 }
 
+func (s *GormStore) AccountByExternalID(externalID string) (a Account, err error) {
+	err = s.db.First(&a, "external_id = ?", externalID).Error
+	return
+}
+
 func (s *GormStore) InsertAccount(a *Account) error {
 	return s.db.Create(a).Error
 }
 
+func (s *GormStore) InsertAccounts(aa []*Account) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, a := range aa {
+			if err := tx.Create(a).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (s *GormStore) SaveAccount(a *Account) error {
 	return s.db.Save(&a).Error
 }
 
+func (s *GormStore) UpdateAccountMetadata(a *Account) error {
+	return s.db.Model(a).Select("ExternalID", "Labels").Updates(a).Error
+}
+
+func (s *GormStore) CloseAccount(address string, closedAt time.Time) error {
+	return s.db.Model(&Account{}).
+		Where("address = ?", address).
+		Session(&gorm.Session{SkipHooks: true}).
+		Update("closed_at", closedAt).Error
+}
+
+func (s *GormStore) PooledAccountCount() (count int64, err error) {
+	err = s.db.Model(&Account{}).Where("type = ?", AccountTypePooled).Count(&count).Error
+	return
+}
+
+func (s *GormStore) PooledAccounts(limit int) (aa []Account, err error) {
+	err = s.db.
+		Where("type = ?", AccountTypePooled).
+		Order("created_at asc").
+		Limit(limit).
+		Find(&aa).Error
+	return
+}
+
+func (s *GormStore) ClaimPooledAccount(a *Account) (claimed bool, err error) {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Take the account out of the pool first, so concurrent claims of
+		// the same account can not both succeed
+		res := tx.Session(&gorm.Session{SkipHooks: true}).
+			Model(&Account{}).
+			Where("address = ? AND type = ?", a.Address, AccountTypePooled).
+			Update("type", AccountTypeCustodial)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+
+		a.Type = AccountTypeCustodial
+		if err := tx.Model(a).Select("Type", "ExternalID", "Labels").Updates(a).Error; err != nil {
+			return err
+		}
+
+		claimed = true
+		return nil
+	})
+	if err != nil {
+		claimed = false
+	}
+	return
+}
+
 func (s *GormStore) HardDeleteAccount(a *Account) error {
 	return s.db.Unscoped().Delete(a).Error
 }
@@ -13,6 +13,8 @@ import (
 	flow_templates "github.com/onflow/flow-go-sdk/templates"
 )
 
+const testGasLimit = 9999
+
 // AddContract is used only in tests
 func AddContract(
 	ctx context.Context,
@@ -44,7 +46,7 @@ func AddContract(
 		SetReferenceBlockID(*referenceBlockID).
 		SetProposalKey(proposer.Address, proposer.Key.Index, proposer.Key.SequenceNumber).
 		SetPayer(payer.Address).
-		SetGasLimit(maxGasLimit).
+		SetGasLimit(testGasLimit).
 		SetScript([]byte(template_strings.AddAccountContractWithAdmin)).
 		AddAuthorizer(payer.Address)
 
@@ -0,0 +1,107 @@
+// Package backup provides encrypted export and restore of custody data
+// (accounts, account keys and account tokens).
+package backup
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/onflow/flow-go-sdk"
+	"gorm.io/datatypes"
+)
+
+// ArchiveVersion is the current version of the archive format.
+const ArchiveVersion = 1
+
+const (
+	SealingPassphrase = "passphrase"
+	SealingPublicKey  = "rsa-oaep"
+)
+
+// Archive is a sealed, versioned backup archive. Contents are gzipped JSON
+// encrypted with AES-256-GCM. The encryption key is either derived from a
+// passphrase (scrypt) or randomly generated and wrapped with an RSA public key.
+type Archive struct {
+	Version    int          `json:"version"`
+	CreatedAt  time.Time    `json:"createdAt"`
+	ChainID    flow.ChainID `json:"chainId"`
+	Sealing    string       `json:"sealing"`
+	Salt       []byte       `json:"salt,omitempty"`
+	WrappedKey []byte       `json:"wrappedKey,omitempty"`
+	Nonce      []byte       `json:"nonce"`
+	Ciphertext []byte       `json:"ciphertext"`
+}
+
+// additionalData returns the archive header which is authenticated
+// (but not encrypted) along with the contents.
+func (a *Archive) additionalData() ([]byte, error) {
+	return json.Marshal(struct {
+		Version    int          `json:"version"`
+		CreatedAt  time.Time    `json:"createdAt"`
+		ChainID    flow.ChainID `json:"chainId"`
+		Sealing    string       `json:"sealing"`
+		Salt       []byte       `json:"salt,omitempty"`
+		WrappedKey []byte       `json:"wrappedKey,omitempty"`
+	}{a.Version, a.CreatedAt, a.ChainID, a.Sealing, a.Salt, a.WrappedKey})
+}
+
+// Contents is the plaintext content of an archive.
+type Contents struct {
+	Accounts      []Account      `json:"accounts"`
+	AccountTokens []AccountToken `json:"accountTokens"`
+}
+
+// Account is the archived form of an accounts.Account.
+type Account struct {
+	Address    string               `json:"address"`
+	ExternalID *string              `json:"externalId,omitempty"`
+	Labels     datatypes.JSON       `json:"labels,omitempty"`
+	Type       accounts.AccountType `json:"type"`
+	ClosedAt   *time.Time           `json:"closedAt,omitempty"`
+	Keys       []Key                `json:"keys"`
+	CreatedAt  time.Time            `json:"createdAt"`
+}
+
+// Key is the archived form of a keys.Storable. Value is the decrypted
+// private key or resource id, it is re-encrypted on restore.
+type Key struct {
+	Index     int    `json:"index"`
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+	PublicKey string `json:"publicKey"`
+	SignAlgo  string `json:"signAlgo"`
+	HashAlgo  string `json:"hashAlgo"`
+	Weight    int    `json:"weight"`
+}
+
+// AccountToken is the archived form of a tokens.AccountToken.
+type AccountToken struct {
+	AccountAddress string              `json:"accountAddress"`
+	TokenName      string              `json:"tokenName"`
+	TokenAddress   string              `json:"tokenAddress"`
+	TokenType      templates.TokenType `json:"tokenType"`
+}
+
+// SealOptions defines how an archive is sealed. Exactly one of Passphrase
+// and PublicKey (PEM encoded RSA public key) should be set.
+type SealOptions struct {
+	Passphrase string `json:"passphrase,omitempty"`
+	PublicKey  string `json:"publicKey,omitempty"`
+}
+
+// OpenOptions defines how an archive is opened. Passphrase should be set for
+// archives sealed with a passphrase and PrivateKey (PEM encoded RSA private key)
+// for archives sealed with a public key.
+type OpenOptions struct {
+	Passphrase string `json:"passphrase,omitempty"`
+	PrivateKey string `json:"privateKey,omitempty"`
+}
+
+// RestoreResult summarizes a restore.
+type RestoreResult struct {
+	Accounts        int      `json:"accounts"`
+	AccountTokens   int      `json:"accountTokens"`
+	SkippedAccounts []string `json:"skippedAccounts"`
+}
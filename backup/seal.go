@@ -0,0 +1,230 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	ErrInvalidSealOptions = errors.New("either a passphrase or a public key is required")
+	ErrInvalidOpenOptions = errors.New("secret does not match archive sealing")
+	ErrUnableToOpen       = errors.New("unable to open archive, wrong secret or archive has been tampered with")
+)
+
+const (
+	keyLength  = 32
+	saltLength = 16
+
+	// scrypt parameters, recommended for interactive logins as of 2017
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+var oaepLabel = []byte("flow-wallet-api backup")
+
+// seal compresses and encrypts plaintext into the archive. Archive header
+// (version, creation time, chain id) should be set before sealing as it is
+// authenticated along with the contents.
+func seal(a *Archive, plaintext []byte, opts SealOptions) error {
+	if (opts.Passphrase == "") == (opts.PublicKey == "") {
+		return ErrInvalidSealOptions
+	}
+
+	var key []byte
+
+	if opts.Passphrase != "" {
+		salt := make([]byte, saltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+
+		derived, err := scrypt.Key([]byte(opts.Passphrase), salt, scryptN, scryptR, scryptP, keyLength)
+		if err != nil {
+			return err
+		}
+
+		a.Sealing = SealingPassphrase
+		a.Salt = salt
+		key = derived
+	} else {
+		pub, err := parseRSAPublicKey(opts.PublicKey)
+		if err != nil {
+			return err
+		}
+
+		key = make([]byte, keyLength)
+		if _, err := rand.Read(key); err != nil {
+			return err
+		}
+
+		wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, key, oaepLabel)
+		if err != nil {
+			return err
+		}
+
+		a.Sealing = SealingPublicKey
+		a.WrappedKey = wrapped
+	}
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(plaintext); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	ad, err := a.additionalData()
+	if err != nil {
+		return err
+	}
+
+	a.Nonce = nonce
+	a.Ciphertext = gcm.Seal(nil, nonce, compressed.Bytes(), ad)
+
+	return nil
+}
+
+// open decrypts and decompresses the contents of the archive.
+func open(a *Archive, opts OpenOptions) ([]byte, error) {
+	var key []byte
+
+	switch a.Sealing {
+	default:
+		return nil, fmt.Errorf("unsupported archive sealing: %s", a.Sealing)
+	case SealingPassphrase:
+		if opts.Passphrase == "" {
+			return nil, ErrInvalidOpenOptions
+		}
+
+		derived, err := scrypt.Key([]byte(opts.Passphrase), a.Salt, scryptN, scryptR, scryptP, keyLength)
+		if err != nil {
+			return nil, err
+		}
+
+		key = derived
+	case SealingPublicKey:
+		if opts.PrivateKey == "" {
+			return nil, ErrInvalidOpenOptions
+		}
+
+		priv, err := parseRSAPrivateKey(opts.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+
+		unwrapped, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, priv, a.WrappedKey, oaepLabel)
+		if err != nil {
+			return nil, ErrUnableToOpen
+		}
+
+		key = unwrapped
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(a.Nonce) != gcm.NonceSize() {
+		return nil, ErrUnableToOpen
+	}
+
+	ad, err := a.additionalData()
+	if err != nil {
+		return nil, err
+	}
+
+	compressed, err := gcm.Open(nil, a.Nonce, a.Ciphertext, ad)
+	if err != nil {
+		return nil, ErrUnableToOpen
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return io.ReadAll(zr)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func parseRSAPublicKey(pemStr string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(pemStr))
+	if block == nil {
+		return nil, fmt.Errorf("public key is not PEM encoded")
+	}
+
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		k, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		pub, ok := k.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key is not an RSA public key")
+		}
+		return pub, nil
+	}
+
+	return nil, fmt.Errorf("unsupported public key PEM type: %s", block.Type)
+}
+
+func parseRSAPrivateKey(pemStr string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemStr))
+	if block == nil {
+		return nil, fmt.Errorf("private key is not PEM encoded")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		priv, ok := k.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("private key is not an RSA private key")
+		}
+		return priv, nil
+	}
+
+	return nil, fmt.Errorf("unsupported private key PEM type: %s", block.Type)
+}
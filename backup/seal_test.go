@@ -0,0 +1,147 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+)
+
+func newTestArchive() *Archive {
+	return &Archive{
+		Version:   ArchiveVersion,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		ChainID:   flow.Emulator,
+	}
+}
+
+func TestSealWithPassphrase(t *testing.T) {
+	plaintext := []byte(`{"accounts":[]}`)
+
+	a := newTestArchive()
+	if err := seal(a, plaintext, SealOptions{Passphrase: "correct horse"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Sealing != SealingPassphrase {
+		t.Fatalf("expected sealing %q, got %q", SealingPassphrase, a.Sealing)
+	}
+
+	t.Run("opens with correct passphrase", func(t *testing.T) {
+		opened, err := open(a, OpenOptions{Passphrase: "correct horse"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(opened, plaintext) {
+			t.Fatalf("expected %q, got %q", plaintext, opened)
+		}
+	})
+
+	t.Run("opens after JSON roundtrip", func(t *testing.T) {
+		b, err := json.Marshal(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var decoded Archive
+		if err := json.Unmarshal(b, &decoded); err != nil {
+			t.Fatal(err)
+		}
+
+		opened, err := open(&decoded, OpenOptions{Passphrase: "correct horse"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(opened, plaintext) {
+			t.Fatalf("expected %q, got %q", plaintext, opened)
+		}
+	})
+
+	t.Run("fails with wrong passphrase", func(t *testing.T) {
+		if _, err := open(a, OpenOptions{Passphrase: "battery staple"}); err != ErrUnableToOpen {
+			t.Fatalf("expected ErrUnableToOpen, got %v", err)
+		}
+	})
+
+	t.Run("fails with missing passphrase", func(t *testing.T) {
+		if _, err := open(a, OpenOptions{}); err != ErrInvalidOpenOptions {
+			t.Fatalf("expected ErrInvalidOpenOptions, got %v", err)
+		}
+	})
+
+	t.Run("fails with tampered header", func(t *testing.T) {
+		tampered := *a
+		tampered.ChainID = flow.Mainnet
+		if _, err := open(&tampered, OpenOptions{Passphrase: "correct horse"}); err != ErrUnableToOpen {
+			t.Fatalf("expected ErrUnableToOpen, got %v", err)
+		}
+	})
+
+	t.Run("fails with tampered ciphertext", func(t *testing.T) {
+		tampered := *a
+		tampered.Ciphertext = append([]byte{}, a.Ciphertext...)
+		tampered.Ciphertext[0] ^= 0xff
+		if _, err := open(&tampered, OpenOptions{Passphrase: "correct horse"}); err != ErrUnableToOpen {
+			t.Fatalf("expected ErrUnableToOpen, got %v", err)
+		}
+	})
+}
+
+func TestSealWithPublicKey(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubBytes, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubBytes}))
+	privPEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}))
+
+	plaintext := []byte(`{"accounts":[]}`)
+
+	a := newTestArchive()
+	if err := seal(a, plaintext, SealOptions{PublicKey: pubPEM}); err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Sealing != SealingPublicKey {
+		t.Fatalf("expected sealing %q, got %q", SealingPublicKey, a.Sealing)
+	}
+
+	opened, err := open(a, OpenOptions{PrivateKey: privPEM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Fatalf("expected %q, got %q", plaintext, opened)
+	}
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(other)}))
+
+	if _, err := open(a, OpenOptions{PrivateKey: otherPEM}); err != ErrUnableToOpen {
+		t.Fatalf("expected ErrUnableToOpen, got %v", err)
+	}
+}
+
+func TestSealOptions(t *testing.T) {
+	if err := seal(newTestArchive(), []byte("{}"), SealOptions{}); err != ErrInvalidSealOptions {
+		t.Fatalf("expected ErrInvalidSealOptions, got %v", err)
+	}
+
+	if err := seal(newTestArchive(), []byte("{}"), SealOptions{Passphrase: "a", PublicKey: "b"}); err != ErrInvalidSealOptions {
+		t.Fatalf("expected ErrInvalidSealOptions, got %v", err)
+	}
+}
@@ -0,0 +1,271 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/flow-hydraulics/flow-wallet-api/tokens"
+	"github.com/onflow/flow-go-sdk/crypto"
+	log "github.com/sirupsen/logrus"
+)
+
+type Service interface {
+	Export(ctx context.Context, opts SealOptions) (*Archive, error)
+	Restore(ctx context.Context, archive *Archive, opts OpenOptions) (*RestoreResult, error)
+}
+
+// ServiceImpl defines the API for backup export and restore.
+type ServiceImpl struct {
+	cfg   *configs.Config
+	store Store
+	km    keys.Manager
+}
+
+// NewService initiates a new backup service.
+func NewService(
+	cfg *configs.Config,
+	store Store,
+	km keys.Manager,
+) Service {
+	// TODO(latenssi): safeguard against nil config?
+	return &ServiceImpl{cfg, store, km}
+}
+
+// Export creates a sealed archive of all accounts, their decrypted keys and
+// account tokens.
+func (s *ServiceImpl) Export(ctx context.Context, opts SealOptions) (*Archive, error) {
+	log.Trace("Export backup")
+
+	if (opts.Passphrase == "") == (opts.PublicKey == "") {
+		return nil, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: ErrInvalidSealOptions}
+	}
+
+	aa, err := s.store.Accounts()
+	if err != nil {
+		return nil, err
+	}
+
+	tt, err := s.store.AccountTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	contents := Contents{
+		Accounts:      make([]Account, 0, len(aa)),
+		AccountTokens: make([]AccountToken, 0, len(tt)),
+	}
+
+	for _, a := range aa {
+		archived := Account{
+			Address:    a.Address,
+			ExternalID: a.ExternalID,
+			Labels:     a.Labels,
+			Type:       a.Type,
+			ClosedAt:   a.ClosedAt,
+			Keys:       make([]Key, 0, len(a.Keys)),
+			CreatedAt:  a.CreatedAt,
+		}
+
+		for _, sk := range a.Keys {
+			// Decrypt the key so it can be re-encrypted with the crypter of the
+			// restoring instance
+			k, err := s.km.Load(sk)
+			if err != nil {
+				return nil, fmt.Errorf("error while decrypting key %d of account %s: %w", sk.Index, a.Address, err)
+			}
+
+			archived.Keys = append(archived.Keys, Key{
+				Index:     sk.Index,
+				Type:      sk.Type,
+				Value:     k.Value,
+				PublicKey: sk.PublicKey,
+				SignAlgo:  sk.SignAlgo,
+				HashAlgo:  sk.HashAlgo,
+				Weight:    sk.Weight,
+			})
+		}
+
+		contents.Accounts = append(contents.Accounts, archived)
+	}
+
+	for _, t := range tt {
+		contents.AccountTokens = append(contents.AccountTokens, AccountToken{
+			AccountAddress: t.AccountAddress,
+			TokenName:      t.TokenName,
+			TokenAddress:   t.TokenAddress,
+			TokenType:      t.TokenType,
+		})
+	}
+
+	plaintext, err := json.Marshal(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &Archive{
+		Version:   ArchiveVersion,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		ChainID:   s.cfg.ChainID,
+	}
+
+	if err := seal(archive, plaintext, opts); err != nil {
+		return nil, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: err}
+	}
+
+	log.
+		WithFields(log.Fields{"accounts": len(contents.Accounts), "accountTokens": len(contents.AccountTokens)}).
+		Info("Backup exported")
+
+	return archive, nil
+}
+
+// Restore opens a sealed archive, validates it and inserts its accounts,
+// keys and account tokens. Keys are re-encrypted using the crypter of this
+// instance. Accounts that already exist are skipped.
+func (s *ServiceImpl) Restore(ctx context.Context, archive *Archive, opts OpenOptions) (*RestoreResult, error) {
+	log.Trace("Restore backup")
+
+	if archive == nil {
+		return nil, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("archive is required")}
+	}
+
+	if archive.Version < 1 || archive.Version > ArchiveVersion {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("unsupported archive version: %d", archive.Version),
+		}
+	}
+
+	if archive.ChainID != s.cfg.ChainID {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("archive chain id %s does not match %s", archive.ChainID, s.cfg.ChainID),
+		}
+	}
+
+	plaintext, err := open(archive, opts)
+	if err != nil {
+		return nil, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: err}
+	}
+
+	var contents Contents
+	if err := json.Unmarshal(plaintext, &contents); err != nil {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid archive contents: %w", err),
+		}
+	}
+
+	result := &RestoreResult{SkippedAccounts: []string{}}
+	restored := make(map[string]bool)
+	aa := []accounts.Account{}
+
+	for _, archived := range contents.Accounts {
+		exists, err := s.store.AccountExists(archived.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		if exists {
+			result.SkippedAccounts = append(result.SkippedAccounts, archived.Address)
+			continue
+		}
+
+		a := accounts.Account{
+			Address:    archived.Address,
+			ExternalID: archived.ExternalID,
+			Labels:     archived.Labels,
+			Type:       archived.Type,
+			ClosedAt:   archived.ClosedAt,
+			Keys:       make([]keys.Storable, 0, len(archived.Keys)),
+			CreatedAt:  archived.CreatedAt,
+		}
+
+		for _, k := range archived.Keys {
+			if err := s.verifyKey(ctx, k); err != nil {
+				return nil, &errors.RequestError{
+					StatusCode: http.StatusBadRequest,
+					Err:        fmt.Errorf("invalid key %d of account %s: %w", k.Index, archived.Address, err),
+				}
+			}
+
+			// Convert the key to storable form (encrypt it)
+			sk, err := s.km.Save(keys.Private{
+				Index:    k.Index,
+				Type:     k.Type,
+				Value:    k.Value,
+				SignAlgo: crypto.StringToSignatureAlgorithm(k.SignAlgo),
+				HashAlgo: crypto.StringToHashAlgorithm(k.HashAlgo),
+				Weight:   k.Weight,
+			})
+			if err != nil {
+				return nil, err
+			}
+			sk.AccountAddress = archived.Address
+			sk.PublicKey = k.PublicKey
+
+			a.Keys = append(a.Keys, sk)
+		}
+
+		restored[a.Address] = true
+		aa = append(aa, a)
+	}
+
+	tt := []tokens.AccountToken{}
+	for _, t := range contents.AccountTokens {
+		if !restored[t.AccountAddress] {
+			continue
+		}
+
+		tt = append(tt, tokens.AccountToken{
+			AccountAddress: t.AccountAddress,
+			TokenName:      t.TokenName,
+			TokenAddress:   t.TokenAddress,
+			TokenType:      t.TokenType,
+		})
+	}
+
+	if err := s.store.InsertAll(aa, tt); err != nil {
+		return nil, err
+	}
+
+	result.Accounts = len(aa)
+	result.AccountTokens = len(tt)
+
+	log.
+		WithFields(log.Fields{"accounts": result.Accounts, "accountTokens": result.AccountTokens, "skipped": len(result.SkippedAccounts)}).
+		Info("Backup restored")
+
+	return result, nil
+}
+
+// verifyKey checks that a local private key matches its archived public key.
+// Keys in a remote key management system are not verified.
+func (s *ServiceImpl) verifyKey(ctx context.Context, k Key) error {
+	if !keys.IsValidKeyType(k.Type) {
+		return fmt.Errorf("invalid key type: %s", k.Type)
+	}
+
+	if k.Type != keys.AccountKeyTypeLocal || k.PublicKey == "" {
+		return nil
+	}
+
+	pbk, _, err := s.km.Import(ctx, k.Type, k.Value, crypto.StringToSignatureAlgorithm(k.SignAlgo))
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(pbk.String(), k.PublicKey) {
+		return fmt.Errorf("private key does not match public key")
+	}
+
+	return nil
+}
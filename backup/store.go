@@ -0,0 +1,21 @@
+package backup
+
+import (
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/tokens"
+)
+
+// Store manages data regarding backups.
+type Store interface {
+	// List all accounts including their keys.
+	Accounts() ([]accounts.Account, error)
+
+	// List all account tokens.
+	AccountTokens() ([]tokens.AccountToken, error)
+
+	// Check if an account exists.
+	AccountExists(address string) (bool, error)
+
+	// Insert accounts (including their keys) and account tokens.
+	InsertAll(aa []accounts.Account, tt []tokens.AccountToken) error
+}
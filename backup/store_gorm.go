@@ -0,0 +1,51 @@
+package backup
+
+import (
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/datastore/lib"
+	"github.com/flow-hydraulics/flow-wallet-api/tokens"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormStore struct {
+	db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) Store {
+	return &GormStore{db}
+}
+
+func (s *GormStore) Accounts() (aa []accounts.Account, err error) {
+	err = s.db.Preload("Keys").Order("created_at asc").Find(&aa).Error
+	return
+}
+
+func (s *GormStore) AccountTokens() (tt []tokens.AccountToken, err error) {
+	err = s.db.Order("id asc").Find(&tt).Error
+	return
+}
+
+func (s *GormStore) AccountExists(address string) (bool, error) {
+	var count int64
+	err := s.db.Model(&accounts.Account{}).Where("address = ?", address).Count(&count).Error
+	return count > 0, err
+}
+
+func (s *GormStore) InsertAll(aa []accounts.Account, tt []tokens.AccountToken) error {
+	return lib.GormTransaction(s.db, func(tx *gorm.DB) error {
+		for i := range aa {
+			if err := tx.Create(&aa[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		for i := range tt {
+			if err := tx.Omit(clause.Associations).Create(&tt[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
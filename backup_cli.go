@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/flow-hydraulics/flow-wallet-api/backup"
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/datastore/gorm"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/flow-hydraulics/flow-wallet-api/keys/basic"
+	log "github.com/sirupsen/logrus"
+)
+
+// runExportBackup exports a sealed backup archive of the database to path.
+// The archive is sealed using "BackupPassphrase" or "BackupPublicKeyPath".
+func runExportBackup(cfg *configs.Config, path string) {
+	configs.ConfigureLogger(cfg.LogLevel)
+
+	opts := backup.SealOptions{Passphrase: cfg.BackupPassphrase}
+	if cfg.BackupPublicKeyPath != "" {
+		b, err := os.ReadFile(cfg.BackupPublicKeyPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts.PublicKey = string(b)
+	}
+
+	db, err := gorm.New(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer gorm.Close(db)
+
+	// Flow client is not needed for encrypting and decrypting keys
+	km := basic.NewKeyManager(cfg, keys.NewGormStore(db), nil)
+	svc := backup.NewService(cfg, backup.NewGormStore(db), km)
+
+	archive, err := svc.Export(context.Background(), opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	b, err := json.Marshal(archive)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		log.Fatal(err)
+	}
+
+	log.WithFields(log.Fields{"path": path}).Info("Backup archive written")
+}
+
+// runRestoreBackup restores a sealed backup archive from path to the database.
+// The archive is opened using "BackupPassphrase" or "BackupPrivateKeyPath".
+func runRestoreBackup(cfg *configs.Config, path string) {
+	configs.ConfigureLogger(cfg.LogLevel)
+
+	opts := backup.OpenOptions{Passphrase: cfg.BackupPassphrase}
+	if cfg.BackupPrivateKeyPath != "" {
+		b, err := os.ReadFile(cfg.BackupPrivateKeyPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts.PrivateKey = string(b)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var archive backup.Archive
+	if err := json.Unmarshal(b, &archive); err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := gorm.New(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer gorm.Close(db)
+
+	// Flow client is not needed for encrypting and decrypting keys
+	km := basic.NewKeyManager(cfg, keys.NewGormStore(db), nil)
+	svc := backup.NewService(cfg, backup.NewGormStore(db), km)
+
+	res, err := svc.Restore(context.Background(), &archive, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.
+		WithFields(log.Fields{"accounts": res.Accounts, "accountTokens": res.AccountTokens, "skippedAccounts": res.SkippedAccounts}).
+		Info("Backup archive restored")
+}
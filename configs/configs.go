@@ -1,8 +1,10 @@
 package configs
 
 import (
+	"fmt"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -59,6 +61,15 @@ type Config struct {
 	EncryptionKeyType string `env:"ENCRYPTION_KEY_TYPE,notEmpty" envDefault:"local"`
 	// DefaultAccountKeyCount specifies how many times the account key will be duplicated upon account creation, does not affect existing accounts
 	DefaultAccountKeyCount uint `env:"DEFAULT_ACCOUNT_KEY_COUNT" envDefault:"1"`
+	// When enabled, proposal key sequence numbers are tracked locally in the
+	// database instead of fetching the account from chain for each transaction.
+	// This allows several in-flight transactions to share a proposal key.
+	SequenceNumberTracking bool `env:"SEQUENCE_NUMBER_TRACKING" envDefault:"false"`
+
+	// With sequence number tracking enabled, the on-chain keys of user
+	// accounts are cached for this long to skip revoked keys without
+	// fetching the account for each transaction.
+	AccountKeyCacheTTL time.Duration `env:"ACCOUNT_KEY_CACHE_TTL" envDefault:"1m"`
 
 	// -- Database --
 
@@ -95,6 +106,25 @@ type Config struct {
 	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
 	// For more info: https://pkg.go.dev/time#ParseDuration
 	JobStatusWebhookTimeout time.Duration `env:"JOB_STATUS_WEBHOOK_TIMEOUT" envDefault:"30s"`
+	// Send a notification to the job status webhook when a deposit to an
+	// account is registered.
+	EnableDepositWebhooks bool `env:"ENABLE_DEPOSIT_WEBHOOKS" envDefault:"false"`
+
+	// -- Seal tracker --
+
+	// When enabled, workers of asynchronous transaction jobs only build, sign
+	// and send transactions. Results of sent transactions are polled by the
+	// seal tracker which resolves the jobs once the transactions are sealed,
+	// expired or failed.
+	EnableSealTracker bool `env:"ENABLE_SEAL_TRACKER" envDefault:"false"`
+	// Interval for polling the results of pending transactions.
+	SealTrackerInterval time.Duration `env:"SEAL_TRACKER_INTERVAL" envDefault:"1s"`
+	// Maximum number of pending transactions to poll per interval.
+	SealTrackerBatchSize int `env:"SEAL_TRACKER_BATCH_SIZE" envDefault:"100"`
+	// Pending transactions which have not been included in a block for this
+	// long are checked for expiry. They are considered expired only if their
+	// reference block is more than 600 blocks behind the latest sealed block.
+	SealTrackerTimeout time.Duration `env:"SEAL_TRACKER_TIMEOUT" envDefault:"10m"`
 
 	// -- Google KMS --
 
@@ -102,6 +132,127 @@ type Config struct {
 	GoogleKMSLocationID string `env:"GOOGLE_KMS_LOCATION_ID"`
 	GoogleKMSKeyRingID  string `env:"GOOGLE_KMS_KEYRING_ID"`
 
+	// -- Payer pool --
+
+	// Strategy for selecting the payer of a transaction from the payer pool,
+	// one of: round-robin, least-recently-used, balance
+	PayerPoolStrategy string `env:"PAYER_POOL_STRATEGY" envDefault:"round-robin"`
+	// Payers whose FLOW balance is below this threshold are excluded from the pool.
+	PayerPoolMinBalance string `env:"PAYER_POOL_MIN_BALANCE" envDefault:"0.01"`
+	// Interval for checking the FLOW balances of payers.
+	PayerPoolBalanceCheckInterval time.Duration `env:"PAYER_POOL_BALANCE_CHECK_INTERVAL" envDefault:"1m"`
+	// With the balance strategy, payers are rotated among this many payers
+	// with the highest balance.
+	PayerPoolBalanceTop int `env:"PAYER_POOL_BALANCE_TOP" envDefault:"3"`
+
+	// -- Account pool --
+
+	// Number of pre-created accounts to keep in the account pool. Account
+	// creation hands out pooled accounts when available. Set to 0 to disable.
+	AccountPoolSize uint `env:"ACCOUNT_POOL_SIZE" envDefault:"0"`
+	// Interval for refilling the account pool.
+	AccountPoolRefillInterval time.Duration `env:"ACCOUNT_POOL_REFILL_INTERVAL" envDefault:"10s"`
+	// Maximum number of accounts created per refill interval.
+	AccountPoolRefillRate uint `env:"ACCOUNT_POOL_REFILL_RATE" envDefault:"5"`
+
+	// -- Batch account creation --
+
+	// Number of accounts created per transaction by batch account creation.
+	AccountBatchSize uint `env:"ACCOUNT_BATCH_SIZE" envDefault:"50"`
+	// Maximum number of accounts which can be requested in a single batch.
+	MaxAccountBatchCount uint `env:"MAX_ACCOUNT_BATCH_COUNT" envDefault:"10000"`
+
+	// -- Balance history --
+
+	// Interval for taking snapshots of the fungible token balances of all
+	// accounts. Set to 0 to disable periodic snapshots.
+	BalanceSnapshotInterval time.Duration `env:"BALANCE_SNAPSHOT_INTERVAL" envDefault:"0"`
+	// Take a snapshot of the balance of an account when a deposit to or a
+	// withdrawal from the account is seen on chain, or a withdrawal sent by
+	// the service is sealed.
+	BalanceSnapshotOnEvents bool `env:"BALANCE_SNAPSHOT_ON_EVENTS" envDefault:"false"`
+
+	// -- Reports --
+
+	// Number of accounts whose balances are read by a single script when
+	// computing reports.
+	ReportBatchSize uint `env:"REPORT_BATCH_SIZE" envDefault:"100"`
+
+	// -- NFT metadata --
+
+	// Number of NFTs whose metadata views are resolved by a single script.
+	NftMetadataBatchSize uint `env:"NFT_METADATA_BATCH_SIZE" envDefault:"50"`
+	// Time resolved NFT metadata is cached for. Cached metadata of an NFT is
+	// also dropped when a deposit or withdrawal of the NFT is seen on chain.
+	// Set to 0 to disable caching.
+	NftMetadataCacheTTL time.Duration `env:"NFT_METADATA_CACHE_TTL" envDefault:"24h"`
+
+	// -- Reconciliation --
+
+	// Interval for scheduling reconciliation of the recorded token transfers
+	// of all accounts against their on-chain balances. Set to 0 to only
+	// reconcile on request.
+	ReconciliationInterval time.Duration `env:"RECONCILIATION_INTERVAL" envDefault:"0"`
+	// Rescan the blocks of a discrepancy for missing deposits in periodic
+	// reconciliation.
+	ReconciliationBackfill bool `env:"RECONCILIATION_BACKFILL" envDefault:"false"`
+	// Transfers whose transaction has not been sealed within this period are
+	// no longer waited for when reconciling an account.
+	ReconciliationGracePeriod time.Duration `env:"RECONCILIATION_GRACE_PERIOD" envDefault:"10m"`
+
+	// -- Fee sponsorship --
+
+	// Enables the endpoint for paying the fees of transactions signed by
+	// non-custodial accounts.
+	EnableFeeSponsorship bool `env:"ENABLE_FEE_SPONSORSHIP" envDefault:"false"`
+	// Hex encoded SHA3-256 hashes of the transaction scripts allowed to be
+	// sponsored, separated by commas. No transactions are sponsored if empty.
+	SponsorAllowedScriptHashes []string `env:"SPONSOR_ALLOWED_SCRIPT_HASHES" envSeparator:","`
+	// Maximum gas limit of a sponsored transaction.
+	SponsorMaxGasLimit uint64 `env:"SPONSOR_MAX_GAS_LIMIT" envDefault:"9999"`
+
+	// -- Compute limits --
+
+	// Compute (gas) limit of transactions unless overridden.
+	DefaultComputeLimit uint64 `env:"DEFAULT_COMPUTE_LIMIT" envDefault:"9999"`
+	// Maximum compute limit which can be requested for a single transaction
+	// or set for a Cadence template.
+	MaxComputeLimit uint64 `env:"MAX_COMPUTE_LIMIT" envDefault:"9999"`
+	// Compute limits by transaction kind overriding the default, separated by
+	// commas, e.g. "fttransfer:200,accountcreation:1000". Kinds are the
+	// transaction types (general, ftsetup, fttransfer, nftsetup, nfttransfer,
+	// contractdeploy, contractupdate, contractremove) and accountcreation,
+	// accountbatch and proposalkeys.
+	ComputeLimits []string `env:"COMPUTE_LIMITS" envSeparator:","`
+
+	// -- Scripts --
+
+	// Number of results of scripts executed at a given block height or ID to
+	// keep in memory. Results at the latest block are never cached.
+	// Set to 0 to disable the cache.
+	ScriptCacheSize int `env:"SCRIPT_CACHE_SIZE" envDefault:"0"`
+
+	// -- Code allowlist --
+
+	// Only allow raw transactions whose normalized code hash is in the code
+	// allowlist. The allowlist is managed through the API.
+	EnableCodeAllowlist bool `env:"ENABLE_CODE_ALLOWLIST" envDefault:"false"`
+
+	// -- Backup --
+
+	// Enables the backup export and restore API endpoints. Exported archives
+	// contain decrypted key material so the endpoints are disabled by default.
+	EnableBackupApi bool `env:"ENABLE_BACKUP_API" envDefault:"false"`
+	// Passphrase used to seal and open archives with the "-export-backup"
+	// and "-restore-backup" commands.
+	BackupPassphrase string `env:"BACKUP_PASSPHRASE"`
+	// Path to a PEM encoded RSA public key used by "-export-backup" to seal
+	// archives instead of a passphrase.
+	BackupPublicKeyPath string `env:"BACKUP_PUBLIC_KEY_PATH"`
+	// Path to a PEM encoded RSA private key used by "-restore-backup" to open
+	// archives sealed with a public key.
+	BackupPrivateKeyPath string `env:"BACKUP_PRIVATE_KEY_PATH"`
+
 	// -- Misc --
 
 	// Duration for which to wait for a transaction seal, if 0 wait indefinitely. Default: 0.
@@ -165,8 +316,44 @@ type Config struct {
 func Parse(opts ...env.Options) (*Config, error) {
 	cfg := Config{}
 	opts = append(opts, env.Options{Prefix: "FLOW_WALLET_"})
-	err := env.Parse(&cfg, opts...)
-	return &cfg, err
+	if err := env.Parse(&cfg, opts...); err != nil {
+		return &cfg, err
+	}
+
+	for _, l := range cfg.ComputeLimits {
+		_, limit, err := parseComputeLimit(l)
+		if err != nil {
+			return &cfg, err
+		}
+		if limit > cfg.MaxComputeLimit {
+			return &cfg, fmt.Errorf(`compute limit "%s" exceeds the maximum compute limit %d`, l, cfg.MaxComputeLimit)
+		}
+	}
+
+	return &cfg, nil
+}
+
+// ComputeLimit returns the compute limit of a kind of transaction.
+func (cfg *Config) ComputeLimit(kind string) uint64 {
+	for _, l := range cfg.ComputeLimits {
+		k, limit, err := parseComputeLimit(l)
+		if err == nil && strings.EqualFold(k, kind) {
+			return limit
+		}
+	}
+	return cfg.DefaultComputeLimit
+}
+
+func parseComputeLimit(s string) (string, uint64, error) {
+	ss := strings.Split(s, ":")
+	if len(ss) != 2 {
+		return "", 0, fmt.Errorf(`invalid compute limit "%s", expected "kind:limit"`, s)
+	}
+	limit, err := strconv.ParseUint(strings.TrimSpace(ss[1]), 10, 64)
+	if err != nil || limit == 0 {
+		return "", 0, fmt.Errorf(`invalid compute limit "%s"`, s)
+	}
+	return strings.TrimSpace(ss[0]), limit, nil
 }
 
 func ConfigureLogger(logLevel string) {
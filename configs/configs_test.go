@@ -37,3 +37,36 @@ func TestParseConfig(t *testing.T) {
 		)
 	}
 }
+
+func TestComputeLimits(t *testing.T) {
+	t.Setenv("FLOW_WALLET_ADMIN_ADDRESS", "admin-address")
+	t.Setenv("FLOW_WALLET_ADMIN_PRIVATE_KEY", "admin-private-key")
+	t.Setenv("FLOW_WALLET_ENCRYPTION_KEY", "encryption-key")
+	t.Setenv("FLOW_WALLET_ACCESS_API_HOST", "access-api-host")
+	t.Setenv("FLOW_WALLET_DEFAULT_COMPUTE_LIMIT", "500")
+	t.Setenv("FLOW_WALLET_COMPUTE_LIMITS", "fttransfer:200,AccountCreation:1000")
+
+	cfg, err := Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for kind, expected := range map[string]uint64{"FtTransfer": 200, "accountcreation": 1000, "general": 500} {
+		if got := cfg.ComputeLimit(kind); got != expected {
+			t.Errorf("expected compute limit of %s to equal %d, got %d", kind, expected, got)
+		}
+	}
+
+	t.Setenv("FLOW_WALLET_COMPUTE_LIMITS", "fttransfer")
+
+	if _, err := Parse(); err == nil {
+		t.Error("expected an invalid compute limit to fail")
+	}
+
+	t.Setenv("FLOW_WALLET_MAX_COMPUTE_LIMIT", "999")
+	t.Setenv("FLOW_WALLET_COMPUTE_LIMITS", "fttransfer:200,accountcreation:1000")
+
+	if _, err := Parse(); err == nil {
+		t.Error("expected a compute limit above the maximum to fail")
+	}
+}
@@ -0,0 +1,30 @@
+// Package decommission provides functions for retiring custodial accounts.
+package decommission
+
+import "time"
+
+// Request describes how an account is decommissioned.
+type Request struct {
+	// Address receiving the fungible token balances and NFTs of the account.
+	SweepTo string `json:"sweepTo"`
+	// Revoke all on-chain keys of the account after sweeping.
+	RevokeKeys bool `json:"revokeKeys"`
+}
+
+// Transfer is a token transfer made while sweeping an account.
+type Transfer struct {
+	TokenName     string `json:"token"`
+	FtAmount      string `json:"amount,omitempty"`
+	NftID         uint64 `json:"nftId,omitempty"`
+	TransactionId string `json:"transactionId"`
+}
+
+// Result is the outcome of decommissioning an account. It is also stored as
+// the metadata of the decommission job.
+type Result struct {
+	Address                 string     `json:"address"`
+	SweptTo                 string     `json:"sweptTo"`
+	Transfers               []Transfer `json:"transfers"`
+	RevokeKeysTransactionId string     `json:"revokeKeysTransactionId,omitempty"`
+	ClosedAt                *time.Time `json:"closedAt"`
+}
@@ -0,0 +1,62 @@
+package decommission
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+)
+
+const DecommissionJobType = "account_decommission"
+
+type decommissionJobAttributes struct {
+	Address string  `json:"address"`
+	Request Request `json:"request"`
+}
+
+func (s *ServiceImpl) executeDecommissionJob(ctx context.Context, j *jobs.Job) error {
+	if j.Type != DecommissionJobType {
+		return jobs.ErrInvalidJobType
+	}
+
+	j.ShouldSendNotification = true
+
+	var attrs decommissionJobAttributes
+	if err := json.Unmarshal(j.Attributes, &attrs); err != nil {
+		return err
+	}
+
+	// Transfers of a previous execution are kept when the job is retried
+	result := &Result{}
+	if len(j.Metadata) > 0 && string(j.Metadata) != "null" {
+		if err := json.Unmarshal(j.Metadata, result); err != nil {
+			return err
+		}
+	}
+	result.Address = attrs.Address
+	result.SweptTo = attrs.Request.SweepTo
+
+	progress := func(r *Result) error {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		j.Metadata = b
+		return s.wp.SaveProgress(j)
+	}
+
+	if err := s.decommission(ctx, attrs.Request, result, progress); err != nil {
+		return err
+	}
+
+	j.Result = result.Address
+	j.TransactionID = result.RevokeKeysTransactionId
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	j.Metadata = b
+
+	return nil
+}
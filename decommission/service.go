@@ -0,0 +1,384 @@
+package decommission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/payers"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/templates/template_strings"
+	"github.com/flow-hydraulics/flow-wallet-api/tokens"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+)
+
+type Service interface {
+	// Decommission sweeps the token balances and NFTs of a custodial account
+	// to another address, optionally revokes its keys and closes it.
+	Decommission(ctx context.Context, sync bool, address string, req Request) (*jobs.Job, *Result, error)
+}
+
+// ServiceImpl defines the API for account decommissioning.
+type ServiceImpl struct {
+	cfg        *configs.Config
+	accounts   accounts.Store
+	payers     payers.Service
+	tokens     tokens.Service
+	tokenStore tokens.Store
+	temps      templates.Service
+	txs        transactions.Service
+	fc         flow_helpers.FlowClient
+	wp         jobs.WorkerPool
+}
+
+// NewService initiates a new decommission service.
+func NewService(
+	cfg *configs.Config,
+	accountStore accounts.Store,
+	pas payers.Service,
+	tos tokens.Service,
+	tokenStore tokens.Store,
+	temps templates.Service,
+	txs transactions.Service,
+	fc flow_helpers.FlowClient,
+	wp jobs.WorkerPool,
+) Service {
+	svc := &ServiceImpl{cfg, accountStore, pas, tos, tokenStore, temps, txs, fc, wp}
+
+	if wp == nil {
+		panic("workerpool nil")
+	}
+
+	// Register asynchronous job executor.
+	wp.RegisterExecutor(DecommissionJobType, svc.executeDecommissionJob)
+
+	return svc
+}
+
+func (s *ServiceImpl) Decommission(ctx context.Context, sync bool, address string, req Request) (*jobs.Job, *Result, error) {
+	log.WithFields(log.Fields{"sync": sync, "address": address}).Trace("Decommission account")
+
+	address, sweepTo, err := s.validate(address, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.SweepTo = sweepTo
+
+	if !sync {
+		attrBytes, err := json.Marshal(decommissionJobAttributes{Address: address, Request: req})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		job, err := s.wp.CreateJob(DecommissionJobType, "", jobs.WithAttributes(attrBytes))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := s.wp.Schedule(job); err != nil {
+			return nil, nil, err
+		}
+
+		return job, nil, nil
+	}
+
+	result := &Result{Address: address, SweptTo: sweepTo}
+	if err := s.decommission(ctx, req, result, nil); err != nil {
+		return nil, nil, err
+	}
+
+	return nil, result, nil
+}
+
+// validate checks that the account can be decommissioned and returns the
+// formatted addresses of the account and the sweep recipient.
+func (s *ServiceImpl) validate(address string, req Request) (string, string, error) {
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return "", "", err
+	}
+
+	sweepTo, err := flow_helpers.ValidateAddress(req.SweepTo, s.cfg.ChainID)
+	if err != nil {
+		return "", "", &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid sweepTo address: %w", err),
+		}
+	}
+
+	if sweepTo == address {
+		return "", "", &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("sweepTo address has to differ from the decommissioned account"),
+		}
+	}
+
+	if address == flow_helpers.FormatAddress(flow.HexToAddress(s.cfg.AdminAddress)) {
+		return "", "", &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("admin account can not be decommissioned"),
+		}
+	}
+
+	a, err := s.accounts.Account(address)
+	if err != nil {
+		return "", "", err
+	}
+
+	if a.Type != accounts.AccountTypeCustodial {
+		return "", "", &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("only custodial accounts can be decommissioned"),
+		}
+	}
+
+	if _, err := s.payers.Details(address); err == nil {
+		return "", "", &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("account is in the payer pool, remove it from the pool first"),
+		}
+	}
+
+	return address, sweepTo, nil
+}
+
+// decommission closes the account, sweeps its fungible token balances and
+// NFTs and revokes its keys if requested. The account is closed first so no
+// new transactions are authorized by it while it is being swept. Sweeping
+// reads the current balances from chain, so an interrupted decommission can
+// be run again, also on an account which has already been closed. progress
+// is called after each transaction, if given.
+func (s *ServiceImpl) decommission(ctx context.Context, req Request, result *Result, progress func(*Result) error) error {
+	entry := log.WithFields(log.Fields{"address": result.Address, "sweepTo": result.SweptTo})
+
+	a, err := s.accounts.Account(result.Address)
+	if err != nil {
+		return err
+	}
+
+	if !a.IsClosed() {
+		now := time.Now()
+		if err := s.accounts.CloseAccount(a.Address, now); err != nil {
+			return err
+		}
+		a.ClosedAt = &now
+	}
+	result.ClosedAt = a.ClosedAt
+
+	save := func() error {
+		if progress == nil {
+			return nil
+		}
+		return progress(result)
+	}
+
+	if err := s.sweepFungibleTokens(ctx, result, save); err != nil {
+		return err
+	}
+
+	if err := s.sweepNonFungibleTokens(ctx, result, save); err != nil {
+		return err
+	}
+
+	if req.RevokeKeys {
+		txID, err := s.revokeKeys(ctx, result.Address)
+		if err != nil {
+			return err
+		}
+		result.RevokeKeysTransactionId = txID
+		if err := save(); err != nil {
+			return err
+		}
+	}
+
+	entry.
+		WithFields(log.Fields{"transfers": len(result.Transfers), "keysRevoked": req.RevokeKeys}).
+		Info("Account decommissioned")
+
+	return nil
+}
+
+func (s *ServiceImpl) sweepFungibleTokens(ctx context.Context, result *Result, save func() error) error {
+	tokenList, err := s.temps.ListTokensFull(templates.FT)
+	if err != nil {
+		return err
+	}
+
+	enabled, err := s.enabledTokens(result.Address, templates.FT)
+	if err != nil {
+		return err
+	}
+
+	for i := range tokenList {
+		token := &tokenList[i]
+
+		// Only the available balance of FLOW can be moved, the rest is
+		// reserved for the storage used by the account
+		balanceCode := token.Balance
+		if token.Name == "FlowToken" {
+			balanceCode = template_strings.AvailableFlowBalance
+		}
+
+		value, err := s.txs.ExecuteScript(ctx, balanceCode, []transactions.Argument{cadence.NewAddress(flow.HexToAddress(result.Address))})
+		if err != nil {
+			if !enabled[token.Name] {
+				// The account has no vault of a token it has not enabled
+				continue
+			}
+			return fmt.Errorf("error while reading %s balance: %w", token.Name, err)
+		}
+
+		amount, ok := value.(cadence.UFix64)
+		if !ok {
+			return fmt.Errorf("unexpected %s balance type: %s", token.Name, value.Type().ID())
+		}
+
+		if amount == 0 {
+			continue
+		}
+
+		args := []transactions.Argument{amount, cadence.NewAddress(flow.HexToAddress(result.SweptTo))}
+		txID, err := s.transfer(ctx, result, token, args, transactions.FtTransfer, amount.String(), 0)
+		if err != nil {
+			return err
+		}
+
+		result.Transfers = append(result.Transfers, Transfer{TokenName: token.Name, FtAmount: amount.String(), TransactionId: txID})
+		if err := save(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *ServiceImpl) sweepNonFungibleTokens(ctx context.Context, result *Result, save func() error) error {
+	tokenList, err := s.temps.ListTokensFull(templates.NFT)
+	if err != nil {
+		return err
+	}
+
+	enabled, err := s.enabledTokens(result.Address, templates.NFT)
+	if err != nil {
+		return err
+	}
+
+	for i := range tokenList {
+		token := &tokenList[i]
+
+		// The balance script of an NFT returns the IDs in the collection
+		value, err := s.txs.ExecuteScript(ctx, token.Balance, []transactions.Argument{cadence.NewAddress(flow.HexToAddress(result.Address))})
+		if err != nil {
+			if !enabled[token.Name] {
+				// The account has no collection of a token it has not enabled
+				continue
+			}
+			return fmt.Errorf("error while reading %s collection: %w", token.Name, err)
+		}
+
+		ids, ok := value.(cadence.Array)
+		if !ok {
+			return fmt.Errorf("unexpected %s balance type: %s", token.Name, value.Type().ID())
+		}
+
+		for _, v := range ids.Values {
+			id, ok := v.(cadence.UInt64)
+			if !ok {
+				return fmt.Errorf("unexpected %s NFT ID type: %s", token.Name, v.Type().ID())
+			}
+
+			args := []transactions.Argument{cadence.NewAddress(flow.HexToAddress(result.SweptTo)), id}
+			txID, err := s.transfer(ctx, result, token, args, transactions.NftTransfer, "", uint64(id))
+			if err != nil {
+				return err
+			}
+
+			result.Transfers = append(result.Transfers, Transfer{TokenName: token.Name, NftID: uint64(id), TransactionId: txID})
+			if err := save(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// enabledTokens returns the names of the tokens of type tType enabled for the
+// account. Every known token is swept, as the account may hold tokens it has
+// not enabled, but reading the balance of an enabled token has to succeed.
+func (s *ServiceImpl) enabledTokens(address string, tType templates.TokenType) (map[string]bool, error) {
+	accountTokens, err := s.tokens.AccountTokens(address, tType)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := make(map[string]bool, len(accountTokens))
+	for _, at := range accountTokens {
+		enabled[at.TokenName] = true
+	}
+
+	return enabled, nil
+}
+
+// transfer sends a token transfer from the closed account and stores it as a
+// withdrawal of the account.
+func (s *ServiceImpl) transfer(ctx context.Context, result *Result, token *templates.Token, args []transactions.Argument, txType transactions.Type, ftAmount string, nftID uint64) (string, error) {
+	_, tx, err := s.txs.Create(ctx, true, result.Address, token.Transfer, args, txType, transactions.IgnoreAccountGuard(), transactions.TrustedCode())
+	if err != nil {
+		return "", fmt.Errorf("error while sweeping %s: %w", token.Name, err)
+	}
+
+	transfer := &tokens.TokenTransfer{
+		TransactionId:    tx.TransactionId,
+		RecipientAddress: result.SweptTo,
+		SenderAddress:    result.Address,
+		FtAmount:         ftAmount,
+		NftID:            nftID,
+		TokenName:        token.Name,
+	}
+
+	if err := s.tokenStore.InsertTokenTransfer(transfer); err != nil {
+		return "", err
+	}
+
+	return tx.TransactionId, nil
+}
+
+// revokeKeys revokes all keys of the account which have not been revoked yet.
+// Returns the ID of the revoking transaction, or an empty string if all keys
+// were already revoked.
+func (s *ServiceImpl) revokeKeys(ctx context.Context, address string) (string, error) {
+	flowAccount, err := s.fc.GetAccount(ctx, flow.HexToAddress(address))
+	if err != nil {
+		return "", err
+	}
+
+	indexes := []cadence.Value{}
+	for _, k := range flowAccount.Keys {
+		if !k.Revoked {
+			indexes = append(indexes, cadence.NewInt(k.Index))
+		}
+	}
+
+	if len(indexes) == 0 {
+		return "", nil
+	}
+
+	args := []transactions.Argument{cadence.NewArray(indexes)}
+	_, tx, err := s.txs.Create(ctx, true, address, template_strings.RevokeAccountKeysTransaction, args, transactions.General, transactions.IgnoreAccountGuard(), transactions.TrustedCode())
+	if err != nil {
+		return "", fmt.Errorf("error while revoking keys: %w", err)
+	}
+
+	return tx.TransactionId, nil
+}
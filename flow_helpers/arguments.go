@@ -0,0 +1,192 @@
+package flow_helpers
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/parser2"
+)
+
+// Parameter is a parameter declared by a transaction or a script.
+type Parameter struct {
+	Name string
+	Type ast.Type
+}
+
+// ParseParameters parses Cadence code and returns the parameters of its
+// transaction declaration, or of its main function for scripts.
+func ParseParameters(code string) ([]Parameter, error) {
+	program, err := parser2.ParseProgram(code, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var list *ast.ParameterList
+
+	if txs := program.TransactionDeclarations(); len(txs) > 0 {
+		list = txs[0].ParameterList
+	} else {
+		found := false
+		for _, f := range program.FunctionDeclarations() {
+			if f.Identifier.Identifier == "main" {
+				list = f.ParameterList
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no transaction declaration or main function found")
+		}
+	}
+
+	if list == nil {
+		return []Parameter{}, nil
+	}
+
+	params := make([]Parameter, len(list.Parameters))
+	for i, p := range list.Parameters {
+		params[i] = Parameter{Name: p.Identifier.Identifier, Type: p.TypeAnnotation.Type}
+	}
+
+	return params, nil
+}
+
+// ParseType parses a Cadence type, e.g. "UFix64", "[Address]" or "{String: UInt64}?".
+func ParseType(s string) (ast.Type, error) {
+	t, errs := parser2.ParseType(s, nil)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid type %s: %s", s, errs[0])
+	}
+	return t, nil
+}
+
+// CheckArgumentType checks a value against a declared Cadence type.
+// Reference, restricted, capability and function types are left for the
+// chain to check.
+func CheckArgumentType(t ast.Type, v cadence.Value) error {
+	switch t := t.(type) {
+	case *ast.OptionalType:
+		o, ok := v.(cadence.Optional)
+		if !ok {
+			// Non-optional values are accepted for optional parameters
+			return CheckArgumentType(t.Type, v)
+		}
+		if o.Value == nil {
+			return nil
+		}
+		return CheckArgumentType(t.Type, o.Value)
+
+	case *ast.VariableSizedType:
+		a, ok := v.(cadence.Array)
+		if !ok {
+			return typeMismatch(t, v)
+		}
+		return checkElements(t.Type, a.Values)
+
+	case *ast.ConstantSizedType:
+		a, ok := v.(cadence.Array)
+		if !ok {
+			return typeMismatch(t, v)
+		}
+		if t.Size != nil && t.Size.Value != nil && t.Size.Value.Cmp(big.NewInt(int64(len(a.Values)))) != 0 {
+			return fmt.Errorf("expected %s, got %d elements", t, len(a.Values))
+		}
+		return checkElements(t.Type, a.Values)
+
+	case *ast.DictionaryType:
+		d, ok := v.(cadence.Dictionary)
+		if !ok {
+			return typeMismatch(t, v)
+		}
+		for _, pair := range d.Pairs {
+			if err := CheckArgumentType(t.KeyType, pair.Key); err != nil {
+				return fmt.Errorf("dictionary key: %w", err)
+			}
+			if err := CheckArgumentType(t.ValueType, pair.Value); err != nil {
+				return fmt.Errorf("dictionary value: %w", err)
+			}
+		}
+		return nil
+
+	case *ast.NominalType:
+		return checkNominalType(t, v)
+	}
+
+	return nil
+}
+
+func checkElements(t ast.Type, values []cadence.Value) error {
+	for i, e := range values {
+		if err := CheckArgumentType(t, e); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Abstract types which accept any of their subtypes.
+var abstractTypes = map[string]bool{
+	"AnyStruct":        true,
+	"Number":           true,
+	"SignedNumber":     true,
+	"Integer":          true,
+	"SignedInteger":    true,
+	"FixedPoint":       true,
+	"SignedFixedPoint": true,
+}
+
+func checkNominalType(t *ast.NominalType, v cadence.Value) error {
+	name := t.String()
+
+	if abstractTypes[name] {
+		return nil
+	}
+
+	if p, ok := v.(cadence.Path); ok {
+		if pathTypeAllows(name, p.Domain) {
+			return nil
+		}
+		return fmt.Errorf("expected %s, got %s path", name, p.Domain)
+	}
+
+	if v == nil || v.Type() == nil {
+		return typeMismatch(t, v)
+	}
+
+	id := v.Type().ID()
+
+	// Composite types are identified by their location,
+	// e.g. A.0ae53cb6e3f42a79.FlowToken.Vault
+	if id == name || strings.HasSuffix(id, "."+name) {
+		return nil
+	}
+
+	return typeMismatch(t, v)
+}
+
+func pathTypeAllows(name, domain string) bool {
+	switch name {
+	case "Path":
+		return true
+	case "StoragePath":
+		return domain == "storage"
+	case "PublicPath":
+		return domain == "public"
+	case "PrivatePath":
+		return domain == "private"
+	case "CapabilityPath":
+		return domain == "public" || domain == "private"
+	}
+	return false
+}
+
+func typeMismatch(t ast.Type, v cadence.Value) error {
+	got := "unknown type"
+	if v != nil && v.Type() != nil {
+		got = v.Type().ID()
+	}
+	return fmt.Errorf("expected %s, got %s", t, got)
+}
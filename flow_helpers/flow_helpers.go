@@ -17,17 +17,35 @@ import (
 
 type FlowClient interface {
 	ExecuteScriptAtLatestBlock(ctx context.Context, script []byte, arguments []cadence.Value) (cadence.Value, error)
+	ExecuteScriptAtBlockHeight(ctx context.Context, height uint64, script []byte, arguments []cadence.Value) (cadence.Value, error)
+	ExecuteScriptAtBlockID(ctx context.Context, blockID flow.Identifier, script []byte, arguments []cadence.Value) (cadence.Value, error)
 	GetAccount(ctx context.Context, address flow.Address) (*flow.Account, error)
 	GetAccountAtLatestBlock(ctx context.Context, address flow.Address) (*flow.Account, error)
 	GetTransaction(ctx context.Context, txID flow.Identifier) (*flow.Transaction, error)
 	GetTransactionResult(ctx context.Context, txID flow.Identifier) (*flow.TransactionResult, error)
 	GetLatestBlockHeader(ctx context.Context, isSealed bool) (*flow.BlockHeader, error)
+	GetBlockHeaderByID(ctx context.Context, blockID flow.Identifier) (*flow.BlockHeader, error)
 	GetEventsForHeightRange(ctx context.Context, eventType string, startHeight uint64, endHeight uint64) ([]flow.BlockEvents, error)
 	SendTransaction(ctx context.Context, tx flow.Transaction) error
 }
 
 const hexPrefix = "0x"
 
+// TransactionExpiry is the number of blocks after its reference block a
+// transaction can be included in.
+const TransactionExpiry = 600
+
+// IsReferenceBlockExpired returns true if a transaction with the given
+// reference block can no longer be included in a block, i.e. the reference
+// block is more than TransactionExpiry blocks behind the given sealed height.
+func IsReferenceBlockExpired(ctx context.Context, flowClient FlowClient, referenceBlockID flow.Identifier, sealedHeight uint64) (bool, error) {
+	ref, err := flowClient.GetBlockHeaderByID(ctx, referenceBlockID)
+	if err != nil {
+		return false, err
+	}
+	return sealedHeight > ref.Height+TransactionExpiry, nil
+}
+
 // LatestBlockId retuns the flow.Identifier for the latest block in the chain.
 func LatestBlockId(ctx context.Context, flowClient FlowClient) (*flow.Identifier, error) {
 	block, err := flowClient.GetLatestBlockHeader(ctx, false)
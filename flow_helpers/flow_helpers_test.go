@@ -56,3 +56,22 @@ func TestWaitForSeal(t *testing.T) {
 		}
 	})
 }
+
+func TestIsReferenceBlockExpired(t *testing.T) {
+	flowClient := &internal.MockFlowClient{BlockHeight: 100}
+	ctx := context.Background()
+
+	for sealedHeight, expected := range map[uint64]bool{
+		100:                     false,
+		100 + TransactionExpiry: false,
+		101 + TransactionExpiry: true,
+	} {
+		expired, err := IsReferenceBlockExpired(ctx, flowClient, flow.EmptyID, sealedHeight)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expired != expected {
+			t.Fatalf("expected expired = %t at sealed height %d, got %t", expected, sealedHeight, expired)
+		}
+	}
+}
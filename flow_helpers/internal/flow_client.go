@@ -9,12 +9,22 @@ import (
 
 type MockFlowClient struct {
 	getTransactionResultCallCount uint
+	// Height of the blocks returned by GetBlockHeaderByID
+	BlockHeight uint64
 }
 
 func (c *MockFlowClient) ExecuteScriptAtLatestBlock(ctx context.Context, script []byte, arguments []cadence.Value) (cadence.Value, error) {
 	return nil, nil
 }
 
+func (c *MockFlowClient) ExecuteScriptAtBlockHeight(ctx context.Context, height uint64, script []byte, arguments []cadence.Value) (cadence.Value, error) {
+	return nil, nil
+}
+
+func (c *MockFlowClient) ExecuteScriptAtBlockID(ctx context.Context, blockID flow.Identifier, script []byte, arguments []cadence.Value) (cadence.Value, error) {
+	return nil, nil
+}
+
 func (c *MockFlowClient) GetAccount(ctx context.Context, address flow.Address) (*flow.Account, error) {
 	return nil, nil
 }
@@ -40,6 +50,10 @@ func (c *MockFlowClient) GetLatestBlockHeader(ctx context.Context, isSealed bool
 	return nil, nil
 }
 
+func (c *MockFlowClient) GetBlockHeaderByID(ctx context.Context, blockID flow.Identifier) (*flow.BlockHeader, error) {
+	return &flow.BlockHeader{ID: blockID, Height: c.BlockHeight}, nil
+}
+
 func (c *MockFlowClient) GetEventsForHeightRange(ctx context.Context, eventType string, startHeight uint64, endHeight uint64) ([]flow.BlockEvents, error) {
 	return nil, nil
 }
@@ -19,6 +19,23 @@ type SyncKeyCountRequest struct {
 	Address flow.Address `json:"address"`
 }
 
+// CreateAccountRequest represents an optional JSON payload for an account creation HTTP request
+type CreateAccountRequest struct {
+	Keys []accounts.KeySpec `json:"keys"`
+	accounts.Metadata
+}
+
+// ImportAccountRequest represents a JSON payload for an account import HTTP request
+type ImportAccountRequest struct {
+	Address string               `json:"address"`
+	Keys    []accounts.ImportKey `json:"keys"`
+}
+
+// UpdateContractRequest represents a JSON payload for a contract update HTTP request
+type UpdateContractRequest struct {
+	Code string `json:"code"`
+}
+
 // NewAccounts initiates a new accounts server.
 func NewAccounts(service accounts.Service) *Accounts {
 	return &Accounts{service}
@@ -32,6 +49,15 @@ func (s *Accounts) Create() http.Handler {
 	return http.HandlerFunc(s.CreateFunc)
 }
 
+func (s *Accounts) CreateBatch() http.Handler {
+	return http.HandlerFunc(s.CreateBatchFunc)
+}
+
+func (s *Accounts) Import() http.Handler {
+	h := http.HandlerFunc(s.ImportFunc)
+	return UseJson(h)
+}
+
 func (s *Accounts) AddNonCustodialAccount() http.Handler {
 	return http.HandlerFunc(s.AddNonCustodialAccountFunc)
 }
@@ -47,3 +73,34 @@ func (s *Accounts) SyncAccountKeyCount() http.Handler {
 func (s *Accounts) Details() http.Handler {
 	return http.HandlerFunc(s.DetailsFunc)
 }
+
+func (s *Accounts) Update() http.Handler {
+	h := http.HandlerFunc(s.UpdateFunc)
+	return UseJson(h)
+}
+
+func (s *Accounts) KeySets() http.Handler {
+	return http.HandlerFunc(s.KeySetsFunc)
+}
+
+func (s *Accounts) Contracts() http.Handler {
+	return http.HandlerFunc(s.ContractsFunc)
+}
+
+func (s *Accounts) ContractDetails() http.Handler {
+	return http.HandlerFunc(s.ContractDetailsFunc)
+}
+
+func (s *Accounts) DeployContract() http.Handler {
+	h := http.HandlerFunc(s.DeployContractFunc)
+	return UseJson(h)
+}
+
+func (s *Accounts) UpdateContract() http.Handler {
+	h := http.HandlerFunc(s.UpdateContractFunc)
+	return UseJson(h)
+}
+
+func (s *Accounts) RemoveContract() http.Handler {
+	return http.HandlerFunc(s.RemoveContractFunc)
+}
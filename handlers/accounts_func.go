@@ -3,11 +3,14 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
 	"github.com/flow-hydraulics/flow-wallet-api/accounts"
 	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
 	"github.com/gorilla/mux"
 )
 
@@ -23,7 +26,18 @@ func (s *Accounts) ListFunc(rw http.ResponseWriter, r *http.Request) {
 		offset = 0
 	}
 
-	res, err := s.service.List(limit, offset)
+	labels, err := accounts.ParseLabelFilter(r.URL.Query()[LabelQueryParameter])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	filter := accounts.ListFilter{
+		ExternalID: r.FormValue(ExternalIdQueryParameter),
+		Labels:     labels,
+	}
+
+	res, err := s.service.List(limit, offset, filter)
 
 	if err != nil {
 		handleError(rw, r, err)
@@ -34,12 +48,30 @@ func (s *Accounts) ListFunc(rw http.ResponseWriter, r *http.Request) {
 }
 
 // Create creates a new account asynchronously.
+// An optional body may define the keys of the account.
 // It returns a Job JSON representation.
 func (s *Accounts) CreateFunc(rw http.ResponseWriter, r *http.Request) {
+	var opts []accounts.CreateOption
+
+	if r.Body != nil && r.Body != http.NoBody {
+		var req CreateAccountRequest
+		// Try to decode the request body.
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			handleError(rw, r, InvalidBodyError)
+			return
+		}
+
+		if len(req.Keys) > 0 {
+			opts = append(opts, accounts.WithKeys(req.Keys))
+		}
+
+		opts = append(opts, accounts.WithMetadata(req.Metadata))
+	}
+
 	// Decide whether to serve sync or async, default async
 	sync := r.FormValue(SyncQueryParameter) != ""
 
-	job, acc, err := s.service.Create(r.Context(), sync)
+	job, acc, err := s.service.Create(r.Context(), sync, opts...)
 
 	if err != nil {
 		handleError(rw, r, err)
@@ -56,6 +88,53 @@ func (s *Accounts) CreateFunc(rw http.ResponseWriter, r *http.Request) {
 	handleJsonResponse(rw, http.StatusCreated, res)
 }
 
+// CreateBatch creates the number of accounts given in the count query
+// parameter asynchronously, several accounts per transaction.
+// It returns a Job JSON representation.
+func (s *Accounts) CreateBatchFunc(rw http.ResponseWriter, r *http.Request) {
+	count, err := strconv.Atoi(r.FormValue(CountQueryParameter))
+	if err != nil {
+		handleError(rw, r, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid count"),
+		})
+		return
+	}
+
+	job, err := s.service.CreateBatch(r.Context(), count)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, job.ToJSONResponse())
+}
+
+// Import takes an existing Flow account into custody.
+// It returns the imported account.
+func (s *Accounts) ImportFunc(rw http.ResponseWriter, r *http.Request) {
+	// Check body is not empty
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var req ImportAccountRequest
+	// Try to decode the request body.
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(rw, r, InvalidBodyError)
+		return
+	}
+
+	res, err := s.service.Import(r.Context(), req.Address, req.Keys)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
+
 // Details returns details regarding an account.
 // It reads the address for the wanted account from URL.
 // Account service is responsible for validating the address.
@@ -72,6 +151,44 @@ func (s *Accounts) DetailsFunc(rw http.ResponseWriter, r *http.Request) {
 	handleJsonResponse(rw, http.StatusOK, res)
 }
 
+// Update updates the external ID and labels of an account.
+// Omitted fields are left unchanged.
+func (s *Accounts) UpdateFunc(rw http.ResponseWriter, r *http.Request) {
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var req accounts.Metadata
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(rw, r, InvalidBodyError)
+		return
+	}
+
+	res, err := s.service.UpdateMetadata(mux.Vars(r)["address"], req)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// KeySets returns the key sets of an account.
+// Each key set groups the clones of a single logical key.
+func (s *Accounts) KeySetsFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	res, err := s.service.KeySets(vars["address"])
+
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
 func (s *Accounts) AddNonCustodialAccountFunc(rw http.ResponseWriter, r *http.Request) {
 	err := checkNonEmptyBody(r)
 	if err != nil {
@@ -135,3 +252,115 @@ func (s *Accounts) SyncAccountKeyCountFunc(rw http.ResponseWriter, r *http.Reque
 
 	handleJsonResponse(rw, http.StatusOK, job)
 }
+
+// ContractsFunc lists the contracts deployed to an account.
+func (s *Accounts) ContractsFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	res, err := s.service.Contracts(r.Context(), vars["address"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// ContractDetailsFunc returns the code of a contract deployed to an account.
+func (s *Accounts) ContractDetailsFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	res, err := s.service.Contract(r.Context(), vars["address"], vars["name"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// DeployContractFunc deploys a contract to a custodial account. It returns a
+// Job JSON representation, or the transaction if sync mode is enabled.
+func (s *Accounts) DeployContractFunc(rw http.ResponseWriter, r *http.Request) {
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var c accounts.Contract
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		handleError(rw, r, InvalidBodyError)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+
+	job, tx, err := s.service.DeployContract(r.Context(), sync, vars["address"], c)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleContractTransactionResponse(rw, sync, job, tx)
+}
+
+// UpdateContractFunc updates the code of a contract deployed to a custodial
+// account. It returns a Job JSON representation, or the transaction if sync
+// mode is enabled.
+func (s *Accounts) UpdateContractFunc(rw http.ResponseWriter, r *http.Request) {
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var b UpdateContractRequest
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+		handleError(rw, r, InvalidBodyError)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+
+	c := accounts.Contract{Name: vars["name"], Code: b.Code}
+	job, tx, err := s.service.UpdateContract(r.Context(), sync, vars["address"], c)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleContractTransactionResponse(rw, sync, job, tx)
+}
+
+// RemoveContractFunc removes a contract from a custodial account. It returns
+// a Job JSON representation, or the transaction if sync mode is enabled.
+func (s *Accounts) RemoveContractFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+
+	job, tx, err := s.service.RemoveContract(r.Context(), sync, vars["address"], vars["name"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleContractTransactionResponse(rw, sync, job, tx)
+}
+
+func handleContractTransactionResponse(rw http.ResponseWriter, sync bool, job *jobs.Job, tx *transactions.Transaction) {
+	var res interface{}
+	if sync {
+		res = tx.ToJSONResponse()
+	} else {
+		res = job.ToJSONResponse()
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/backup"
+)
+
+// Backup is a HTTP server for backup export and restore.
+type Backup struct {
+	service backup.Service
+}
+
+// RestoreBackupRequest represents a JSON payload for a HTTP request
+type RestoreBackupRequest struct {
+	Archive *backup.Archive `json:"archive"`
+	backup.OpenOptions
+}
+
+// NewBackup initiates a new backup server.
+func NewBackup(service backup.Service) *Backup {
+	return &Backup{service}
+}
+
+func (s *Backup) Export() http.Handler {
+	h := http.HandlerFunc(s.ExportFunc)
+	return UseJson(h)
+}
+
+func (s *Backup) Restore() http.Handler {
+	h := http.HandlerFunc(s.RestoreFunc)
+	return UseJson(h)
+}
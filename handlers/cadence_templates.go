@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+)
+
+// CadenceTemplates is a HTTP server for the Cadence template registry.
+// It provides create, list, details and deprecate APIs and executes
+// templates by name.
+type CadenceTemplates struct {
+	templates    templates.Service
+	transactions transactions.Service
+}
+
+// ExecuteTemplateRequest represents a JSON payload for executing a template.
+// Version 0 executes the latest non-deprecated version.
+type ExecuteTemplateRequest struct {
+	Version     uint                    `json:"version,omitempty"`
+	Arguments   []transactions.Argument `json:"arguments"`
+	Authorizers []string                `json:"authorizers,omitempty"`
+	// ComputeLimit overrides the compute limit of the template
+	ComputeLimit uint64 `json:"computeLimit,omitempty"`
+}
+
+// NewCadenceTemplates initiates a new Cadence template server.
+func NewCadenceTemplates(templates templates.Service, transactions transactions.Service) *CadenceTemplates {
+	return &CadenceTemplates{templates, transactions}
+}
+
+func (s *CadenceTemplates) List() http.Handler {
+	return http.HandlerFunc(s.ListFunc)
+}
+
+func (s *CadenceTemplates) Create() http.Handler {
+	h := http.HandlerFunc(s.CreateFunc)
+	return UseJson(h)
+}
+
+func (s *CadenceTemplates) Details() http.Handler {
+	return http.HandlerFunc(s.DetailsFunc)
+}
+
+func (s *CadenceTemplates) Deprecate() http.Handler {
+	return http.HandlerFunc(s.DeprecateFunc)
+}
+
+func (s *CadenceTemplates) ExecuteTransaction() http.Handler {
+	h := http.HandlerFunc(s.ExecuteTransactionFunc)
+	return UseJson(h)
+}
+
+func (s *CadenceTemplates) ExecuteScript() http.Handler {
+	h := http.HandlerFunc(s.ExecuteScriptFunc)
+	return UseJson(h)
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/gorilla/mux"
+	"github.com/onflow/cadence"
+)
+
+func (s *CadenceTemplates) ListFunc(rw http.ResponseWriter, r *http.Request) {
+	includeDeprecated := r.FormValue("deprecated") == "true"
+
+	tt, err := s.templates.ListCadenceTemplates(r.FormValue("name"), includeDeprecated)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, tt)
+}
+
+func (s *CadenceTemplates) CreateFunc(rw http.ResponseWriter, r *http.Request) {
+	var t templates.CadenceTemplate
+
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		handleError(rw, r, InvalidBodyError)
+		return
+	}
+
+	// ID and version are assigned by the registry
+	t.ID = 0
+	t.Version = 0
+
+	if err := s.templates.AddCadenceTemplate(&t); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, t)
+}
+
+func (s *CadenceTemplates) DetailsFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	version, err := templateVersion(vars)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	t, err := s.templates.GetCadenceTemplate(vars["name"], version)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, t)
+}
+
+func (s *CadenceTemplates) DeprecateFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	version, err := templateVersion(vars)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	t, err := s.templates.DeprecateCadenceTemplate(vars["name"], version)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, t)
+}
+
+func (s *CadenceTemplates) ExecuteTransactionFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	req, t, err := s.executableTemplate(r, templates.TransactionTemplate)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	// The account is the proposer and first authorizer
+	authorizers := append([]string{vars["address"]}, req.Authorizers...)
+
+	// Compute limit of the request overrides the limit of the template
+	var opts []transactions.TransactionOption
+	if req.ComputeLimit > 0 {
+		opts = append(opts, transactions.WithComputeLimit(req.ComputeLimit))
+	} else if t.ComputeLimit > 0 {
+		opts = append(opts, transactions.WithComputeLimit(t.ComputeLimit))
+	}
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+	job, transaction, err := s.transactions.CreateWithAuthorizers(r.Context(), sync, authorizers, t.Code, req.Arguments, transactions.General, opts...)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var res interface{}
+	if sync {
+		res = transaction.ToJSONResponse()
+	} else {
+		res = job.ToJSONResponse()
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
+
+func (s *CadenceTemplates) ExecuteScriptFunc(rw http.ResponseWriter, r *http.Request) {
+	req, t, err := s.executableTemplate(r, templates.ScriptTemplate)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	block, err := scriptBlock(r)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	value, err := s.transactions.ExecuteScriptAtBlock(r.Context(), t.Code, req.Arguments, block)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	res, err := transactions.FormatScriptResult(value, r.FormValue(ScriptFormatQueryParameter))
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// executableTemplate decodes an execute request and returns the requested
+// template after checking the arguments.
+func (s *CadenceTemplates) executableTemplate(r *http.Request, kind templates.TemplateKind) (*ExecuteTemplateRequest, *templates.CadenceTemplate, error) {
+	if err := checkNonEmptyBody(r); err != nil {
+		return nil, nil, err
+	}
+
+	var req ExecuteTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, nil, InvalidBodyError
+	}
+
+	args := make([]cadence.Value, len(req.Arguments))
+	for i, a := range req.Arguments {
+		c, err := transactions.ArgAsCadence(a)
+		if err != nil {
+			return nil, nil, &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("invalid argument at index %d: %w", i, err),
+			}
+		}
+		args[i] = c
+	}
+
+	t, err := s.templates.ExecutableCadenceTemplate(mux.Vars(r)["name"], req.Version, kind, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &req, t, nil
+}
+
+func templateVersion(vars map[string]string) (uint, error) {
+	v, ok := vars["version"]
+	if !ok {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseUint(v, 10, 32)
+	if err != nil || version == 0 {
+		return 0, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid version: %s", v),
+		}
+	}
+
+	return uint(version), nil
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/decommission"
+)
+
+// Decommission is a HTTP server for retiring custodial accounts.
+type Decommission struct {
+	service decommission.Service
+}
+
+// NewDecommission initiates a new decommission server.
+func NewDecommission(service decommission.Service) *Decommission {
+	return &Decommission{service}
+}
+
+func (s *Decommission) Decommission() http.Handler {
+	h := http.HandlerFunc(s.DecommissionFunc)
+	return UseJson(h)
+}
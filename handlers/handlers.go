@@ -17,6 +17,22 @@ import (
 
 const SyncQueryParameter = "sync"
 
+// Script execution query parameters
+const (
+	BlockHeightQueryParameter  = "blockHeight"
+	BlockIdQueryParameter      = "blockId"
+	ScriptFormatQueryParameter = "format"
+)
+
+// Account list query parameters
+const (
+	ExternalIdQueryParameter = "externalId"
+	LabelQueryParameter      = "label"
+)
+
+// Number of accounts to create in a batch
+const CountQueryParameter = "count"
+
 var EmptyBodyError = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("empty body")}
 var InvalidBodyError = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid body")}
 
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/payers"
+)
+
+// Payers is a HTTP server for payer pool management.
+// It provides list, add, details, remove and balance check APIs.
+type Payers struct {
+	service payers.Service
+}
+
+// AddPayerRequest represents a JSON payload for a HTTP request
+type AddPayerRequest struct {
+	Address string `json:"address"`
+}
+
+// NewPayers initiates a new payers server.
+func NewPayers(service payers.Service) *Payers {
+	return &Payers{service}
+}
+
+func (s *Payers) List() http.Handler {
+	return http.HandlerFunc(s.ListFunc)
+}
+
+func (s *Payers) Add() http.Handler {
+	h := http.HandlerFunc(s.AddFunc)
+	return UseJson(h)
+}
+
+func (s *Payers) Details() http.Handler {
+	return http.HandlerFunc(s.DetailsFunc)
+}
+
+func (s *Payers) Remove() http.Handler {
+	return http.HandlerFunc(s.RemoveFunc)
+}
+
+func (s *Payers) CheckBalances() http.Handler {
+	return http.HandlerFunc(s.CheckBalancesFunc)
+}
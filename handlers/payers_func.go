@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/payers"
+	"github.com/gorilla/mux"
+)
+
+// List returns all payers of the pool.
+func (s *Payers) ListFunc(rw http.ResponseWriter, r *http.Request) {
+	pp, err := s.service.List()
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	res := make([]payers.PayerJSON, len(pp))
+	for i, p := range pp {
+		res[i] = p.ToJSON()
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// Add adds a custodial account to the payer pool.
+func (s *Payers) AddFunc(rw http.ResponseWriter, r *http.Request) {
+	// Check body is not empty
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var req AddPayerRequest
+	// Try to decode the request body.
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(rw, r, InvalidBodyError)
+		return
+	}
+
+	p, err := s.service.Add(r.Context(), req.Address)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, p.ToJSON())
+}
+
+// Details returns details regarding a payer.
+func (s *Payers) DetailsFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	p, err := s.service.Details(vars["address"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, p.ToJSON())
+}
+
+// Remove removes a payer from the pool.
+func (s *Payers) RemoveFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if err := s.service.Remove(vars["address"]); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// CheckBalances updates payer balances immediately and returns the payers.
+func (s *Payers) CheckBalancesFunc(rw http.ResponseWriter, r *http.Request) {
+	if err := s.service.CheckBalances(r.Context()); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	s.ListFunc(rw, r)
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/reconciliation"
+)
+
+// Reconciliation is a HTTP server for reconciling recorded token transfers
+// with on-chain balances.
+type Reconciliation struct {
+	service reconciliation.Service
+}
+
+// NewReconciliation initiates a new reconciliation server.
+func NewReconciliation(service reconciliation.Service) *Reconciliation {
+	return &Reconciliation{service}
+}
+
+func (s *Reconciliation) Reconcile() http.Handler {
+	h := http.HandlerFunc(s.ReconcileFunc)
+	return UseJson(h)
+}
+
+func (s *Reconciliation) Discrepancies() http.Handler {
+	return http.HandlerFunc(s.DiscrepanciesFunc)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/flow-hydraulics/flow-wallet-api/reconciliation"
+)
+
+// Reconcile compares the on-chain fungible token balances of accounts with
+// the recorded transfers. It returns a Job JSON representation, or the
+// result of the reconciliation if sync mode is enabled.
+func (s *Reconciliation) ReconcileFunc(rw http.ResponseWriter, r *http.Request) {
+	// Check body is not empty
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var req reconciliation.Request
+	// Try to decode the request body.
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(rw, r, InvalidBodyError)
+		return
+	}
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+
+	job, result, err := s.service.Reconcile(r.Context(), sync, req)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var res interface{}
+	if sync {
+		res = result
+	} else {
+		res = job.ToJSONResponse()
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
+
+// Discrepancies lists the discrepancies found by reconciliation, newest
+// first. Only unresolved discrepancies are listed if the "unresolved" query
+// parameter is set.
+func (s *Reconciliation) DiscrepanciesFunc(rw http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil {
+		offset = 0
+	}
+
+	unresolvedOnly := r.FormValue("unresolved") != ""
+
+	res, err := s.service.Discrepancies(limit, offset, unresolvedOnly)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/reports"
+)
+
+// Reports is a HTTP server for reports over the accounts held in custody.
+type Reports struct {
+	service reports.Service
+}
+
+// NewReports initiates a new reports server.
+func NewReports(service reports.Service) *Reports {
+	return &Reports{service}
+}
+
+func (s *Reports) Liabilities() http.Handler {
+	return http.HandlerFunc(s.LiabilitiesFunc)
+}
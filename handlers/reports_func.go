@@ -0,0 +1,23 @@
+package handlers
+
+import "net/http"
+
+// LiabilitiesFunc returns the total balances of all tokens held by custodial
+// accounts, as JSON or as CSV if the "format" query parameter is "csv".
+func (s *Reports) LiabilitiesFunc(rw http.ResponseWriter, r *http.Request) {
+	res, err := s.service.Liabilities(r.Context(), r.FormValue("groupBy"))
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	if r.FormValue("format") != "csv" {
+		handleJsonResponse(rw, http.StatusOK, res)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/csv")
+	rw.Header().Set("Content-Disposition", `attachment; filename="liabilities.csv"`)
+	rw.WriteHeader(http.StatusOK)
+	res.WriteCSV(rw) // nolint
+}
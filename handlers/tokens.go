@@ -24,11 +24,26 @@ func (s *Tokens) AccountTokens(tType templates.TokenType) http.Handler {
 	return s.MakeAccountTokensFunc(tType)
 }
 
+func (s *Tokens) DiscoverAccountTokens() http.Handler {
+	h := http.HandlerFunc(s.DiscoverAccountTokensFunc)
+	return h
+}
+
 func (s *Tokens) Details() http.Handler {
 	h := http.HandlerFunc(s.DetailsFunc)
 	return h
 }
 
+func (s *Tokens) NftItems() http.Handler {
+	h := http.HandlerFunc(s.NftItemsFunc)
+	return h
+}
+
+func (s *Tokens) BalanceHistory() http.Handler {
+	h := http.HandlerFunc(s.BalanceHistoryFunc)
+	return h
+}
+
 func (s *Tokens) CreateWithdrawal() http.Handler {
 	h := http.HandlerFunc(s.CreateWithdrawalFunc)
 	return UseJson(h)
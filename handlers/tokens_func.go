@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/flow-hydraulics/flow-wallet-api/errors"
 	"github.com/flow-hydraulics/flow-wallet-api/templates"
@@ -51,6 +53,20 @@ func (s *Tokens) MakeAccountTokensFunc(tType templates.TokenType) http.HandlerFu
 	}
 }
 
+func (s *Tokens) DiscoverAccountTokensFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	a := vars["address"]
+
+	res, err := s.service.DiscoverAccountTokens(r.Context(), a)
+
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
 func (s *Tokens) DetailsFunc(rw http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	address := vars["address"]
@@ -66,6 +82,86 @@ func (s *Tokens) DetailsFunc(rw http.ResponseWriter, r *http.Request) {
 	handleJsonResponse(rw, http.StatusOK, res)
 }
 
+// NftItemsFunc lists the NFTs of a token owned by an account with their
+// metadata views, paginated with the "limit" and "offset" query parameters.
+func (s *Tokens) NftItemsFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	address := vars["address"]
+	tokenName := vars["tokenName"]
+
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil {
+		offset = 0
+	}
+
+	res, err := s.service.NftItems(r.Context(), tokenName, address, limit, offset)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+func (s *Tokens) BalanceHistoryFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	address := vars["address"]
+	tokenName := vars["tokenName"]
+
+	from, err := parseTimeParameter(r, "from", false)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	to, err := parseTimeParameter(r, "to", true)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	res, err := s.service.BalanceHistory(tokenName, address, from, to)
+
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// parseTimeParameter parses an optional RFC 3339 time or date (e.g.
+// 2022-11-05, midnight UTC) query parameter. If endOfDay is set a date is
+// parsed as the end of the day, so that the whole day is included.
+func parseTimeParameter(r *http.Request, name string, endOfDay bool) (time.Time, error) {
+	v := r.FormValue(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		if endOfDay {
+			// Microsecond precision, as stored by the database
+			return t.AddDate(0, 0, 1).Add(-time.Microsecond), nil
+		}
+		return t, nil
+	}
+
+	return time.Time{}, &errors.RequestError{
+		StatusCode: http.StatusBadRequest,
+		Err:        fmt.Errorf("invalid %s, expected an RFC 3339 time or a date", name),
+	}
+}
+
 func (s *Tokens) CreateWithdrawalFunc(rw http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	address := vars["address"]
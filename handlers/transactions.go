@@ -24,6 +24,11 @@ func (s *Transactions) Create() http.Handler {
 	return UseJson(h)
 }
 
+func (s *Transactions) Sponsor() http.Handler {
+	h := http.HandlerFunc(s.SponsorFunc)
+	return UseJson(h)
+}
+
 func (s *Transactions) Sign() http.Handler {
 	h := http.HandlerFunc(s.SignFunc)
 	return UseJson(h)
@@ -37,3 +42,16 @@ func (s *Transactions) ExecuteScript() http.Handler {
 	h := http.HandlerFunc(s.ExecuteScriptFunc)
 	return UseJson(h)
 }
+
+func (s *Transactions) ListAllowedCodes() http.Handler {
+	return http.HandlerFunc(s.ListAllowedCodesFunc)
+}
+
+func (s *Transactions) AddAllowedCode() http.Handler {
+	h := http.HandlerFunc(s.AddAllowedCodeFunc)
+	return UseJson(h)
+}
+
+func (s *Transactions) RemoveAllowedCode() http.Handler {
+	return http.HandlerFunc(s.RemoveAllowedCodeFunc)
+}
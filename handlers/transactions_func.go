@@ -1,14 +1,18 @@
 package handlers
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
 	"github.com/flow-hydraulics/flow-wallet-api/transactions"
 	"github.com/gorilla/mux"
+	"github.com/onflow/flow-go-sdk"
 )
 
 func (s *Transactions) ListFunc(rw http.ResponseWriter, r *http.Request) {
@@ -34,6 +38,9 @@ func (s *Transactions) ListFunc(rw http.ResponseWriter, r *http.Request) {
 		// This endpoint is used to handle "raw" transactions for an account
 		// so we use transactions.General type here
 		transactionSlice, err = s.service.ListForAccount(transactions.General, address, limit, offset)
+	} else if eventType := r.FormValue("eventType"); eventType != "" {
+		// Handle transactions which emitted an event of the given type
+		transactionSlice, err = s.service.ListByEventType(eventType, limit, offset)
 	} else {
 		// Handle all transactions
 		transactionSlice, err = s.service.List(limit, offset)
@@ -79,9 +86,65 @@ func (s *Transactions) CreateFunc(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Account specific transactions are proposed by the account,
+	// otherwise the first listed authorizer is the proposer
+	authorizers := txReq.Authorizers
+	if address, ok := vars["address"]; ok {
+		authorizers = append([]string{address}, authorizers...)
+	}
+
 	// Decide whether to serve sync or async, default async
 	sync := r.FormValue(SyncQueryParameter) != ""
-	job, transaction, err := s.service.Create(r.Context(), sync, vars["address"], txReq.Code, txReq.Arguments, transactions.General)
+	job, transaction, err := s.service.CreateWithAuthorizers(r.Context(), sync, authorizers, txReq.Code, txReq.Arguments, transactions.General, transactions.WithComputeLimit(txReq.ComputeLimit))
+
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var res interface{}
+	if sync {
+		res = transaction.ToJSONResponse()
+	} else {
+		res = job.ToJSONResponse()
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
+
+func (s *Transactions) SponsorFunc(rw http.ResponseWriter, r *http.Request) {
+	err := checkNonEmptyBody(r)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var txReq transactions.SponsorJSONRequest
+
+	// Try to decode the request body into the struct.
+	err = json.NewDecoder(r.Body).Decode(&txReq)
+	if err != nil {
+		err = &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid body"),
+		}
+		handleError(rw, r, err)
+		return
+	}
+
+	encoded, err := hex.DecodeString(strings.TrimPrefix(txReq.Transaction, "0x"))
+	if err != nil || len(encoded) == 0 {
+		err = &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("transaction should be a hex encoded RLP"),
+		}
+		handleError(rw, r, err)
+		return
+	}
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+	job, transaction, err := s.service.Sponsor(r.Context(), sync, encoded)
 
 	if err != nil {
 		handleError(rw, r, err)
@@ -120,7 +183,7 @@ func (s *Transactions) SignFunc(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tx, err := s.service.Sign(r.Context(), vars["address"], txReq.Code, txReq.Arguments)
+	tx, err := s.service.Sign(r.Context(), vars["address"], txReq.Code, txReq.Arguments, transactions.WithComputeLimit(txReq.ComputeLimit))
 	if err != nil {
 		handleError(rw, r, err)
 		return
@@ -191,12 +254,114 @@ func (s *Transactions) ExecuteScriptFunc(rw http.ResponseWriter, r *http.Request
 		return
 	}
 
-	res, err := s.service.ExecuteScript(r.Context(), txReq.Code, txReq.Arguments)
+	block, err := scriptBlock(r)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	value, err := s.service.ExecuteScriptAtBlock(r.Context(), txReq.Code, txReq.Arguments, block)
 
 	if err != nil {
 		handleError(rw, r, err)
 		return
 	}
 
+	res, err := transactions.FormatScriptResult(value, r.FormValue(ScriptFormatQueryParameter))
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
 	handleJsonResponse(rw, http.StatusOK, res)
 }
+
+// scriptBlock reads the block a script should be executed at from the
+// request, the latest block by default.
+func scriptBlock(r *http.Request) (transactions.ScriptBlock, error) {
+	var block transactions.ScriptBlock
+
+	height, id := r.FormValue(BlockHeightQueryParameter), r.FormValue(BlockIdQueryParameter)
+
+	if height != "" && id != "" {
+		return block, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("only one of %s and %s can be given", BlockHeightQueryParameter, BlockIdQueryParameter),
+		}
+	}
+
+	if height != "" {
+		h, err := strconv.ParseUint(height, 10, 64)
+		if err != nil || h == 0 {
+			return block, &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("invalid block height: %s", height),
+			}
+		}
+		block.Height = h
+	}
+
+	if id != "" {
+		if err := flow_helpers.ValidateTransactionId(id); err != nil {
+			return block, &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("invalid block ID: %s", id),
+			}
+		}
+		block.ID = flow.HexToID(id)
+	}
+
+	return block, nil
+}
+
+func (s *Transactions) ListAllowedCodesFunc(rw http.ResponseWriter, r *http.Request) {
+	res, err := s.service.ListAllowedCodes()
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+func (s *Transactions) AddAllowedCodeFunc(rw http.ResponseWriter, r *http.Request) {
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var req transactions.AllowedCodeJSONRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(rw, r, InvalidBodyError)
+		return
+	}
+
+	res, err := s.service.AddAllowedCode(req)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
+
+func (s *Transactions) RemoveAllowedCodeFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	id, err := strconv.ParseUint(vars["id"], 10, 64)
+	if err != nil {
+		err = &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid allowed code id: %s", vars["id"]),
+		}
+		handleError(rw, r, err)
+		return
+	}
+
+	if err := s.service.RemoveAllowedCode(id); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, id)
+}
@@ -1,6 +1,7 @@
 package jobs
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -25,6 +26,9 @@ const (
 	Error              State = "ERROR"
 	Complete           State = "COMPLETE"
 	Failed             State = "FAILED"
+	// AwaitingResult is the state of a job whose transaction has been sent
+	// and is waiting to be resolved by the seal tracker.
+	AwaitingResult State = "AWAITING_RESULT"
 )
 
 // Job database model
@@ -35,6 +39,7 @@ type Job struct {
 	Error                  string         `gorm:"column:error"`
 	Errors                 pq.StringArray `gorm:"column:errors;type:text[]"`
 	Result                 string         `gorm:"column:result"`
+	Metadata               datatypes.JSON `gorm:"column:metadata"` // Metadata of the result, e.g. labels of a created account
 	TransactionID          string         `gorm:"column:transaction_id"`
 	ExecCount              int            `gorm:"column:exec_count;default:0"`
 	CreatedAt              time.Time      `gorm:"column:created_at"`
@@ -55,19 +60,21 @@ type JobQueueStatus struct {
 	JobsErrored     int `json:"jobsErrored"`
 	JobsFailed      int `json:"jobsFailed"`
 	JobsCompleted   int `json:"jobsCompleted"`
+	JobsAwaiting    int `json:"jobsAwaitingResult"`
 }
 
 // Job HTTP response
 type JSONResponse struct {
-	ID            uuid.UUID `json:"jobId"`
-	Type          string    `json:"type"`
-	State         State     `json:"state"`
-	Error         string    `json:"error"`
-	Errors        []string  `json:"errors"`
-	Result        string    `json:"result"`
-	TransactionID string    `json:"transactionId"`
-	CreatedAt     time.Time `json:"createdAt"`
-	UpdatedAt     time.Time `json:"updatedAt"`
+	ID            uuid.UUID       `json:"jobId"`
+	Type          string          `json:"type"`
+	State         State           `json:"state"`
+	Error         string          `json:"error"`
+	Errors        []string        `json:"errors"`
+	Result        string          `json:"result"`
+	Metadata      json.RawMessage `json:"metadata,omitempty"`
+	TransactionID string          `json:"transactionId"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	UpdatedAt     time.Time       `json:"updatedAt"`
 }
 
 func (j Job) ToJSONResponse() JSONResponse {
@@ -78,12 +85,21 @@ func (j Job) ToJSONResponse() JSONResponse {
 		Error:         j.Error,
 		Errors:        []string(j.Errors),
 		Result:        j.Result,
+		Metadata:      j.metadata(),
 		TransactionID: j.TransactionID,
 		CreatedAt:     j.CreatedAt,
 		UpdatedAt:     j.UpdatedAt,
 	}
 }
 
+// metadata returns the metadata of the job, or nil if it has none.
+func (j Job) metadata() json.RawMessage {
+	if len(j.Metadata) == 0 || string(j.Metadata) == "null" {
+		return nil
+	}
+	return json.RawMessage(j.Metadata)
+}
+
 func (j *Job) BeforeCreate(tx *gorm.DB) (err error) {
 	j.ID = uuid.New()
 	return nil
@@ -17,7 +17,9 @@ import (
 	"github.com/google/uuid"
 )
 
-type dummyStore struct{}
+type dummyStore struct {
+	awaiting []Job
+}
 
 func (*dummyStore) Jobs(datastore.ListOptions) ([]Job, error) { return nil, nil }
 func (*dummyStore) Job(id uuid.UUID) (Job, error)             { return Job{}, nil }
@@ -31,6 +33,9 @@ func (*dummyStore) SchedulableJobs(acceptedGracePeriod, reSchedulableGracePeriod
 	return nil, nil
 }
 func (*dummyStore) Status() ([]StatusQuery, error) { return nil, nil }
+func (s *dummyStore) AwaitingResultJobs(transactionID string) ([]Job, error) {
+	return s.awaiting, nil
+}
 
 func TestScheduleSendNotification(t *testing.T) {
 	logger, hook := test.NewNullLogger()
@@ -92,6 +97,118 @@ func TestScheduleSendNotification(t *testing.T) {
 	}
 }
 
+func TestAwaitingResultJob(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+
+	store := &dummyStore{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wp := WorkerPoolImpl{
+		context:       ctx,
+		cancelContext: cancel,
+		executors:     make(map[string]ExecutorFunc),
+		jobChan:       make(chan *Job, 1),
+		store:         store,
+	}
+
+	WithJobStatusWebhook("http://localhost", time.Minute)(&wp)
+	WithLogger(logger)(&wp)
+
+	wp.RegisterExecutor("TestJobType", func(ctx context.Context, j *Job) error {
+		j.ShouldSendNotification = true
+		j.State = AwaitingResult
+		return nil
+	})
+
+	job, err := wp.CreateJob("TestJobType", "0x01")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := wp.process(job); err != nil {
+		t.Fatal(err)
+	}
+
+	if job.State != AwaitingResult {
+		t.Fatalf("expected job state %s, got %s", AwaitingResult, job.State)
+	}
+
+	if len(wp.jobChan) != 0 {
+		t.Fatal("did not expect a notification before the job is resolved")
+	}
+
+	store.awaiting = []Job{*job}
+
+	if err := wp.ResolveJobs("0x01", fmt.Errorf("transaction expired")); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(wp.jobChan) == 0 {
+		t.Fatal("expected job channel to contain a notification job")
+	}
+
+	notification := <-wp.jobChan
+
+	var res JSONResponse
+	if err := json.Unmarshal([]byte(notification.Result), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	if res.State != Failed || res.Error != "transaction expired" {
+		t.Fatalf("unexpected resolved job: %+v", res)
+	}
+}
+
+func TestAwaitingResultJobRetry(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+
+	job := Job{ID: uuid.New(), State: AwaitingResult, ExecCount: 1, TransactionID: "0x01"}
+	store := &dummyStore{awaiting: []Job{job}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wp := WorkerPoolImpl{
+		context:          ctx,
+		cancelContext:    cancel,
+		executors:        make(map[string]ExecutorFunc),
+		jobChan:          make(chan *Job, 1),
+		store:            store,
+		maxJobErrorCount: 5,
+	}
+
+	WithJobStatusWebhook("http://localhost", time.Minute)(&wp)
+	WithLogger(logger)(&wp)
+
+	t.Run("retryable error returns job to the pool", func(t *testing.T) {
+		if err := wp.ResolveJobs("0x01", fmt.Errorf("transaction expired")); err != nil {
+			t.Fatal(err)
+		}
+
+		if store.awaiting[0].State != Error {
+			t.Fatalf("expected job state %s, got %s", Error, store.awaiting[0].State)
+		}
+
+		if len(wp.jobChan) != 0 {
+			t.Fatal("did not expect a notification for a job to be retried")
+		}
+	})
+
+	t.Run("permanent failure fails job", func(t *testing.T) {
+		store.awaiting[0].State = AwaitingResult
+
+		if err := wp.ResolveJobs("0x01", PermanentFailure(fmt.Errorf("execution failed"))); err != nil {
+			t.Fatal(err)
+		}
+
+		if store.awaiting[0].State != Failed {
+			t.Fatalf("expected job state %s, got %s", Failed, store.awaiting[0].State)
+		}
+
+		if len(wp.jobChan) == 0 {
+			t.Fatal("expected job channel to contain a notification job")
+		}
+	})
+}
+
 func TestExecuteSendNotification(t *testing.T) {
 	t.Run("valid job should send", func(t *testing.T) {
 		var webhookJob Job
@@ -16,6 +16,7 @@ type Store interface {
 	AcceptJob(j *Job, acceptedGracePeriod time.Duration) error
 	SchedulableJobs(acceptedGracePeriod, reSchedulableGracePeriod time.Duration, o datastore.ListOptions) ([]Job, error)
 	Status() ([]StatusQuery, error)
+	AwaitingResultJobs(transactionID string) ([]Job, error)
 }
 
 type StatusQuery struct {
@@ -46,7 +46,7 @@ func isAcceptable(j *Job, acceptedGracePeriod time.Duration) bool {
 	if j.State == Accepted && j.UpdatedAt.After(tAccepted) {
 		return false
 	}
-	if j.State == Complete || j.State == Failed {
+	if j.State == Complete || j.State == Failed || j.State == AwaitingResult {
 		return false
 	}
 	return true
@@ -100,3 +100,10 @@ func (s *GormStore) Status() ([]StatusQuery, error) {
 	}
 	return res, nil
 }
+
+func (s *GormStore) AwaitingResultJobs(transactionID string) (jj []Job, err error) {
+	err = s.db.
+		Where("state = ? AND transaction_id = ?", AwaitingResult, transactionID).
+		Find(&jj).Error
+	return
+}
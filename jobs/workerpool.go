@@ -44,6 +44,13 @@ type WorkerPool interface {
 	RegisterExecutor(jobType string, executorF ExecutorFunc)
 	CreateJob(jobType, txID string, opts ...JobOption) (*Job, error)
 	Schedule(j *Job) error
+	ResolveJobs(transactionID string, jobErr error) error
+	// ScheduleNotification schedules content to be sent to the job status
+	// webhook. It does nothing if no webhook is configured.
+	ScheduleNotification(content interface{}) error
+	// SaveProgress stores the intermediate state of a job being executed,
+	// e.g. the progress of a long-running job.
+	SaveProgress(j *Job) error
 	Status() (WorkerPoolStatus, error)
 	Start()
 	Stop(wait bool)
@@ -139,6 +146,8 @@ func (wp *WorkerPoolImpl) Status() (WorkerPoolStatus, error) {
 			status.JobsFailed = r.Count
 		case Complete:
 			status.JobsCompleted = r.Count
+		case AwaitingResult:
+			status.JobsAwaiting = r.Count
 		default:
 			continue
 		}
@@ -172,6 +181,12 @@ func (wp *WorkerPoolImpl) CreateJob(jobType, txID string, opts ...JobOption) (*J
 	return job, nil
 }
 
+func (wp *WorkerPoolImpl) SaveProgress(j *Job) error {
+	// Saving also refreshes the update time of the job, which keeps a long
+	// running job from being rescheduled after the accepted grace period.
+	return wp.store.UpdateJob(j)
+}
+
 func (wp *WorkerPoolImpl) RegisterExecutor(jobType string, executorF ExecutorFunc) {
 	wp.executors[jobType] = executorF
 }
@@ -406,7 +421,10 @@ func (wp *WorkerPoolImpl) process(job *Job) error {
 			Warn("Job execution resulted with error")
 
 	} else {
-		job.State = Complete
+		// Executor may leave the job waiting for the result of its transaction
+		if job.State != AwaitingResult {
+			job.State = Complete
+		}
 		job.Error = "" // Clear the error message for the final & successful execution
 	}
 
@@ -425,6 +443,59 @@ func (wp *WorkerPoolImpl) process(job *Job) error {
 	return nil
 }
 
+// ResolveJobs resolves the jobs awaiting the result of the given transaction.
+// Jobs are completed if jobErr is nil. Otherwise they are retried like jobs
+// whose execution failed, unless jobErr is a permanent failure or the jobs
+// have run out of retries. Job status notifications are sent for completed
+// and failed jobs.
+func (wp *WorkerPoolImpl) ResolveJobs(transactionID string, jobErr error) error {
+	jobs, err := wp.store.AwaitingResultJobs(transactionID)
+	if err != nil {
+		return err
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+
+		entry := job.logEntry(wp.logger.WithFields(log.Fields{
+			"package":  "jobs",
+			"function": "WorkerPool.ResolveJobs",
+		}))
+
+		if jobErr != nil {
+			if job.ExecCount > wp.maxJobErrorCount || errors.Is(jobErr, ErrPermanentFailure) {
+				job.State = Failed
+			} else {
+				// Return the job to the pool to be retried
+				job.State = Error
+			}
+			job.Error = jobErr.Error()
+			job.Errors = append(job.Errors, jobErr.Error())
+
+			entry.
+				WithFields(log.Fields{"error": jobErr}).
+				Warn("Job transaction resulted with error")
+		} else {
+			job.State = Complete
+			job.Error = ""
+		}
+
+		if err := wp.store.UpdateJob(job); err != nil {
+			return fmt.Errorf("error while updating database entry: %w", err)
+		}
+
+		if (job.State == Failed || job.State == Complete) && wp.notificationConfig.ShouldSendJobStatus() {
+			if err := wp.scheduleJobStatusNotification(job); err != nil {
+				entry.
+					WithFields(log.Fields{"error": err}).
+					Warn("Could not schedule a status update notification for job")
+			}
+		}
+	}
+
+	return nil
+}
+
 func (wp *WorkerPoolImpl) executeSendJobStatus(ctx context.Context, j *Job) error {
 	if j.Type != SendJobStatusJobType {
 		return ErrInvalidJobType
@@ -447,17 +518,25 @@ func (wp *WorkerPoolImpl) scheduleJobStatusNotification(parent *Job) error {
 
 	entry.Debug("Scheduling job status notification")
 
+	return wp.ScheduleNotification(parent.ToJSONResponse())
+}
+
+func (wp *WorkerPoolImpl) ScheduleNotification(content interface{}) error {
+	if !wp.notificationConfig.ShouldSendJobStatus() {
+		return nil
+	}
+
 	job, err := wp.CreateJob(SendJobStatusJobType, "")
 	if err != nil {
 		return err
 	}
 
-	b, err := json.Marshal(parent.ToJSONResponse())
+	b, err := json.Marshal(content)
 	if err != nil {
 		return err
 	}
 
-	// Store the notification content of the parent job in Result of the new job
+	// Store the notification content in Result of the new job
 	job.Result = string(b)
 
 	if err := wp.store.UpdateJob(job); err != nil {
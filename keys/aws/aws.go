@@ -57,17 +57,66 @@ func Generate(cfg *configs.Config, ctx context.Context, keyIndex, weight int) (*
 	}
 
 	// Get the public key from AWS KMS
-	pbkOutput, err := client.GetPublicKey(ctx, &kms.GetPublicKeyInput{KeyId: createKeyOutput.KeyMetadata.KeyId})
+	pbk, signAlgo, hashAlgo, err := getPublicKey(ctx, client, createKeyOutput.KeyMetadata.KeyId)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	f := flow.NewAccountKey().
+		SetPublicKey(pbk).
+		SetHashAlgo(hashAlgo).
+		SetWeight(weight)
+	f.Index = keyIndex
+
+	pk := &keys.Private{
+		Index:    keyIndex,
+		Type:     keys.AccountKeyTypeAWSKMS,
+		Value:    *createKeyOutput.KeyMetadata.Arn,
+		SignAlgo: signAlgo,
+		HashAlgo: hashAlgo,
+		Weight:   weight,
+	}
+
+	return f, pk, nil
+}
+
+// Import resolves the public key of an existing asymmetric signing key in
+// AWS KMS and returns it with an "in flight" private key (AWS KMS key ARN)
+func Import(ctx context.Context, keyArn string) (crypto.PublicKey, *keys.Private, error) {
+	if !arn.IsARN(keyArn) {
+		return nil, nil, fmt.Errorf("not a valid AWS KMS key ARN")
+	}
+
+	client := createKMSClient(ctx)
+
+	pbk, signAlgo, hashAlgo, err := getPublicKey(ctx, client, aws.String(keyArn))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pk := &keys.Private{
+		Type:     keys.AccountKeyTypeAWSKMS,
+		Value:    keyArn,
+		SignAlgo: signAlgo,
+		HashAlgo: hashAlgo,
+	}
+
+	return pbk, pk, nil
+}
+
+func getPublicKey(ctx context.Context, client *kms.Client, keyId *string) (crypto.PublicKey, crypto.SignatureAlgorithm, crypto.HashAlgorithm, error) {
+	// Get the public key from AWS KMS
+	pbkOutput, err := client.GetPublicKey(ctx, &kms.GetPublicKeyInput{KeyId: keyId})
+	if err != nil {
+		return nil, crypto.UnknownSignatureAlgorithm, crypto.UnknownHashAlgorithm, err
+	}
+
 	var dest kmsPubKey
 
 	// Decode the public key
 	_, err = asn1.Unmarshal(pbkOutput.PublicKey, &dest)
 	if err != nil {
-		return nil, nil, err
+		return nil, crypto.UnknownSignatureAlgorithm, crypto.UnknownHashAlgorithm, err
 	}
 
 	// Parse signature & hash algorithm
@@ -79,24 +128,10 @@ func Generate(cfg *configs.Config, ctx context.Context, keyIndex, weight int) (*
 	pemStr := string(pem.EncodeToMemory(&pem.Block{Bytes: pbkOutput.PublicKey})[:])
 	pbk, err := crypto.DecodePublicKeyPEM(signAlgo, pemStr)
 	if err != nil {
-		return nil, nil, err
+		return nil, crypto.UnknownSignatureAlgorithm, crypto.UnknownHashAlgorithm, err
 	}
 
-	f := flow.NewAccountKey().
-		SetPublicKey(pbk).
-		SetHashAlgo(hashAlgo).
-		SetWeight(weight)
-	f.Index = keyIndex
-
-	pk := &keys.Private{
-		Index:    keyIndex,
-		Type:     keys.AccountKeyTypeAWSKMS,
-		Value:    *createKeyOutput.KeyMetadata.Arn,
-		SignAlgo: signAlgo,
-		HashAlgo: hashAlgo,
-	}
-
-	return f, pk, nil
+	return pbk, signAlgo, hashAlgo, nil
 }
 
 // Reference: https://docs.aws.amazon.com/kms/latest/developerguide/symm-asymm-choose.html#key-spec-ecc
@@ -4,6 +4,8 @@ package basic
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -24,6 +26,15 @@ type KeyManager struct {
 	crypter         encryption.Crypter
 	adminAccountKey keys.Private
 	cfg             *configs.Config
+
+	accountCache      map[flow.Address]cachedAccount
+	accountCacheMutex sync.Mutex
+}
+
+// cachedAccount holds the on-chain keys of an account fetched at fetchedAt.
+type cachedAccount struct {
+	keys      []*flow.AccountKey
+	fetchedAt time.Time
 }
 
 // NewKeyManager initiates a new key manager.
@@ -54,11 +65,12 @@ func NewKeyManager(cfg *configs.Config, store keys.Store, fc flow_helpers.FlowCl
 	}
 
 	return &KeyManager{
-		store,
-		fc,
-		crypter,
-		adminAccountKey,
-		cfg,
+		store:           store,
+		fc:              fc,
+		crypter:         crypter,
+		adminAccountKey: adminAccountKey,
+		cfg:             cfg,
+		accountCache:    map[flow.Address]cachedAccount{},
 	}
 }
 
@@ -101,9 +113,13 @@ func (s *KeyManager) CheckAdminProposalKeyCount(ctx context.Context) error {
 }
 
 func (s *KeyManager) Generate(ctx context.Context, keyIndex, weight int) (*flow.AccountKey, *keys.Private, error) {
-	switch s.cfg.DefaultKeyType {
+	return s.GenerateOfType(ctx, s.cfg.DefaultKeyType, keyIndex, weight)
+}
+
+func (s *KeyManager) GenerateOfType(ctx context.Context, keyType string, keyIndex, weight int) (*flow.AccountKey, *keys.Private, error) {
+	switch keyType {
 	default:
-		return nil, nil, fmt.Errorf("keyStore.Generate() not implmented for %s", s.cfg.DefaultKeyType)
+		return nil, nil, fmt.Errorf("keyStore.Generate() not implmented for %s", keyType)
 	case keys.AccountKeyTypeLocal:
 		return local.Generate(
 			keyIndex, weight,
@@ -120,6 +136,22 @@ func (s *KeyManager) GenerateDefault(ctx context.Context) (*flow.AccountKey, *ke
 	return s.Generate(ctx, s.cfg.DefaultKeyIndex, s.cfg.DefaultKeyWeight)
 }
 
+func (s *KeyManager) Import(ctx context.Context, keyType, value string, signAlgo crypto.SignatureAlgorithm) (crypto.PublicKey, *keys.Private, error) {
+	switch keyType {
+	default:
+		return nil, nil, fmt.Errorf("keyStore.Import() not implmented for %s", keyType)
+	case keys.AccountKeyTypeLocal:
+		if signAlgo == crypto.UnknownSignatureAlgorithm {
+			signAlgo = crypto.StringToSignatureAlgorithm(s.cfg.DefaultSignAlgo)
+		}
+		return local.Import(value, signAlgo, crypto.StringToHashAlgorithm(s.cfg.DefaultHashAlgo))
+	case keys.AccountKeyTypeGoogleKMS:
+		return google.Import(ctx, value)
+	case keys.AccountKeyTypeAWSKMS:
+		return aws.Import(ctx, value)
+	}
+}
+
 func (s *KeyManager) Save(key keys.Private) (keys.Storable, error) {
 	encValue, err := s.crypter.Encrypt([]byte(key.Value))
 	if err != nil {
@@ -131,6 +163,7 @@ func (s *KeyManager) Save(key keys.Private) (keys.Storable, error) {
 		Value:    encValue,
 		SignAlgo: key.SignAlgo.String(),
 		HashAlgo: key.HashAlgo.String(),
+		Weight:   key.Weight,
 	}, nil
 }
 
@@ -145,18 +178,118 @@ func (s *KeyManager) Load(key keys.Storable) (keys.Private, error) {
 		Value:    string(decValue),
 		SignAlgo: crypto.StringToSignatureAlgorithm(key.SignAlgo),
 		HashAlgo: crypto.StringToHashAlgorithm(key.HashAlgo),
+		Weight:   key.Weight,
 	}, nil
 }
 
 func (s *KeyManager) AdminAuthorizer(ctx context.Context) (keys.Authorizer, error) {
-	return s.MakeAuthorizer(ctx, flow.HexToAddress(s.cfg.AdminAddress))
+	return s.makeAuthorizer(ctx, flow.HexToAddress(s.cfg.AdminAddress), false)
+}
+
+func (s *KeyManager) PayerAuthorizer(ctx context.Context, address flow.Address) (keys.Authorizer, error) {
+	return s.makeAuthorizer(ctx, address, false)
 }
 
 func (s *KeyManager) UserAuthorizer(ctx context.Context, address flow.Address) (keys.Authorizer, error) {
 	return s.MakeAuthorizer(ctx, address)
 }
 
+// UserAuthorizers returns an Authorizer for the "least recently used" key of
+// each key set of the given address until the combined on-chain weight of
+// the keys meets flow.AccountKeyWeightThreshold.
+func (s *KeyManager) UserAuthorizers(ctx context.Context, address flow.Address) ([]keys.Authorizer, error) {
+	return s.userAuthorizers(ctx, address, true)
+}
+
+// UserSigners works like UserAuthorizers but the keys are only used to sign,
+// so no proposal key sequence number is allocated.
+func (s *KeyManager) UserSigners(ctx context.Context, address flow.Address) ([]keys.Authorizer, error) {
+	return s.userAuthorizers(ctx, address, false)
+}
+
+func (s *KeyManager) userAuthorizers(ctx context.Context, address flow.Address, proposer bool) ([]keys.Authorizer, error) {
+	if address == flow.HexToAddress(s.cfg.AdminAddress) {
+		a, err := s.makeAuthorizer(ctx, address, proposer)
+		if err != nil {
+			return nil, err
+		}
+		return []keys.Authorizer{a}, nil
+	}
+
+	sks, err := s.store.AccountKeySet(flow_helpers.FormatAddress(address))
+	if err != nil {
+		return nil, err
+	}
+
+	// With sequence number tracking the on-chain keys are read from cache
+	// and the sequence number of the proposal key is allocated locally
+	accountKeys, err := s.onChainKeys(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	authorizers := []keys.Authorizer{}
+	weight := 0
+
+	for _, sk := range sks {
+		if sk.Index < 0 || sk.Index >= len(accountKeys) {
+			continue
+		}
+
+		if accountKeys[sk.Index].Revoked {
+			continue
+		}
+
+		// Copy the key so the cached key is never modified
+		accountKey := *accountKeys[sk.Index]
+
+		k, err := s.Load(sk)
+		if err != nil {
+			return nil, err
+		}
+
+		sig, err := signerForKey(ctx, address, k)
+		if err != nil {
+			return nil, err
+		}
+
+		authorizers = append(authorizers, keys.Authorizer{
+			Address: address,
+			Key:     &accountKey,
+			Signer:  sig,
+		})
+
+		weight += accountKey.Weight
+		if weight >= flow.AccountKeyWeightThreshold {
+			if s.cfg.SequenceNumberTracking && proposer {
+				// First authorizer acts as the proposer
+				n, err := s.nextSequenceNumber(ctx, address, authorizers[0].Key.Index)
+				if err != nil {
+					return nil, err
+				}
+				authorizers[0].Key.SequenceNumber = n
+			}
+
+			return authorizers, nil
+		}
+	}
+
+	return nil, fmt.Errorf(
+		"address: %s, weight: %d, threshold: %d, %w",
+		address,
+		weight,
+		flow.AccountKeyWeightThreshold,
+		keys.ErrInsufficientKeyWeight,
+	)
+}
+
+// MakeAuthorizer returns an Authorizer for the "least recently used" key of
+// the given address to be used as proposer.
 func (s *KeyManager) MakeAuthorizer(ctx context.Context, address flow.Address) (keys.Authorizer, error) {
+	return s.makeAuthorizer(ctx, address, true)
+}
+
+func (s *KeyManager) makeAuthorizer(ctx context.Context, address flow.Address, proposer bool) (keys.Authorizer, error) {
 	var k keys.Private
 
 	if address == flow.HexToAddress(s.cfg.AdminAddress) {
@@ -173,19 +306,19 @@ func (s *KeyManager) MakeAuthorizer(ctx context.Context, address flow.Address) (
 		}
 	}
 
-	acc, err := s.fc.GetAccount(ctx, address)
+	sig, err := signerForKey(ctx, address, k)
 	if err != nil {
 		return keys.Authorizer{}, err
 	}
 
-	sig, err := signerForKey(ctx, address, k)
+	accountKey, err := s.accountKey(ctx, address, k.Index, k.Weight, proposer)
 	if err != nil {
 		return keys.Authorizer{}, err
 	}
 
 	return keys.Authorizer{
 		Address: address,
-		Key:     acc.Keys[k.Index],
+		Key:     accountKey,
 		Signer:  sig,
 	}, nil
 }
@@ -198,12 +331,12 @@ func (s *KeyManager) AdminProposalKey(ctx context.Context) (keys.Authorizer, err
 		return keys.Authorizer{}, fmt.Errorf("unable to get admin proposal key: %w", err)
 	}
 
-	acc, err := s.fc.GetAccount(ctx, adminAcc)
+	sig, err := signerForKey(ctx, adminAcc, s.adminAccountKey)
 	if err != nil {
 		return keys.Authorizer{}, err
 	}
 
-	sig, err := signerForKey(ctx, adminAcc, s.adminAccountKey)
+	accountKey, err := s.accountKey(ctx, adminAcc, index, s.adminAccountKey.Weight, true)
 	if err != nil {
 		return keys.Authorizer{}, err
 	}
@@ -215,11 +348,167 @@ func (s *KeyManager) AdminProposalKey(ctx context.Context) (keys.Authorizer, err
 
 	return keys.Authorizer{
 		Address: adminAcc,
-		Key:     acc.Keys[index],
+		Key:     accountKey,
 		Signer:  sig,
 	}, nil
 }
 
+// ExternalProposerSet returns the Authorizers needed to propose a transaction
+// by the given address which is signed but not sent by the service. The
+// proposal key carries its on-chain sequence number.
+func (s *KeyManager) ExternalProposerSet(ctx context.Context, address flow.Address) ([]keys.Authorizer, error) {
+	var authorizers []keys.Authorizer
+
+	if address == flow.HexToAddress(s.cfg.AdminAddress) {
+		index, err := s.store.ProposalKeyIndex(int(s.cfg.AdminProposalKeyCount))
+		if err != nil {
+			return nil, fmt.Errorf("unable to get admin proposal key: %w", err)
+		}
+
+		sig, err := signerForKey(ctx, address, s.adminAccountKey)
+		if err != nil {
+			return nil, err
+		}
+
+		authorizers = []keys.Authorizer{{
+			Address: address,
+			Key:     &flow.AccountKey{Index: index, Weight: s.adminAccountKey.Weight},
+			Signer:  sig,
+		}}
+	} else {
+		var err error
+		authorizers, err = s.userAuthorizers(ctx, address, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	n, err := s.onChainSequenceNumber(ctx, address, authorizers[0].Key.Index)
+	if err != nil {
+		return nil, err
+	}
+	authorizers[0].Key.SequenceNumber = n
+
+	return authorizers, nil
+}
+
+func (s *KeyManager) ReleaseSequenceNumber(address flow.Address, keyIndex int, sequenceNumber uint64) error {
+	if !s.cfg.SequenceNumberTracking {
+		return nil
+	}
+
+	log.WithFields(log.Fields{
+		"address":        flow_helpers.FormatAddress(address),
+		"keyIndex":       keyIndex,
+		"sequenceNumber": sequenceNumber,
+	}).Debug("Releasing proposal key sequence number")
+
+	return s.store.ReleaseSequenceNumber(flow_helpers.FormatAddress(address), keyIndex, sequenceNumber)
+}
+
+func (s *KeyManager) ResyncSequenceNumber(ctx context.Context, address flow.Address, keyIndex int, sequenceNumber uint64) error {
+	if !s.cfg.SequenceNumberTracking {
+		return nil
+	}
+
+	log.WithFields(log.Fields{
+		"address":        flow_helpers.FormatAddress(address),
+		"keyIndex":       keyIndex,
+		"sequenceNumber": sequenceNumber,
+	}).Debug("Resyncing proposal key sequence number")
+
+	s.accountCacheMutex.Lock()
+	delete(s.accountCache, address)
+	s.accountCacheMutex.Unlock()
+
+	return s.store.ResyncSequenceNumber(flow_helpers.FormatAddress(address), keyIndex, sequenceNumber, func() (uint64, error) {
+		return s.onChainSequenceNumber(ctx, address, keyIndex)
+	})
+}
+
+// onChainKeys returns the on-chain keys of the given account. With sequence
+// number tracking the keys are cached for cfg.AccountKeyCacheTTL, otherwise
+// the account is fetched from chain.
+func (s *KeyManager) onChainKeys(ctx context.Context, address flow.Address) ([]*flow.AccountKey, error) {
+	if !s.cfg.SequenceNumberTracking {
+		acc, err := s.fc.GetAccount(ctx, address)
+		if err != nil {
+			return nil, err
+		}
+		return acc.Keys, nil
+	}
+
+	s.accountCacheMutex.Lock()
+	cached, ok := s.accountCache[address]
+	s.accountCacheMutex.Unlock()
+
+	if ok && time.Since(cached.fetchedAt) < s.cfg.AccountKeyCacheTTL {
+		return cached.keys, nil
+	}
+
+	acc, err := s.fc.GetAccount(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	s.accountCacheMutex.Lock()
+	s.accountCache[address] = cachedAccount{keys: acc.Keys, fetchedAt: time.Now()}
+	s.accountCacheMutex.Unlock()
+
+	return acc.Keys, nil
+}
+
+// accountKey returns the account key of address at keyIndex. Without sequence
+// number tracking the key is fetched from chain. Otherwise the key is built
+// from local data and if it is to be used as proposal key, its sequence
+// number is allocated from the locally tracked sequence numbers.
+func (s *KeyManager) accountKey(ctx context.Context, address flow.Address, keyIndex, weight int, proposer bool) (*flow.AccountKey, error) {
+	if !s.cfg.SequenceNumberTracking {
+		acc, err := s.fc.GetAccount(ctx, address)
+		if err != nil {
+			return nil, err
+		}
+		if keyIndex < 0 || keyIndex >= len(acc.Keys) {
+			return nil, fmt.Errorf("key index %d not found on account %s", keyIndex, address)
+		}
+		return acc.Keys[keyIndex], nil
+	}
+
+	accountKey := &flow.AccountKey{Index: keyIndex, Weight: weight}
+
+	if proposer {
+		n, err := s.nextSequenceNumber(ctx, address, keyIndex)
+		if err != nil {
+			return nil, err
+		}
+		accountKey.SequenceNumber = n
+	}
+
+	return accountKey, nil
+}
+
+// nextSequenceNumber allocates the next proposal key sequence number of the
+// given account key. The account is fetched from chain only if the key is not
+// yet tracked.
+func (s *KeyManager) nextSequenceNumber(ctx context.Context, address flow.Address, keyIndex int) (uint64, error) {
+	return s.store.NextSequenceNumber(flow_helpers.FormatAddress(address), keyIndex, func() (uint64, error) {
+		return s.onChainSequenceNumber(ctx, address, keyIndex)
+	})
+}
+
+// onChainSequenceNumber fetches the current sequence number of the given
+// account key from chain.
+func (s *KeyManager) onChainSequenceNumber(ctx context.Context, address flow.Address, keyIndex int) (uint64, error) {
+	acc, err := s.fc.GetAccount(ctx, address)
+	if err != nil {
+		return 0, err
+	}
+	if keyIndex < 0 || keyIndex >= len(acc.Keys) {
+		return 0, fmt.Errorf("key index %d not found on account %s", keyIndex, address)
+	}
+	return acc.Keys[keyIndex].SequenceNumber, nil
+}
+
 func signerForKey(ctx context.Context, address flow.Address, k keys.Private) (crypto.Signer, error) {
 	var (
 		sig crypto.Signer
@@ -105,11 +105,40 @@ func Generate(cfg *configs.Config, ctx context.Context, keyIndex, weight int) (*
 		Value:    k.ResourceID(),
 		SignAlgo: *s,
 		HashAlgo: *h,
+		Weight:   weight,
 	}
 
 	return f, p, nil
 }
 
+// Import resolves the public key of an existing asymmetric signing key in
+// Google KMS and returns it with an "in flight" private key (KMS key resource name)
+func Import(ctx context.Context, resourceID string) (crypto.PublicKey, *keys.Private, error) {
+	k, err := cloudkms.KeyFromResourceID(resourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c, err := cloudkms.NewClient(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pub, h, s, err := getPublicKey(ctx, c, &k)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := &keys.Private{
+		Type:     keys.AccountKeyTypeGoogleKMS,
+		Value:    k.ResourceID(),
+		SignAlgo: *s,
+		HashAlgo: *h,
+	}
+
+	return *pub, p, nil
+}
+
 // Signer creates a crypto.Signer for the given private key
 // (KMS key resource name)
 func Signer(ctx context.Context, key keys.Private) (crypto.Signer, error) {
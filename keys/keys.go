@@ -4,10 +4,13 @@ package keys
 import (
 	"context"
 	"errors"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/crypto"
+	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
@@ -17,7 +20,62 @@ const (
 	AccountKeyTypeAWSKMS    = "aws_kms"
 )
 
+// SequenceNumberAllocationTTL is how long an allocated sequence number is
+// considered to be in flight. Transactions expire 600 blocks after their
+// reference block, roughly in 10 minutes.
+const SequenceNumberAllocationTTL = 15 * time.Minute
+
 var ErrAdminProposalKeyCountMismatch = errors.New("admin-proposal-key count mismatch")
+var ErrInsufficientKeyWeight = errors.New("insufficient key weight")
+
+// IsSequenceNumberError returns true if the given error was caused by an
+// invalid proposal key sequence number.
+func IsSequenceNumberError(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "sequence number")
+}
+
+// IsInsufficientKeyWeightError returns true if the given error was caused by
+// an account not having enough stored key weight to sign on its own.
+func IsInsufficientKeyWeightError(err error) bool {
+	return errors.Is(err, ErrInsufficientKeyWeight)
+}
+
+// ResyncOnSequenceNumberError resyncs the proposal key sequence number of the
+// given transaction if err was caused by an invalid sequence number.
+func ResyncOnSequenceNumberError(ctx context.Context, km Manager, tx *flow.Transaction, err error) {
+	if !IsSequenceNumberError(err) {
+		return
+	}
+
+	pk := tx.ProposalKey
+	if rerr := km.ResyncSequenceNumber(ctx, pk.Address, pk.KeyIndex, pk.SequenceNumber); rerr != nil {
+		log.
+			WithFields(log.Fields{"address": pk.Address, "keyIndex": pk.KeyIndex, "error": rerr}).
+			Warn("Unable to resync proposal key sequence number")
+	}
+}
+
+// ReleaseProposalKey releases the proposal key sequence number of the given
+// transaction. It should be called whenever a transaction fails after its
+// sequence number has been allocated but before it reached the network, or
+// expires, so the allocated sequence number is not left unused.
+func ReleaseProposalKey(km Manager, tx *flow.Transaction) {
+	pk := tx.ProposalKey
+	if rerr := km.ReleaseSequenceNumber(pk.Address, pk.KeyIndex, pk.SequenceNumber); rerr != nil {
+		log.
+			WithFields(log.Fields{"address": pk.Address, "keyIndex": pk.KeyIndex, "error": rerr}).
+			Warn("Unable to release proposal key sequence number")
+	}
+}
+
+// IsValidKeyType returns true if the given key type is supported by key management.
+func IsValidKeyType(keyType string) bool {
+	switch keyType {
+	case AccountKeyTypeLocal, AccountKeyTypeGoogleKMS, AccountKeyTypeAWSKMS:
+		return true
+	}
+	return false
+}
 
 // Manager provides the functions needed for key management.
 type Manager interface {
@@ -25,14 +83,35 @@ type Manager interface {
 	Generate(ctx context.Context, keyIndex, weight int) (*flow.AccountKey, *Private, error)
 	// GenerateDefault generates a new Key using application defaults.
 	GenerateDefault(context.Context) (*flow.AccountKey, *Private, error)
+	// GenerateOfType generates a new Key of the given key type (e.g. local or aws_kms)
+	// using provided key index and weight.
+	GenerateOfType(ctx context.Context, keyType string, keyIndex, weight int) (*flow.AccountKey, *Private, error)
+	// Import resolves the public key of an existing key of the given key type.
+	// Value should be the hex encoded private key for local keys or the key
+	// resource id (or ARN) for keys in a remote key management system.
+	Import(ctx context.Context, keyType, value string, signAlgo crypto.SignatureAlgorithm) (crypto.PublicKey, *Private, error)
 	// Save is responsible for converting an "in flight" key to a storable key.
 	Save(Private) (Storable, error)
 	// Load is responsible for converting a storable key to an "in flight" key.
 	Load(Storable) (Private, error)
 	// AdminAuthorizer returns an Authorizer for the applications admin account.
+	// When sequence number tracking is enabled the returned key carries no
+	// sequence number and should only be used to sign as payer.
 	AdminAuthorizer(context.Context) (Authorizer, error)
-	// UserAuthorizer returns an Authorizer for the given address.
+	// PayerAuthorizer returns an Authorizer for the given custodial address to
+	// be used as payer. The returned key carries no sequence number when
+	// sequence number tracking is enabled.
+	PayerAuthorizer(ctx context.Context, address flow.Address) (Authorizer, error)
+	// UserAuthorizer returns an Authorizer for the given address to be used as proposer.
 	UserAuthorizer(ctx context.Context, address flow.Address) (Authorizer, error)
+	// UserAuthorizers returns a set of Authorizers for the given address whose
+	// combined key weight meets the signing threshold. The first Authorizer
+	// should be used as the proposer.
+	UserAuthorizers(ctx context.Context, address flow.Address) ([]Authorizer, error)
+	// UserSigners returns a set of Authorizers like UserAuthorizers to be used
+	// only for signing the payload as an additional authorizer. The returned keys
+	// carry no sequence number when sequence number tracking is enabled.
+	UserSigners(ctx context.Context, address flow.Address) ([]Authorizer, error)
 	// CheckAdminProposalKeyCount checks if admin proposal keys have been correctly initiated (counts match).
 	CheckAdminProposalKeyCount(ctx context.Context) error
 	// InitAdminProposalKeys will init the admin proposal keys in the database
@@ -40,6 +119,19 @@ type Manager interface {
 	InitAdminProposalKeys(ctx context.Context) (uint16, error)
 	// AdminProposalKey returns Authorizer to be used as proposer.
 	AdminProposalKey(ctx context.Context) (Authorizer, error)
+	// ExternalProposerSet returns the Authorizers needed to propose a
+	// transaction by the given address which is signed but not sent by the
+	// service. The sequence number of the proposal key is read from chain, no
+	// tracked sequence number is allocated.
+	ExternalProposerSet(ctx context.Context, address flow.Address) ([]Authorizer, error)
+	// ReleaseSequenceNumber makes a tracked sequence number of the given
+	// account key available again, as its transaction never reached the chain.
+	ReleaseSequenceNumber(address flow.Address, keyIndex int, sequenceNumber uint64) error
+	// ResyncSequenceNumber resyncs the tracked sequence number of the given
+	// account key with the chain after a transaction using sequenceNumber
+	// failed because of an invalid sequence number. Sequence numbers of
+	// transactions which may still be in flight are not reused.
+	ResyncSequenceNumber(ctx context.Context, address flow.Address, keyIndex int, sequenceNumber uint64) error
 }
 
 // Storable struct represents a storable account private key.
@@ -55,6 +147,7 @@ type Storable struct {
 	PublicKey      string         `json:"publicKey"`
 	SignAlgo       string         `json:"signAlgo"`
 	HashAlgo       string         `json:"hashAlgo"`
+	Weight         int            `json:"weight" gorm:"default:1000"`
 	CreatedAt      time.Time      `json:"createdAt"`
 	UpdatedAt      time.Time      `json:"updatedAt"`
 	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
@@ -76,6 +169,38 @@ func (ProposalKey) TableName() string {
 	return "proposal_keys"
 }
 
+// SequenceNumber holds the next proposal key sequence number of an account key
+// when sequence numbers are tracked locally.
+type SequenceNumber struct {
+	ID        int    `gorm:"primaryKey"`
+	Address   string `gorm:"uniqueIndex:idx_sequence_numbers_address_key_index;not null"`
+	KeyIndex  int    `gorm:"uniqueIndex:idx_sequence_numbers_address_key_index;not null"`
+	Number    uint64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (SequenceNumber) TableName() string {
+	return "sequence_numbers"
+}
+
+// SequenceNumberAllocation is a tracked sequence number of an account key
+// allocated to a transaction which may still be in flight, or a released
+// sequence number to be allocated again.
+type SequenceNumberAllocation struct {
+	ID        int    `gorm:"primaryKey"`
+	Address   string `gorm:"index:idx_sequence_number_allocations_address_key_index;not null"`
+	KeyIndex  int    `gorm:"index:idx_sequence_number_allocations_address_key_index;not null"`
+	Number    uint64
+	Released  bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (SequenceNumberAllocation) TableName() string {
+	return "sequence_number_allocations"
+}
+
 // Private is an "in flight" account private key meaning its Value should be the actual
 // private key or resource id (unencrypted).
 type Private struct {
@@ -84,6 +209,7 @@ type Private struct {
 	Value    string                    `json:"-"`
 	SignAlgo crypto.SignatureAlgorithm `json:"-"`
 	HashAlgo crypto.HashAlgorithm      `json:"-"`
+	Weight   int                       `json:"-"`
 }
 
 // Authorizer groups the necessary items for transaction signing.
@@ -96,3 +222,45 @@ type Authorizer struct {
 func (a *Authorizer) Equals(t Authorizer) bool {
 	return a.Address.Hex() == t.Address.Hex() && a.Key.Index == t.Key.Index
 }
+
+// KeySet groups the stored clones of a single logical account key,
+// i.e. all stored keys of an account sharing the same public key.
+type KeySet struct {
+	PublicKey string `json:"publicKey"`
+	Type      string `json:"type"`
+	Weight    int    `json:"weight"`
+	SignAlgo  string `json:"signAlgo"`
+	HashAlgo  string `json:"hashAlgo"`
+	Indexes   []int  `json:"indexes"`
+}
+
+// GroupKeySets groups the given storable keys into key sets by public key.
+// Key sets are ordered by the lowest key index in each set.
+func GroupKeySets(storables []Storable) []KeySet {
+	sorted := make([]Storable, len(storables))
+	copy(sorted, storables)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Index < sorted[j].Index
+	})
+
+	sets := []KeySet{}
+	setIndex := make(map[string]int)
+	for _, k := range sorted {
+		i, ok := setIndex[k.PublicKey]
+		if !ok {
+			i = len(sets)
+			setIndex[k.PublicKey] = i
+			sets = append(sets, KeySet{
+				PublicKey: k.PublicKey,
+				Type:      k.Type,
+				Weight:    k.Weight,
+				SignAlgo:  k.SignAlgo,
+				HashAlgo:  k.HashAlgo,
+				Indexes:   []int{},
+			})
+		}
+		sets[i].Indexes = append(sets[i].Indexes, k.Index)
+	}
+
+	return sets
+}
@@ -0,0 +1,69 @@
+package keys
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGroupKeySets(t *testing.T) {
+	t.Run("groups clones by public key", func(t *testing.T) {
+		storables := []Storable{
+			{Index: 2, Type: AccountKeyTypeAWSKMS, PublicKey: "0xb", Weight: 500},
+			{Index: 0, Type: AccountKeyTypeLocal, PublicKey: "0xa", Weight: 500},
+			{Index: 3, Type: AccountKeyTypeAWSKMS, PublicKey: "0xb", Weight: 500},
+			{Index: 1, Type: AccountKeyTypeLocal, PublicKey: "0xa", Weight: 500},
+		}
+
+		sets := GroupKeySets(storables)
+
+		if len(sets) != 2 {
+			t.Fatalf("expected 2 key sets, got %d", len(sets))
+		}
+
+		if sets[0].PublicKey != "0xa" || !reflect.DeepEqual(sets[0].Indexes, []int{0, 1}) {
+			t.Fatalf("unexpected first key set: %+v", sets[0])
+		}
+
+		if sets[1].PublicKey != "0xb" || !reflect.DeepEqual(sets[1].Indexes, []int{2, 3}) {
+			t.Fatalf("unexpected second key set: %+v", sets[1])
+		}
+
+		if sets[1].Type != AccountKeyTypeAWSKMS || sets[1].Weight != 500 {
+			t.Fatalf("unexpected second key set: %+v", sets[1])
+		}
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		if sets := GroupKeySets(nil); len(sets) != 0 {
+			t.Fatalf("expected no key sets, got %d", len(sets))
+		}
+	})
+}
+
+func TestIsValidKeyType(t *testing.T) {
+	for _, keyType := range []string{AccountKeyTypeLocal, AccountKeyTypeGoogleKMS, AccountKeyTypeAWSKMS} {
+		if !IsValidKeyType(keyType) {
+			t.Errorf("expected %s to be valid", keyType)
+		}
+	}
+
+	if IsValidKeyType("unknown") {
+		t.Error("expected unknown key type to be invalid")
+	}
+}
+
+func TestIsSequenceNumberError(t *testing.T) {
+	err := errors.New("[Error Code: 1007] invalid proposal key: public key 0 on account f8d6e0586b0a20c7 has sequence number 7, but given 6")
+	if !IsSequenceNumberError(err) {
+		t.Error("expected sequence number error")
+	}
+
+	if IsSequenceNumberError(errors.New("signature is not valid")) {
+		t.Error("expected other errors not to be sequence number errors")
+	}
+
+	if IsSequenceNumberError(nil) {
+		t.Error("expected nil not to be a sequence number error")
+	}
+}
@@ -41,11 +41,36 @@ func Generate(
 		Value:    strings.TrimPrefix(pk.String(), "0x"),
 		SignAlgo: signAlgo,
 		HashAlgo: hashAlgo,
+		Weight:   weight,
 	}
 
 	return f, p, nil
 }
 
+// Import decodes an existing hex encoded private key and returns its public
+// key with an "in flight" private key.
+func Import(
+	privateKeyHex string,
+	signAlgo crypto.SignatureAlgorithm,
+	hashAlgo crypto.HashAlgorithm,
+) (crypto.PublicKey, *keys.Private, error) {
+	value := strings.TrimPrefix(privateKeyHex, "0x")
+
+	pk, err := crypto.DecodePrivateKeyHex(signAlgo, value)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := &keys.Private{
+		Type:     keys.AccountKeyTypeLocal,
+		Value:    value,
+		SignAlgo: signAlgo,
+		HashAlgo: hashAlgo,
+	}
+
+	return pk.PublicKey(), p, nil
+}
+
 func Signer(ctx context.Context, key keys.Private) (crypto.Signer, error) {
 	p, err := crypto.DecodePrivateKeyHex(key.SignAlgo, key.Value)
 	if err != nil {
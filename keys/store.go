@@ -3,8 +3,25 @@ package keys
 // Store is the interface required by key manager for data storage.
 type Store interface {
 	AccountKey(address string) (Storable, error)
+	AccountKeySet(address string) ([]Storable, error)
 	ProposalKeyIndex(limitKeyCount int) (int, error)
 	ProposalKeyCount() (int64, error)
 	InsertProposalKey(proposalKey ProposalKey) error
 	DeleteAllProposalKeys() error
+	// NextSequenceNumber allocates the lowest released sequence number of the
+	// given account key, or returns the tracked sequence number and increments
+	// it. If the key is not yet tracked, fetch is used to get the current
+	// sequence number from chain.
+	NextSequenceNumber(address string, keyIndex int, fetch func() (uint64, error)) (uint64, error)
+	// ReleaseSequenceNumber makes an allocated sequence number available
+	// again. The tracked sequence number is decremented if number was the
+	// last one allocated.
+	ReleaseSequenceNumber(address string, keyIndex int, number uint64) error
+	// ResyncSequenceNumber resyncs the tracked sequence number of the given
+	// account key with the on-chain sequence number returned by fetch after
+	// the transaction using failed was rejected. Sequence numbers between the
+	// on-chain one and the tracked one are released, except those allocated
+	// to transactions which may still be in flight.
+	ResyncSequenceNumber(address string, keyIndex int, failed uint64, fetch func() (uint64, error)) error
+	DeleteSequenceNumber(address string, keyIndex int) error
 }
@@ -1,6 +1,7 @@
 package keys
 
 import (
+	"sort"
 	"sync"
 	"time"
 
@@ -11,9 +12,10 @@ import (
 )
 
 type GormStore struct {
-	accountKeyMutex  sync.Mutex
-	proposalKeyMutex sync.Mutex
-	db               *gorm.DB
+	accountKeyMutex     sync.Mutex
+	proposalKeyMutex    sync.Mutex
+	sequenceNumberMutex sync.Mutex
+	db                  *gorm.DB
 }
 
 func NewGormStore(db *gorm.DB) Store {
@@ -46,6 +48,51 @@ func (s *GormStore) AccountKey(address string) (Storable, error) {
 	return k, err
 }
 
+// AccountKeySet returns the "least recently used" stored key of each distinct
+// public key for the given address, ordered by key index.
+func (s *GormStore) AccountKeySet(address string) ([]Storable, error) {
+	s.accountKeyMutex.Lock()
+	defer s.accountKeyMutex.Unlock()
+
+	var set []Storable
+
+	err := lib.GormTransaction(s.db, func(tx *gorm.DB) error {
+		var all []Storable
+
+		if err := tx.
+			// NOWAIT so this call will fail rather than use a stale value
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
+			Where(&Storable{AccountAddress: address}).
+			Order("updated_at asc").
+			Find(&all).Error; err != nil {
+			return err
+		}
+
+		seen := make(map[string]bool)
+		ids := []int{}
+		for _, k := range all {
+			if seen[k.PublicKey] {
+				continue
+			}
+			seen[k.PublicKey] = true
+			set = append(set, k)
+			ids = append(ids, k.ID)
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		return tx.Model(&Storable{}).Where("id IN ?", ids).Update("updated_at", time.Now()).Error
+	})
+
+	sort.SliceStable(set, func(i, j int) bool {
+		return set[i].Index < set[j].Index
+	})
+
+	return set, err
+}
+
 func (s *GormStore) ProposalKeyIndex(limitKeyCount int) (int, error) {
 	s.proposalKeyMutex.Lock()
 	defer s.proposalKeyMutex.Unlock()
@@ -83,3 +130,184 @@ func (s *GormStore) InsertProposalKey(p ProposalKey) error {
 func (s *GormStore) DeleteAllProposalKeys() error {
 	return s.db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&ProposalKey{}).Error
 }
+
+func (s *GormStore) NextSequenceNumber(address string, keyIndex int, fetch func() (uint64, error)) (uint64, error) {
+	s.sequenceNumberMutex.Lock()
+	defer s.sequenceNumberMutex.Unlock()
+
+	var next uint64
+
+	err := lib.GormTransaction(s.db, func(tx *gorm.DB) error {
+		n, found, err := lockSequenceNumber(tx, address, keyIndex)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			current, err := fetch()
+			if err != nil {
+				return err
+			}
+			n = SequenceNumber{Address: address, KeyIndex: keyIndex, Number: current}
+			if err := tx.Create(&n).Error; err != nil {
+				return err
+			}
+		}
+
+		// Allocations older than the TTL are no longer in flight
+		if err := tx.
+			Where("address = ? AND key_index = ? AND released = ? AND updated_at < ?", address, keyIndex, false, time.Now().Add(-SequenceNumberAllocationTTL)).
+			Delete(&SequenceNumberAllocation{}).Error; err != nil {
+			return err
+		}
+
+		// Reuse the lowest released sequence number first
+		a := SequenceNumberAllocation{}
+		res := tx.
+			Where("address = ? AND key_index = ? AND released = ?", address, keyIndex, true).
+			Order("number asc").
+			Limit(1).Find(&a)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected > 0 {
+			next = a.Number
+			return tx.Model(&a).Update("released", false).Error
+		}
+
+		next = n.Number
+		if err := tx.Model(&n).Update("number", n.Number+1).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&SequenceNumberAllocation{Address: address, KeyIndex: keyIndex, Number: next}).Error
+	})
+
+	return next, err
+}
+
+func (s *GormStore) ReleaseSequenceNumber(address string, keyIndex int, number uint64) error {
+	s.sequenceNumberMutex.Lock()
+	defer s.sequenceNumberMutex.Unlock()
+
+	return lib.GormTransaction(s.db, func(tx *gorm.DB) error {
+		n, found, err := lockSequenceNumber(tx, address, keyIndex)
+		if err != nil || !found || number >= n.Number {
+			return err
+		}
+
+		allocation := tx.Where("address = ? AND key_index = ? AND number = ?", address, keyIndex, number)
+
+		if number+1 == n.Number {
+			// Last allocated sequence number, nothing has to be left released
+			if err := allocation.Delete(&SequenceNumberAllocation{}).Error; err != nil {
+				return err
+			}
+			return tx.Model(&n).Update("number", number).Error
+		}
+
+		res := allocation.Model(&SequenceNumberAllocation{}).Update("released", true)
+		if res.Error != nil || res.RowsAffected > 0 {
+			return res.Error
+		}
+
+		return tx.Create(&SequenceNumberAllocation{Address: address, KeyIndex: keyIndex, Number: number, Released: true}).Error
+	})
+}
+
+func (s *GormStore) ResyncSequenceNumber(address string, keyIndex int, failed uint64, fetch func() (uint64, error)) error {
+	s.sequenceNumberMutex.Lock()
+	defer s.sequenceNumberMutex.Unlock()
+
+	return lib.GormTransaction(s.db, func(tx *gorm.DB) error {
+		n, found, err := lockSequenceNumber(tx, address, keyIndex)
+		if err != nil || !found {
+			return err
+		}
+
+		onChain, err := fetch()
+		if err != nil {
+			return err
+		}
+
+		// Only allocations which may still be in flight are kept: sequence
+		// numbers below the on-chain one have been used, allocations older
+		// than the TTL have expired and the failed transaction did not use its
+		// sequence number.
+		if err := tx.
+			Where("address = ? AND key_index = ?", address, keyIndex).
+			Where("released = ? OR number < ? OR number = ? OR updated_at < ?", true, onChain, failed, time.Now().Add(-SequenceNumberAllocationTTL)).
+			Delete(&SequenceNumberAllocation{}).Error; err != nil {
+			return err
+		}
+
+		var inFlight []SequenceNumberAllocation
+		if err := tx.
+			Where("address = ? AND key_index = ?", address, keyIndex).
+			Order("number asc").
+			Find(&inFlight).Error; err != nil {
+			return err
+		}
+
+		if len(inFlight) == 0 {
+			return tx.Model(&n).Update("number", onChain).Error
+		}
+
+		// Sequence numbers up to the tracked one which are not in flight are
+		// released, so the gaps left on chain are filled first
+		allocated := make(map[uint64]bool, len(inFlight))
+		for _, a := range inFlight {
+			allocated[a.Number] = true
+		}
+
+		released := []SequenceNumberAllocation{}
+		for number := onChain; number < n.Number; number++ {
+			if !allocated[number] {
+				released = append(released, SequenceNumberAllocation{Address: address, KeyIndex: keyIndex, Number: number, Released: true})
+			}
+		}
+
+		if len(released) == 0 {
+			return nil
+		}
+
+		return tx.Create(&released).Error
+	})
+}
+
+func (s *GormStore) DeleteSequenceNumber(address string, keyIndex int) error {
+	s.sequenceNumberMutex.Lock()
+	defer s.sequenceNumberMutex.Unlock()
+
+	// Take the same lock as NextSequenceNumber so a sequence number is not
+	// allocated (by another instance) while the tracked value is discarded
+	return lib.GormTransaction(s.db, func(tx *gorm.DB) error {
+		n, found, err := lockSequenceNumber(tx, address, keyIndex)
+		if err != nil || !found {
+			return err
+		}
+
+		if err := tx.
+			Where("address = ? AND key_index = ?", address, keyIndex).
+			Delete(&SequenceNumberAllocation{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&n).Error
+	})
+}
+
+// lockSequenceNumber selects the tracked sequence number of an account key for
+// update. It waits for the lock instead of NOWAIT; concurrent transactions
+// using the same proposal key have to get consecutive sequence numbers.
+func lockSequenceNumber(tx *gorm.DB, address string, keyIndex int) (SequenceNumber, bool, error) {
+	n := SequenceNumber{}
+
+	res := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("address = ? AND key_index = ?", address, keyIndex).
+		Limit(1).Find(&n)
+
+	return n, res.RowsAffected > 0, res.Error
+}
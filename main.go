@@ -10,14 +10,19 @@ import (
 	"time"
 
 	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/backup"
 	"github.com/flow-hydraulics/flow-wallet-api/chain_events"
 	"github.com/flow-hydraulics/flow-wallet-api/configs"
 	"github.com/flow-hydraulics/flow-wallet-api/datastore/gorm"
+	"github.com/flow-hydraulics/flow-wallet-api/decommission"
 	"github.com/flow-hydraulics/flow-wallet-api/handlers"
 	"github.com/flow-hydraulics/flow-wallet-api/jobs"
 	"github.com/flow-hydraulics/flow-wallet-api/keys"
 	"github.com/flow-hydraulics/flow-wallet-api/keys/basic"
 	"github.com/flow-hydraulics/flow-wallet-api/ops"
+	"github.com/flow-hydraulics/flow-wallet-api/payers"
+	"github.com/flow-hydraulics/flow-wallet-api/reconciliation"
+	"github.com/flow-hydraulics/flow-wallet-api/reports"
 	"github.com/flow-hydraulics/flow-wallet-api/system"
 	"github.com/flow-hydraulics/flow-wallet-api/templates"
 	"github.com/flow-hydraulics/flow-wallet-api/tokens"
@@ -40,13 +45,21 @@ var (
 
 func main() {
 	var (
-		printVersion bool
-		envFilePath  string // LEGACY: now used to check if user still is using envFilePath
+		printVersion      bool
+		envFilePath       string // LEGACY: now used to check if user still is using envFilePath
+		exportBackupPath  string
+		restoreBackupPath string
+		liabilitiesPath   string
+		liabilitiesGroup  string
 	)
 
 	// If we should just print the version number and exit
 	flag.BoolVar(&printVersion, "version", false, "if true, print version and exit")
 	flag.StringVar(&envFilePath, "envfile", "", "deprecated")
+	flag.StringVar(&exportBackupPath, "export-backup", "", "if set, export a sealed backup archive to this path and exit")
+	flag.StringVar(&restoreBackupPath, "restore-backup", "", "if set, restore a sealed backup archive from this path and exit")
+	flag.StringVar(&liabilitiesPath, "liabilities-report", "", `if set, write a CSV liabilities report to this path ("-" for stdout) and exit`)
+	flag.StringVar(&liabilitiesGroup, "liabilities-group-by", "", "account label to group the liabilities report by")
 	flag.Parse()
 
 	if envFilePath != "" {
@@ -63,6 +76,21 @@ func main() {
 		panic(err)
 	}
 
+	if exportBackupPath != "" {
+		runExportBackup(cfg, exportBackupPath)
+		os.Exit(0)
+	}
+
+	if restoreBackupPath != "" {
+		runRestoreBackup(cfg, restoreBackupPath)
+		os.Exit(0)
+	}
+
+	if liabilitiesPath != "" {
+		runLiabilitiesReport(cfg, liabilitiesPath, liabilitiesGroup)
+		os.Exit(0)
+	}
+
 	runServer(cfg)
 
 	os.Exit(0)
@@ -131,10 +159,14 @@ func runServer(cfg *configs.Config) {
 		log.Fatal(err)
 	}
 	jobsService := jobs.NewService(jobs.NewGormStore(db))
-	transactionService := transactions.NewService(cfg, transactions.NewGormStore(db), km, fc, wp, transactions.WithTxRatelimiter(txRatelimiter))
+	payerService := payers.NewService(cfg, payers.NewGormStore(db), km, fc, accounts.NewGormStore(db))
+	transactionService := transactions.NewService(cfg, transactions.NewGormStore(db), km, fc, wp, transactions.WithTxRatelimiter(txRatelimiter), transactions.WithPayerProvider(payerService), transactions.WithAccountGuard(accounts.NewClosedAccountGuard(accounts.NewGormStore(db))))
 	accountService := accounts.NewService(cfg, accounts.NewGormStore(db), km, fc, wp, transactionService, templateService, accounts.WithTxRatelimiter(txRatelimiter))
 	tokenService := tokens.NewService(cfg, tokens.NewGormStore(db), km, fc, wp, transactionService, templateService, accountService)
 	opsService := ops.NewService(cfg, ops.NewGormStore(db), templateService, transactionService, tokenService)
+	reportService := reports.NewService(cfg, accounts.NewGormStore(db), tokens.NewGormStore(db), templateService, fc)
+	decommissionService := decommission.NewService(cfg, accounts.NewGormStore(db), payerService, tokenService, tokens.NewGormStore(db), templateService, transactionService, fc, wp)
+	reconciliationService := reconciliation.NewService(cfg, reconciliation.NewGormStore(db), accounts.NewGormStore(db), payerService, tokenService, tokens.NewGormStore(db), templateService, transactionService, fc, wp)
 
 	// Register a handler for account added events
 	accounts.AccountAdded.Register(&tokens.AccountAddedHandler{
@@ -150,14 +182,76 @@ func runServer(cfg *configs.Config) {
 	wp.Start()
 	log.Info("Started workerpool")
 
+	payerService.Start()
+	defer func() {
+		payerService.Stop()
+		log.Info("Stopped payer pool balance checks")
+	}()
+
+	if cfg.EnableSealTracker {
+		sealTracker := transactions.NewSealTracker(cfg, transactions.NewGormStore(db), km, fc, wp)
+
+		// Register a handler for resolved transactions
+		transactions.TransactionResolved.Register(&tokens.TransactionResolvedHandler{
+			Store: tokens.NewGormStore(db),
+		})
+
+		sealTracker.Start()
+		defer func() {
+			sealTracker.Stop()
+			log.Info("Stopped seal tracker")
+		}()
+
+		log.Info("Started seal tracker")
+	}
+
+	if cfg.AccountPoolSize > 0 {
+		accountPool := accounts.NewAccountPool(accountService, cfg.AccountPoolRefillInterval)
+		accountPool.Start()
+		defer func() {
+			accountPool.Stop()
+			log.Info("Stopped account pool")
+		}()
+
+		log.Info("Started account pool")
+	}
+
+	if cfg.BalanceSnapshotInterval > 0 {
+		balanceSnapshotter := tokens.NewBalanceSnapshotter(tokenService, cfg.BalanceSnapshotInterval)
+		balanceSnapshotter.Start()
+		defer func() {
+			balanceSnapshotter.Stop()
+			log.Info("Stopped balance snapshots")
+		}()
+
+		log.Info("Started balance snapshots")
+	}
+
+	if cfg.ReconciliationInterval > 0 {
+		reconciler := reconciliation.NewReconciler(reconciliationService, cfg.ReconciliationInterval, cfg.ReconciliationBackfill)
+		reconciler.Start()
+		defer func() {
+			reconciler.Stop()
+			log.Info("Stopped periodic reconciliation")
+		}()
+
+		log.Info("Started periodic reconciliation")
+	}
+
 	// HTTP handling
 	systemHandler := handlers.NewSystem(systemService)
 	templateHandler := handlers.NewTemplates(templateService)
+	cadenceTemplateHandler := handlers.NewCadenceTemplates(templateService, transactionService)
 	jobsHandler := handlers.NewJobs(jobsService)
 	accountHandler := handlers.NewAccounts(accountService)
 	transactionHandler := handlers.NewTransactions(transactionService)
 	tokenHandler := handlers.NewTokens(tokenService)
 	opsHandler := handlers.NewOps(opsService)
+	payerHandler := handlers.NewPayers(payerService)
+	decommissionHandler := handlers.NewDecommission(decommissionService)
+	reportHandler := handlers.NewReports(reportService)
+	reconciliationHandler := handlers.NewReconciliation(reconciliationService)
+	backupHandler := handlers.NewBackup(backup.NewService(cfg, backup.NewGormStore(db), km))
 
 	r := mux.NewRouter()
 
@@ -179,6 +273,19 @@ func runServer(cfg *configs.Config) {
 
 	rv.Handle("/system/sync-account-key-count", accountHandler.SyncAccountKeyCount()).Methods(http.MethodPost)
 
+	// Backup
+	if cfg.EnableBackupApi {
+		rv.Handle("/system/backup/export", backupHandler.Export()).Methods(http.MethodPost)   // export
+		rv.Handle("/system/backup/restore", backupHandler.Restore()).Methods(http.MethodPost) // restore
+	}
+
+	// Payer pool
+	rv.Handle("/payers", payerHandler.List()).Methods(http.MethodGet)                          // list
+	rv.Handle("/payers", payerHandler.Add()).Methods(http.MethodPost)                          // add
+	rv.Handle("/payers/check-balances", payerHandler.CheckBalances()).Methods(http.MethodPost) // check balances
+	rv.Handle("/payers/{address}", payerHandler.Details()).Methods(http.MethodGet)             // details
+	rv.Handle("/payers/{address}", payerHandler.Remove()).Methods(http.MethodDelete)           // remove
+
 	// Jobs
 	rv.Handle("/jobs", jobsHandler.List()).Methods(http.MethodGet)            // list
 	rv.Handle("/jobs/{jobId}", jobsHandler.Details()).Methods(http.MethodGet) // details
@@ -193,28 +300,61 @@ func runServer(cfg *configs.Config) {
 	rv.Handle("/fungible-tokens", templateHandler.ListTokens(templates.FT)).Methods(http.MethodGet)      // list
 	rv.Handle("/non-fungible-tokens", templateHandler.ListTokens(templates.NFT)).Methods(http.MethodGet) // list
 
+	// Cadence template registry
+	rv.Handle("/cadence-templates", cadenceTemplateHandler.List()).Methods(http.MethodGet)                                           // list
+	rv.Handle("/cadence-templates", cadenceTemplateHandler.Create()).Methods(http.MethodPost)                                        // create or add version
+	rv.Handle("/cadence-templates/{name}", cadenceTemplateHandler.Details()).Methods(http.MethodGet)                                 // latest version
+	rv.Handle("/cadence-templates/{name}/versions/{version}", cadenceTemplateHandler.Details()).Methods(http.MethodGet)              // details
+	rv.Handle("/cadence-templates/{name}/versions/{version}/deprecate", cadenceTemplateHandler.Deprecate()).Methods(http.MethodPost) // deprecate
+	rv.Handle("/cadence-templates/{name}/scripts", cadenceTemplateHandler.ExecuteScript()).Methods(http.MethodPost)                  // execute script
+
 	// Transactions
 	rv.Handle("/transactions", transactionHandler.List()).Methods(http.MethodGet)                    // list
 	rv.Handle("/transactions/{transactionId}", transactionHandler.Details()).Methods(http.MethodGet) // details
 
 	// Account
-	rv.Handle("/accounts", accountHandler.List()).Methods(http.MethodGet)              // list
-	rv.Handle("/accounts", accountHandler.Create()).Methods(http.MethodPost)           // create
-	rv.Handle("/accounts/{address}", accountHandler.Details()).Methods(http.MethodGet) // details
+	rv.Handle("/accounts", accountHandler.List()).Methods(http.MethodGet)                                       // list
+	rv.Handle("/accounts", accountHandler.Create()).Methods(http.MethodPost)                                    // create
+	rv.Handle("/accounts/batch", accountHandler.CreateBatch()).Methods(http.MethodPost)                         // create batch
+	rv.Handle("/accounts/import", accountHandler.Import()).Methods(http.MethodPost)                             // import
+	rv.Handle("/accounts/{address}", accountHandler.Details()).Methods(http.MethodGet)                          // details
+	rv.Handle("/accounts/{address}", accountHandler.Update()).Methods(http.MethodPatch)                         // update metadata
+	rv.Handle("/accounts/{address}/keys", accountHandler.KeySets()).Methods(http.MethodGet)                     // list key sets
+	rv.Handle("/accounts/{address}/decommission", decommissionHandler.Decommission()).Methods(http.MethodPost)  // decommission
+	rv.Handle("/accounts/{address}/contracts", accountHandler.Contracts()).Methods(http.MethodGet)              // list contracts
+	rv.Handle("/accounts/{address}/contracts/{name}", accountHandler.ContractDetails()).Methods(http.MethodGet) // contract details
 
 	// Account raw transactions
 	if !cfg.DisableRawTransactions {
-		rv.Handle("/accounts/{address}/sign", transactionHandler.Sign()).Methods(http.MethodPost)                           // sign
-		rv.Handle("/accounts/{address}/transactions", transactionHandler.List()).Methods(http.MethodGet)                    // list
-		rv.Handle("/accounts/{address}/transactions", transactionHandler.Create()).Methods(http.MethodPost)                 // create
-		rv.Handle("/accounts/{address}/transactions/{transactionId}", transactionHandler.Details()).Methods(http.MethodGet) // details
+		rv.Handle("/transactions", transactionHandler.Create()).Methods(http.MethodPost)                                                             // create with authorizers
+		rv.Handle("/accounts/{address}/sign", transactionHandler.Sign()).Methods(http.MethodPost)                                                    // sign
+		rv.Handle("/accounts/{address}/transactions", transactionHandler.List()).Methods(http.MethodGet)                                             // list
+		rv.Handle("/accounts/{address}/transactions", transactionHandler.Create()).Methods(http.MethodPost)                                          // create
+		rv.Handle("/accounts/{address}/transactions/{transactionId}", transactionHandler.Details()).Methods(http.MethodGet)                          // details
+		rv.Handle("/accounts/{address}/cadence-templates/{name}/transactions", cadenceTemplateHandler.ExecuteTransaction()).Methods(http.MethodPost) // execute template
+		rv.Handle("/accounts/{address}/contracts", accountHandler.DeployContract()).Methods(http.MethodPost)                                         // deploy contract
+		rv.Handle("/accounts/{address}/contracts/{name}", accountHandler.UpdateContract()).Methods(http.MethodPut)                                   // update contract
+		rv.Handle("/accounts/{address}/contracts/{name}", accountHandler.RemoveContract()).Methods(http.MethodDelete)                                // remove contract
 	} else {
 		log.Info("raw transactions disabled")
 	}
 
+	// Code allowlist for raw transactions
+	if cfg.EnableCodeAllowlist {
+		rv.Handle("/code-allowlist", transactionHandler.ListAllowedCodes()).Methods(http.MethodGet)          // list
+		rv.Handle("/code-allowlist", transactionHandler.AddAllowedCode()).Methods(http.MethodPost)           // add
+		rv.Handle("/code-allowlist/{id}", transactionHandler.RemoveAllowedCode()).Methods(http.MethodDelete) // remove
+	}
+
+	// Fee sponsorship for non-custodial transactions
+	if cfg.EnableFeeSponsorship {
+		rv.Handle("/transactions/sponsor", transactionHandler.Sponsor()).Methods(http.MethodPost) // sponsor
+	}
+
 	// Non-custodial watchlist accounts
-	rv.Handle("/watchlist/accounts", accountHandler.AddNonCustodialAccount()).Methods(http.MethodPost)                // add
-	rv.Handle("/watchlist/accounts/{address}", accountHandler.DeleteNonCustodialAccount()).Methods(http.MethodDelete) // delete
+	rv.Handle("/watchlist/accounts", accountHandler.AddNonCustodialAccount()).Methods(http.MethodPost)                        // add
+	rv.Handle("/watchlist/accounts/{address}", accountHandler.DeleteNonCustodialAccount()).Methods(http.MethodDelete)         // delete
+	rv.Handle("/watchlist/accounts/{address}/discover-tokens", tokenHandler.DiscoverAccountTokens()).Methods(http.MethodPost) // discover tokens
 
 	// Scripts
 	rv.Handle("/scripts", transactionHandler.ExecuteScript()).Methods(http.MethodPost) // execute
@@ -224,6 +364,7 @@ func runServer(cfg *configs.Config) {
 		rv.Handle("/accounts/{address}/fungible-tokens", tokenHandler.AccountTokens(templates.FT)).Methods(http.MethodGet)
 		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}", tokenHandler.Details()).Methods(http.MethodGet)
 		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}", tokenHandler.Setup()).Methods(http.MethodPost)
+		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}/balance-history", tokenHandler.BalanceHistory()).Methods(http.MethodGet)
 		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}/withdrawals", tokenHandler.ListWithdrawals()).Methods(http.MethodGet)
 		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}/withdrawals", tokenHandler.CreateWithdrawal()).Methods(http.MethodPost)
 		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}/withdrawals/{transactionId}", tokenHandler.GetWithdrawal()).Methods(http.MethodGet)
@@ -238,6 +379,7 @@ func runServer(cfg *configs.Config) {
 		rv.Handle("/accounts/{address}/non-fungible-tokens", tokenHandler.AccountTokens(templates.NFT)).Methods(http.MethodGet)
 		rv.Handle("/accounts/{address}/non-fungible-tokens/{tokenName}", tokenHandler.Details()).Methods(http.MethodGet)
 		rv.Handle("/accounts/{address}/non-fungible-tokens/{tokenName}", tokenHandler.Setup()).Methods(http.MethodPost)
+		rv.Handle("/accounts/{address}/non-fungible-tokens/{tokenName}/items", tokenHandler.NftItems()).Methods(http.MethodGet)
 		rv.Handle("/accounts/{address}/non-fungible-tokens/{tokenName}/withdrawals", tokenHandler.ListWithdrawals()).Methods(http.MethodGet)
 		rv.Handle("/accounts/{address}/non-fungible-tokens/{tokenName}/withdrawals", tokenHandler.CreateWithdrawal()).Methods(http.MethodPost)
 		rv.Handle("/accounts/{address}/non-fungible-tokens/{tokenName}/withdrawals/{transactionId}", tokenHandler.GetWithdrawal()).Methods(http.MethodGet)
@@ -247,6 +389,13 @@ func runServer(cfg *configs.Config) {
 		log.Info("non-fungible tokens disabled")
 	}
 
+	// Reports
+	rv.Handle("/reports/liabilities", reportHandler.Liabilities()).Methods(http.MethodGet) // liabilities
+
+	// Reconciliation
+	rv.Handle("/reconciliation", reconciliationHandler.Reconcile()).Methods(http.MethodPost)                  // reconcile
+	rv.Handle("/reconciliation/discrepancies", reconciliationHandler.Discrepancies()).Methods(http.MethodGet) // list discrepancies
+
 	// Ops
 	rv.Handle("/ops/missing-fungible-token-vaults/start", opsHandler.InitMissingFungibleVaults()).Methods(http.MethodGet) // start retroactive init job
 	rv.Handle("/ops/missing-fungible-token-vaults/stats", opsHandler.GetMissingFungibleVaults()).Methods(http.MethodGet)  // get number of accounts with missing fungible token vaults
@@ -332,15 +481,8 @@ func runServer(cfg *configs.Config) {
 				return nil, err
 			}
 
-			token_count := len(tt)
-			event_types := make([]string, token_count)
-
-			// Listen for enabled tokens deposit events
-			for i, token := range tt {
-				event_types[i] = templates.DepositEventTypeFromToken(token)
-			}
-
-			return event_types, nil
+			// Listen for enabled tokens deposit and withdrawal events
+			return templates.TokenEventTypes(tt), nil
 		}
 
 		listener := chain_events.NewListener(
@@ -0,0 +1,44 @@
+package m20221019
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221019"
+
+type Storable struct {
+	ID             int            `json:"-" gorm:"primaryKey"`
+	AccountAddress string         `json:"-" gorm:"index"`
+	Index          int            `json:"index" gorm:"index"`
+	Type           string         `json:"type"`
+	Value          []byte         `json:"-"`
+	PublicKey      string         `json:"publicKey"`
+	SignAlgo       string         `json:"signAlgo"`
+	HashAlgo       string         `json:"hashAlgo"`
+	Weight         int            `json:"weight" gorm:"default:1000"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+func (Storable) TableName() string {
+	return "storable_keys"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.Migrator().AddColumn(&Storable{}, "weight"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropColumn(&Storable{}, "weight"); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+// m20221020 handles SequenceNumber migration
+// NOTE: SequenceNumbers are used to track proposal key sequence numbers locally
+// when sequence number tracking is enabled
+package m20221020
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221020"
+
+type SequenceNumber struct {
+	ID        int    `gorm:"primaryKey"`
+	Address   string `gorm:"uniqueIndex:idx_sequence_numbers_address_key_index;not null"`
+	KeyIndex  int    `gorm:"uniqueIndex:idx_sequence_numbers_address_key_index;not null"`
+	Number    uint64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (SequenceNumber) TableName() string {
+	return "sequence_numbers"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&SequenceNumber{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&SequenceNumber{}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+// m20221021 handles Payer migration
+// NOTE: Payers are custodial accounts in the payer pool used to pay for
+// transaction fees instead of the admin account
+package m20221021
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221021"
+
+type Payer struct {
+	ID         int    `gorm:"primaryKey"`
+	Address    string `gorm:"uniqueIndex;not null"`
+	Balance    uint64
+	Excluded   bool `gorm:"default:false"`
+	LastUsedAt time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (Payer) TableName() string {
+	return "payers"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&Payer{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&Payer{}); err != nil {
+		return err
+	}
+
+	return nil
+}
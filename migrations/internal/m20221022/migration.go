@@ -0,0 +1,51 @@
+// m20221022 adds a status column to transactions
+// NOTE: Status is used by the seal tracker to find transactions which have
+// been sent but whose result is still pending
+package m20221022
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221022"
+
+type Transaction struct {
+	TransactionId   string         `gorm:"column:transaction_id;primaryKey"`
+	TransactionType int            `gorm:"column:transaction_type;index"`
+	ProposerAddress string         `gorm:"column:proposer_address;index"`
+	FlowTransaction []byte         `gorm:"column:flow_transaction;type:bytes"`
+	Status          string         `gorm:"column:status;index"`
+	CreatedAt       time.Time      `gorm:"column:created_at"`
+	UpdatedAt       time.Time      `gorm:"column:updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"column:deleted_at;index"`
+}
+
+func (Transaction) TableName() string {
+	return "transactions"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.Migrator().AddColumn(&Transaction{}, "Status"); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().CreateIndex(&Transaction{}, "Status"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropIndex(&Transaction{}, "Status"); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().DropColumn(&Transaction{}, "Status"); err != nil {
+		return err
+	}
+
+	return nil
+}
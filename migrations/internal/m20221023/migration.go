@@ -0,0 +1,43 @@
+// m20221023 handles TransactionAttempt migration
+// NOTE: Each attempt is a Flow transaction sent on behalf of a transaction.
+// A transaction is rebuilt as a new attempt when its previous attempt expired
+// or failed because of an invalid proposal key sequence number
+package m20221023
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221023"
+
+type TransactionAttempt struct {
+	ID                uint64    `gorm:"column:id;primaryKey"`
+	TransactionId     string    `gorm:"column:transaction_id;index"`
+	FlowTransactionId string    `gorm:"column:flow_transaction_id;uniqueIndex"`
+	Status            string    `gorm:"column:status"`
+	Error             string    `gorm:"column:error"`
+	CreatedAt         time.Time `gorm:"column:created_at"`
+	UpdatedAt         time.Time `gorm:"column:updated_at"`
+}
+
+func (TransactionAttempt) TableName() string {
+	return "transaction_attempts"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&TransactionAttempt{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&TransactionAttempt{}); err != nil {
+		return err
+	}
+
+	return nil
+}
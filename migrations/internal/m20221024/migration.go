@@ -0,0 +1,75 @@
+// m20221024 adds result columns to transactions and handles TransactionEvent migration
+// NOTE: Results and events of sealed transactions are stored so they can be
+// returned without querying the Access API
+package m20221024
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221024"
+
+type Transaction struct {
+	TransactionId   string         `gorm:"column:transaction_id;primaryKey"`
+	TransactionType int            `gorm:"column:transaction_type;index"`
+	ProposerAddress string         `gorm:"column:proposer_address;index"`
+	FlowTransaction []byte         `gorm:"column:flow_transaction;type:bytes"`
+	Status          string         `gorm:"column:status;index"`
+	ResultError     string         `gorm:"column:result_error"`
+	BlockId         string         `gorm:"column:block_id"`
+	BlockHeight     uint64         `gorm:"column:block_height"`
+	CreatedAt       time.Time      `gorm:"column:created_at"`
+	UpdatedAt       time.Time      `gorm:"column:updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"column:deleted_at;index"`
+}
+
+func (Transaction) TableName() string {
+	return "transactions"
+}
+
+type TransactionEvent struct {
+	ID                uint64    `gorm:"column:id;primaryKey"`
+	TransactionId     string    `gorm:"column:transaction_id;index"`
+	FlowTransactionId string    `gorm:"column:flow_transaction_id"`
+	Type              string    `gorm:"column:type;index"`
+	TransactionIndex  int       `gorm:"column:transaction_index"`
+	EventIndex        int       `gorm:"column:event_index"`
+	Payload           []byte    `gorm:"column:payload;type:bytes"`
+	CreatedAt         time.Time `gorm:"column:created_at"`
+}
+
+func (TransactionEvent) TableName() string {
+	return "transaction_events"
+}
+
+var resultColumns = []string{"ResultError", "BlockId", "BlockHeight"}
+
+func Migrate(tx *gorm.DB) error {
+	for _, c := range resultColumns {
+		if err := tx.Migrator().AddColumn(&Transaction{}, c); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.AutoMigrate(&TransactionEvent{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&TransactionEvent{}); err != nil {
+		return err
+	}
+
+	for _, c := range resultColumns {
+		if err := tx.Migrator().DropColumn(&Transaction{}, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
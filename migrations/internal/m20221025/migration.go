@@ -0,0 +1,47 @@
+// m20221025 handles CadenceTemplate migration
+// NOTE: Cadence templates are named, versioned transactions and scripts
+// which can be executed by name
+package m20221025
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221025"
+
+type CadenceTemplate struct {
+	ID           uint64     `gorm:"column:id;primaryKey"`
+	Name         string     `gorm:"column:name;uniqueIndex:idx_cadence_templates_name_version;not null"`
+	Version      uint       `gorm:"column:version;uniqueIndex:idx_cadence_templates_name_version;not null"`
+	Kind         string     `gorm:"column:kind;not null"`
+	Description  string     `gorm:"column:description"`
+	Token        string     `gorm:"column:token"`
+	Code         string     `gorm:"column:code;not null"`
+	Parameters   string     `gorm:"column:parameters;type:text"`
+	Deprecated   bool       `gorm:"column:deprecated;index"`
+	DeprecatedAt *time.Time `gorm:"column:deprecated_at"`
+	CreatedAt    time.Time  `gorm:"column:created_at"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at"`
+}
+
+func (CadenceTemplate) TableName() string {
+	return "cadence_templates"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&CadenceTemplate{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&CadenceTemplate{}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+// m20221026 handles AllowedCode migration
+// NOTE: Allowed codes are normalized code hashes which raw transactions are
+// checked against when the code allowlist is enabled
+package m20221026
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221026"
+
+type AllowedCode struct {
+	ID          uint64    `gorm:"column:id;primaryKey"`
+	Hash        string    `gorm:"column:hash;uniqueIndex:idx_allowed_codes_hash_address;not null"`
+	Address     string    `gorm:"column:address;uniqueIndex:idx_allowed_codes_hash_address"`
+	Description string    `gorm:"column:description"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+func (AllowedCode) TableName() string {
+	return "allowed_codes"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&AllowedCode{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&AllowedCode{}); err != nil {
+		return err
+	}
+
+	return nil
+}
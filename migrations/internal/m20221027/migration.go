@@ -0,0 +1,71 @@
+// m20221027 adds compute limit columns to transactions and cadence templates
+// NOTE: The execution effort of sealed transactions is recorded so compute
+// limits can be tuned based on actual usage
+package m20221027
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221027"
+
+type Transaction struct {
+	TransactionId   string         `gorm:"column:transaction_id;primaryKey"`
+	TransactionType int            `gorm:"column:transaction_type;index"`
+	ProposerAddress string         `gorm:"column:proposer_address;index"`
+	FlowTransaction []byte         `gorm:"column:flow_transaction;type:bytes"`
+	Status          string         `gorm:"column:status;index"`
+	ResultError     string         `gorm:"column:result_error"`
+	BlockId         string         `gorm:"column:block_id"`
+	BlockHeight     uint64         `gorm:"column:block_height"`
+	ComputeLimit    uint64         `gorm:"column:compute_limit"`
+	ExecutionEffort uint64         `gorm:"column:execution_effort"`
+	CreatedAt       time.Time      `gorm:"column:created_at"`
+	UpdatedAt       time.Time      `gorm:"column:updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"column:deleted_at;index"`
+}
+
+func (Transaction) TableName() string {
+	return "transactions"
+}
+
+type CadenceTemplate struct {
+	ID           uint64 `gorm:"column:id;primaryKey"`
+	ComputeLimit uint64 `gorm:"column:compute_limit"`
+}
+
+func (CadenceTemplate) TableName() string {
+	return "cadence_templates"
+}
+
+var transactionColumns = []string{"ComputeLimit", "ExecutionEffort"}
+
+func Migrate(tx *gorm.DB) error {
+	for _, c := range transactionColumns {
+		if err := tx.Migrator().AddColumn(&Transaction{}, c); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Migrator().AddColumn(&CadenceTemplate{}, "ComputeLimit"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropColumn(&CadenceTemplate{}, "ComputeLimit"); err != nil {
+		return err
+	}
+
+	for _, c := range transactionColumns {
+		if err := tx.Migrator().DropColumn(&Transaction{}, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
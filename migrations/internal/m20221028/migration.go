@@ -0,0 +1,87 @@
+// m20221028 adds metadata columns to accounts and jobs and handles AccountLabel migration
+// NOTE: External IDs and labels link accounts to records of external systems.
+// Account labels index the top-level labels of accounts for filtering
+package m20221028
+
+import (
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const ID = "20221028"
+
+type Account struct {
+	Address    string         `gorm:"primaryKey"`
+	ExternalID *string        `gorm:"uniqueIndex"`
+	Labels     datatypes.JSON `gorm:"column:labels"`
+}
+
+func (Account) TableName() string {
+	return "accounts"
+}
+
+type AccountLabel struct {
+	ID             uint64 `gorm:"column:id;primaryKey"`
+	AccountAddress string `gorm:"column:account_address;index;not null"`
+	Key            string `gorm:"column:label_key;index:idx_account_labels_key_value;not null"`
+	Value          string `gorm:"column:label_value;index:idx_account_labels_key_value"`
+}
+
+func (AccountLabel) TableName() string {
+	return "account_labels"
+}
+
+type Job struct {
+	ID       string         `gorm:"column:id;primary_key;type:uuid;"`
+	Metadata datatypes.JSON `gorm:"column:metadata"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.Migrator().AddColumn(&Account{}, "ExternalID"); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().CreateIndex(&Account{}, "ExternalID"); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().AddColumn(&Account{}, "Labels"); err != nil {
+		return err
+	}
+
+	if err := tx.AutoMigrate(&AccountLabel{}); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().AddColumn(&Job{}, "Metadata"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropColumn(&Job{}, "Metadata"); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().DropTable(&AccountLabel{}); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().DropIndex(&Account{}, "ExternalID"); err != nil {
+		return err
+	}
+
+	for _, c := range []string{"ExternalID", "Labels"} {
+		if err := tx.Migrator().DropColumn(&Account{}, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
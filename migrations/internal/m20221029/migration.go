@@ -0,0 +1,28 @@
+// m20221029 adds a closed at column to accounts
+// NOTE: Closed accounts have been decommissioned and can not authorize new transactions
+package m20221029
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221029"
+
+type Account struct {
+	Address  string     `gorm:"primaryKey"`
+	ClosedAt *time.Time `gorm:"column:closed_at"`
+}
+
+func (Account) TableName() string {
+	return "accounts"
+}
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Migrator().AddColumn(&Account{}, "ClosedAt")
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Migrator().DropColumn(&Account{}, "ClosedAt")
+}
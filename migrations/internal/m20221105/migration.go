@@ -0,0 +1,43 @@
+// m20221105 handles BalanceSnapshot migration
+// NOTE: Balance snapshots record the fungible token balance of an account at
+// a block height so balances at past dates can be queried
+package m20221105
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221105"
+
+type BalanceSnapshot struct {
+	ID             uint64    `gorm:"column:id;primaryKey"`
+	AccountAddress string    `gorm:"column:account_address;uniqueIndex:idx_balance_snapshots_account_token_height;not null"`
+	TokenName      string    `gorm:"column:token_name;uniqueIndex:idx_balance_snapshots_account_token_height;not null"`
+	BlockHeight    uint64    `gorm:"column:block_height;uniqueIndex:idx_balance_snapshots_account_token_height;not null"`
+	BlockTimestamp time.Time `gorm:"column:block_timestamp;index"`
+	Balance        string    `gorm:"column:balance"`
+	Source         string    `gorm:"column:source"`
+	CreatedAt      time.Time `gorm:"column:created_at"`
+}
+
+func (BalanceSnapshot) TableName() string {
+	return "balance_snapshots"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&BalanceSnapshot{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&BalanceSnapshot{}); err != nil {
+		return err
+	}
+
+	return nil
+}
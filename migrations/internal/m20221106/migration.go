@@ -0,0 +1,62 @@
+// m20221106 handles reconciliation migration
+// NOTE: Reconciliation checkpoints record the last block height at which the
+// recorded token transfers of an account matched its on-chain balance, and
+// discrepancies record where they did not
+package m20221106
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221106"
+
+type ReconciliationCheckpoint struct {
+	ID             uint64    `gorm:"column:id;primaryKey"`
+	AccountAddress string    `gorm:"column:account_address;uniqueIndex:idx_reconciliation_checkpoints_account_token;not null"`
+	TokenName      string    `gorm:"column:token_name;uniqueIndex:idx_reconciliation_checkpoints_account_token;not null"`
+	BlockHeight    uint64    `gorm:"column:block_height"`
+	Balance        string    `gorm:"column:balance"`
+	CreatedAt      time.Time `gorm:"column:created_at"`
+	UpdatedAt      time.Time `gorm:"column:updated_at"`
+}
+
+func (ReconciliationCheckpoint) TableName() string {
+	return "reconciliation_checkpoints"
+}
+
+type ReconciliationDiscrepancy struct {
+	ID                 uint64     `gorm:"column:id;primaryKey"`
+	AccountAddress     string     `gorm:"column:account_address;index:idx_reconciliation_discrepancies_account_token;not null"`
+	TokenName          string     `gorm:"column:token_name;index:idx_reconciliation_discrepancies_account_token;not null"`
+	FromHeight         uint64     `gorm:"column:from_height"`
+	ToHeight           uint64     `gorm:"column:to_height"`
+	ExpectedBalance    string     `gorm:"column:expected_balance"`
+	ActualBalance      string     `gorm:"column:actual_balance"`
+	Difference         string     `gorm:"column:difference"`
+	BackfilledDeposits int        `gorm:"column:backfilled_deposits"`
+	ResolvedAt         *time.Time `gorm:"column:resolved_at;index"`
+	CreatedAt          time.Time  `gorm:"column:created_at"`
+	UpdatedAt          time.Time  `gorm:"column:updated_at"`
+}
+
+func (ReconciliationDiscrepancy) TableName() string {
+	return "reconciliation_discrepancies"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&ReconciliationCheckpoint{}, &ReconciliationDiscrepancy{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&ReconciliationCheckpoint{}, &ReconciliationDiscrepancy{}); err != nil {
+		return err
+	}
+
+	return nil
+}
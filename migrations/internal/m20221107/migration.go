@@ -0,0 +1,42 @@
+// m20221107 handles NFT metadata cache migration
+// NOTE: The cache stores the resolved metadata views of NFTs so listing the
+// NFTs of an account does not resolve them on chain on every request
+package m20221107
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221107"
+
+type CachedNftItem struct {
+	ID           uint64    `gorm:"column:id;primaryKey"`
+	TokenName    string    `gorm:"column:token_name;uniqueIndex:idx_nft_metadata_cache_token_nft;not null"`
+	NftID        uint64    `gorm:"column:nft_id;uniqueIndex:idx_nft_metadata_cache_token_nft;not null"`
+	OwnerAddress string    `gorm:"column:owner_address"`
+	Item         string    `gorm:"column:item"`
+	CreatedAt    time.Time `gorm:"column:created_at"`
+	UpdatedAt    time.Time `gorm:"column:updated_at"`
+}
+
+func (CachedNftItem) TableName() string {
+	return "nft_metadata_cache"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&CachedNftItem{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&CachedNftItem{}); err != nil {
+		return err
+	}
+
+	return nil
+}
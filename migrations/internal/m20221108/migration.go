@@ -0,0 +1,42 @@
+// m20221108 handles SequenceNumberAllocation migration
+// NOTE: SequenceNumberAllocations record tracked proposal key sequence numbers
+// which may still be in flight or which have been released for reuse
+package m20221108
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221108"
+
+type SequenceNumberAllocation struct {
+	ID        int    `gorm:"primaryKey"`
+	Address   string `gorm:"index:idx_sequence_number_allocations_address_key_index;not null"`
+	KeyIndex  int    `gorm:"index:idx_sequence_number_allocations_address_key_index;not null"`
+	Number    uint64
+	Released  bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (SequenceNumberAllocation) TableName() string {
+	return "sequence_number_allocations"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&SequenceNumberAllocation{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&SequenceNumberAllocation{}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -12,6 +12,21 @@ import (
 	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20211221_2"
 	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20220212"
 	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221001"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221019"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221020"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221021"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221022"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221023"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221024"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221025"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221026"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221027"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221028"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221029"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221105"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221106"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221107"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221108"
 	"github.com/go-gormigrate/gormigrate/v2"
 )
 
@@ -72,6 +87,81 @@ func List() []*gormigrate.Migration {
 			Migrate:  m20221001.Migrate,
 			Rollback: m20221001.Rollback,
 		},
+		{
+			ID:       m20221019.ID,
+			Migrate:  m20221019.Migrate,
+			Rollback: m20221019.Rollback,
+		},
+		{
+			ID:       m20221020.ID,
+			Migrate:  m20221020.Migrate,
+			Rollback: m20221020.Rollback,
+		},
+		{
+			ID:       m20221021.ID,
+			Migrate:  m20221021.Migrate,
+			Rollback: m20221021.Rollback,
+		},
+		{
+			ID:       m20221022.ID,
+			Migrate:  m20221022.Migrate,
+			Rollback: m20221022.Rollback,
+		},
+		{
+			ID:       m20221023.ID,
+			Migrate:  m20221023.Migrate,
+			Rollback: m20221023.Rollback,
+		},
+		{
+			ID:       m20221024.ID,
+			Migrate:  m20221024.Migrate,
+			Rollback: m20221024.Rollback,
+		},
+		{
+			ID:       m20221025.ID,
+			Migrate:  m20221025.Migrate,
+			Rollback: m20221025.Rollback,
+		},
+		{
+			ID:       m20221026.ID,
+			Migrate:  m20221026.Migrate,
+			Rollback: m20221026.Rollback,
+		},
+		{
+			ID:       m20221027.ID,
+			Migrate:  m20221027.Migrate,
+			Rollback: m20221027.Rollback,
+		},
+		{
+			ID:       m20221028.ID,
+			Migrate:  m20221028.Migrate,
+			Rollback: m20221028.Rollback,
+		},
+		{
+			ID:       m20221029.ID,
+			Migrate:  m20221029.Migrate,
+			Rollback: m20221029.Rollback,
+		},
+		{
+			ID:       m20221105.ID,
+			Migrate:  m20221105.Migrate,
+			Rollback: m20221105.Rollback,
+		},
+		{
+			ID:       m20221106.ID,
+			Migrate:  m20221106.Migrate,
+			Rollback: m20221106.Rollback,
+		},
+		{
+			ID:       m20221107.ID,
+			Migrate:  m20221107.Migrate,
+			Rollback: m20221107.Rollback,
+		},
+		{
+			ID:       m20221108.ID,
+			Migrate:  m20221108.Migrate,
+			Rollback: m20221108.Rollback,
+		},
 	}
 	return ms
 }
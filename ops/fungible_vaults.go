@@ -102,7 +102,7 @@ func (s *ServiceImpl) InitMissingFungibleTokenVaults() (string, error) {
 					}
 
 					// blocks until transaction is sealed
-					_, tx, err := s.txs.Create(context.Background(), true, address, txScript, nil, transactions.FtSetup)
+					_, tx, err := s.txs.Create(context.Background(), true, address, txScript, nil, transactions.FtSetup, transactions.TrustedCode())
 					if err != nil {
 						return err
 					}
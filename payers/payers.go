@@ -0,0 +1,61 @@
+// Package payers provides a pool of custodial accounts used to pay for
+// transaction fees instead of the admin account.
+package payers
+
+import (
+	"time"
+
+	"github.com/onflow/cadence"
+)
+
+// Payer selection strategies.
+const (
+	StrategyRoundRobin        = "round-robin"
+	StrategyLeastRecentlyUsed = "least-recently-used"
+	StrategyBalance           = "balance"
+)
+
+// Payer struct represents a storable payer pool account.
+// Balance is the FLOW balance of the account as of the latest balance check.
+// Excluded payers are not used until their balance is above the configured threshold again.
+type Payer struct {
+	ID         int    `gorm:"primaryKey"`
+	Address    string `gorm:"uniqueIndex;not null"`
+	Balance    uint64
+	Excluded   bool `gorm:"default:false"`
+	LastUsedAt time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (Payer) TableName() string {
+	return "payers"
+}
+
+// Convert to JSON version
+func (p *Payer) ToJSON() PayerJSON {
+	return PayerJSON{
+		Address:    p.Address,
+		Balance:    cadence.UFix64(p.Balance).String(),
+		Excluded:   p.Excluded,
+		LastUsedAt: p.LastUsedAt,
+		CreatedAt:  p.CreatedAt,
+	}
+}
+
+type PayerJSON struct {
+	Address    string    `json:"address"`
+	Balance    string    `json:"balance"`
+	Excluded   bool      `json:"excluded"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// IsValidStrategy returns true if the given payer selection strategy is supported.
+func IsValidStrategy(strategy string) bool {
+	switch strategy {
+	case StrategyRoundRobin, StrategyLeastRecentlyUsed, StrategyBalance:
+		return true
+	}
+	return false
+}
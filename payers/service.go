@@ -0,0 +1,367 @@
+package payers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+)
+
+type Service interface {
+	List() ([]Payer, error)
+	Details(address string) (*Payer, error)
+	Add(ctx context.Context, address string) (*Payer, error)
+	Remove(address string) error
+	// PayerAuthorizer returns an Authorizer for the next payer of the pool
+	// according to the configured strategy. The admin account is used if
+	// there are no available payers.
+	PayerAuthorizer(ctx context.Context) (keys.Authorizer, error)
+	// CheckBalances updates the balances of all payers and excludes payers
+	// whose balance is below the configured threshold. Payers whose balance
+	// can not be read are skipped.
+	CheckBalances(ctx context.Context) error
+	// Start periodic balance checks.
+	Start()
+	// Stop periodic balance checks.
+	Stop()
+}
+
+// ServiceImpl defines the API for payer pool management.
+type ServiceImpl struct {
+	cfg        *configs.Config
+	store      Store
+	km         keys.Manager
+	fc         flow_helpers.FlowClient
+	accounts   accounts.Store
+	minBalance uint64
+	counter    uint64
+	// Number of available payers as of the latest check, -1 if unknown
+	available   int64
+	ticker      *time.Ticker
+	stopChan    chan struct{}
+	stopOnce    sync.Once
+	tickerMutex sync.Mutex
+}
+
+// NewService initiates a new payer pool service.
+func NewService(
+	cfg *configs.Config,
+	store Store,
+	km keys.Manager,
+	fc flow_helpers.FlowClient,
+	accountStore accounts.Store,
+) Service {
+	// TODO(latenssi): safeguard against nil config?
+
+	if !IsValidStrategy(cfg.PayerPoolStrategy) {
+		panic(fmt.Sprintf("unknown payer pool strategy: %s", cfg.PayerPoolStrategy))
+	}
+
+	minBalance, err := cadence.NewUFix64(cfg.PayerPoolMinBalance)
+	if err != nil {
+		panic(fmt.Sprintf("invalid payer pool min balance: %s", err))
+	}
+
+	return &ServiceImpl{
+		cfg:        cfg,
+		store:      store,
+		km:         km,
+		fc:         fc,
+		accounts:   accountStore,
+		minBalance: uint64(minBalance),
+		available:  -1,
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// List returns all payers of the pool.
+func (s *ServiceImpl) List() ([]Payer, error) {
+	return s.store.Payers()
+}
+
+// Details returns a specific payer.
+func (s *ServiceImpl) Details(address string) (*Payer, error) {
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.store.Payer(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Add adds a custodial account to the payer pool. Each key set of the
+// account has to be able to sign transaction envelopes on its own, i.e. its
+// weight has to meet the signing threshold.
+func (s *ServiceImpl) Add(ctx context.Context, address string) (*Payer, error) {
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	if flow.HexToAddress(address) == flow.HexToAddress(s.cfg.AdminAddress) {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("admin account is always used as fallback payer and can not be added to the pool"),
+		}
+	}
+
+	if _, err := s.store.Payer(address); err == nil {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("payer already exists"),
+		}
+	}
+
+	account, err := s.accounts.Account(address)
+	if err != nil || account.Type != accounts.AccountTypeCustodial || len(account.Keys) == 0 {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("payer has to be a custodial account"),
+		}
+	}
+
+	if account.IsClosed() {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("payer account is closed"),
+		}
+	}
+
+	for _, ks := range keys.GroupKeySets(account.Keys) {
+		if ks.Weight < flow.AccountKeyWeightThreshold {
+			return nil, &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("payer keys have to meet the signing threshold on their own, key weight: %d", ks.Weight),
+			}
+		}
+	}
+
+	balance, err := s.balance(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Payer{
+		Address:    address,
+		Balance:    balance,
+		Excluded:   balance < s.minBalance,
+		LastUsedAt: time.Now(),
+	}
+
+	if err := s.store.InsertPayer(p); err != nil {
+		return nil, err
+	}
+
+	s.resetAvailable()
+
+	log.
+		WithFields(log.Fields{"address": address, "excluded": p.Excluded}).
+		Info("Payer added")
+
+	return p, nil
+}
+
+// Remove removes a payer from the pool. The account itself is not affected.
+func (s *ServiceImpl) Remove(address string) error {
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.store.Payer(address); err != nil {
+		return err
+	}
+
+	if err := s.store.DeletePayer(address); err != nil {
+		return err
+	}
+
+	s.resetAvailable()
+
+	return nil
+}
+
+func (s *ServiceImpl) PayerAuthorizer(ctx context.Context) (keys.Authorizer, error) {
+	// Skip the database if there were no available payers on the latest check
+	if atomic.LoadInt64(&s.available) == 0 {
+		log.Trace("No available payers, using admin account as payer")
+		return s.km.AdminAuthorizer(ctx)
+	}
+
+	p, err := s.next()
+	if err != nil {
+		return keys.Authorizer{}, fmt.Errorf("error while getting next payer: %w", err)
+	}
+
+	if p == nil {
+		atomic.StoreInt64(&s.available, 0)
+		log.Trace("No available payers, using admin account as payer")
+		return s.km.AdminAuthorizer(ctx)
+	}
+
+	log.WithFields(log.Fields{"address": p.Address}).Trace("Using payer from pool")
+
+	return s.km.PayerAuthorizer(ctx, flow.HexToAddress(p.Address))
+}
+
+// next selects the next available payer using the configured strategy.
+func (s *ServiceImpl) next() (*Payer, error) {
+	switch s.cfg.PayerPoolStrategy {
+	case StrategyLeastRecentlyUsed:
+		return s.store.NextPayer("last_used_at asc", 1)
+	case StrategyBalance:
+		// Rotate among the top payers so a single payer is not used for
+		// every transaction until the next balance check
+		return s.store.NextPayer("balance desc", s.cfg.PayerPoolBalanceTop)
+	default:
+		pp, err := s.store.AvailablePayers()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(pp) == 0 {
+			return nil, nil
+		}
+
+		p := pp[(atomic.AddUint64(&s.counter, 1)-1)%uint64(len(pp))]
+
+		if err := s.store.TouchPayer(p.Address); err != nil {
+			return nil, err
+		}
+
+		return &p, nil
+	}
+}
+
+func (s *ServiceImpl) CheckBalances(ctx context.Context) error {
+	pp, err := s.store.Payers()
+	if err != nil {
+		return err
+	}
+
+	available := int64(0)
+	failed := 0
+
+	for _, p := range pp {
+		balance, err := s.balance(ctx, p.Address)
+		if err != nil {
+			// Keep the previous state of the payer, the rest of the pool is
+			// still checked
+			log.
+				WithFields(log.Fields{"address": p.Address, "error": err}).
+				Warn("Could not check payer balance")
+			failed++
+			continue
+		}
+
+		excluded := balance < s.minBalance
+
+		entry := log.WithFields(log.Fields{
+			"address":    p.Address,
+			"balance":    cadence.UFix64(balance).String(),
+			"minBalance": cadence.UFix64(s.minBalance).String(),
+		})
+
+		if excluded && !p.Excluded {
+			entry.Warn("Payer balance below threshold, excluding payer from pool")
+		} else if !excluded && p.Excluded {
+			entry.Info("Payer balance above threshold, including payer in pool")
+		}
+
+		if err := s.store.UpdateBalance(p.Address, balance, excluded); err != nil {
+			return err
+		}
+
+		if !excluded {
+			available++
+		}
+	}
+
+	if available == 0 && failed > 0 {
+		// Payers which could not be checked may still be available
+		s.resetAvailable()
+		return nil
+	}
+
+	atomic.StoreInt64(&s.available, available)
+
+	return nil
+}
+
+// resetAvailable makes the next payer selection check the database for
+// available payers.
+func (s *ServiceImpl) resetAvailable() {
+	atomic.StoreInt64(&s.available, -1)
+}
+
+func (s *ServiceImpl) Start() {
+	s.tickerMutex.Lock()
+	defer s.tickerMutex.Unlock()
+
+	if s.ticker != nil {
+		// Already started
+		return
+	}
+
+	s.ticker = time.NewTicker(s.cfg.PayerPoolBalanceCheckInterval)
+
+	go func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		entry := log.WithFields(log.Fields{
+			"package":  "payers",
+			"function": "Service.Start.goroutine",
+		})
+
+		for {
+			select {
+			case <-s.stopChan:
+				return
+			case <-s.ticker.C:
+				if err := s.CheckBalances(ctx); err != nil {
+					entry.
+						WithFields(log.Fields{"error": err}).
+						Warn("Error while checking payer balances")
+				}
+			}
+		}
+	}()
+}
+
+func (s *ServiceImpl) Stop() {
+	s.tickerMutex.Lock()
+	defer s.tickerMutex.Unlock()
+
+	s.stopOnce.Do(func() {
+		close(s.stopChan)
+	})
+
+	if s.ticker != nil {
+		s.ticker.Stop()
+	}
+}
+
+func (s *ServiceImpl) balance(ctx context.Context, address string) (uint64, error) {
+	acc, err := s.fc.GetAccount(ctx, flow.HexToAddress(address))
+	if err != nil {
+		return 0, err
+	}
+	return acc.Balance, nil
+}
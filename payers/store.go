@@ -0,0 +1,30 @@
+package payers
+
+// Store manages data regarding payers.
+type Store interface {
+	// List all payers.
+	Payers() ([]Payer, error)
+
+	// Get payer details.
+	Payer(address string) (Payer, error)
+
+	// Insert a new payer.
+	InsertPayer(p *Payer) error
+
+	// Permanently delete a payer.
+	DeletePayer(address string) error
+
+	// List payers which are not excluded, ordered by ID.
+	AvailablePayers() ([]Payer, error)
+
+	// Get the least recently used of the first candidates payers which are
+	// not excluded using the given order and mark it as used. Returns nil if
+	// there are no available payers.
+	NextPayer(order string, candidates int) (*Payer, error)
+
+	// Mark the given payer as used.
+	TouchPayer(address string) error
+
+	// Update the balance and exclusion status of a payer.
+	UpdateBalance(address string, balance uint64, excluded bool) error
+}
@@ -0,0 +1,93 @@
+package payers
+
+import (
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/flow-hydraulics/flow-wallet-api/datastore/lib"
+)
+
+type GormStore struct {
+	payerMutex sync.Mutex
+	db         *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) Store {
+	return &GormStore{db: db}
+}
+
+func (s *GormStore) Payers() (pp []Payer, err error) {
+	err = s.db.Order("id asc").Find(&pp).Error
+	return
+}
+
+func (s *GormStore) Payer(address string) (p Payer, err error) {
+	err = s.db.First(&p, "address = ?", address).Error
+	return
+}
+
+func (s *GormStore) InsertPayer(p *Payer) error {
+	return s.db.Create(p).Error
+}
+
+func (s *GormStore) DeletePayer(address string) error {
+	return s.db.Where("address = ?", address).Delete(&Payer{}).Error
+}
+
+func (s *GormStore) AvailablePayers() (pp []Payer, err error) {
+	err = s.db.Where("excluded = ?", false).Order("id asc").Find(&pp).Error
+	return
+}
+
+func (s *GormStore) NextPayer(order string, candidates int) (*Payer, error) {
+	if candidates < 1 {
+		candidates = 1
+	}
+
+	s.payerMutex.Lock()
+	defer s.payerMutex.Unlock()
+
+	var p *Payer
+
+	err := lib.GormTransaction(s.db, func(tx *gorm.DB) error {
+		pp := []Payer{}
+
+		if err := tx.
+			// NOWAIT so this call will fail rather than use a stale value
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
+			Where("excluded = ?", false).
+			Order(order).
+			Limit(candidates).Find(&pp).Error; err != nil {
+			return err
+		}
+
+		if len(pp) == 0 {
+			return nil
+		}
+
+		p = &pp[0]
+		for i := range pp[1:] {
+			if pp[i+1].LastUsedAt.Before(p.LastUsedAt) {
+				p = &pp[i+1]
+			}
+		}
+
+		return tx.Model(p).Update("last_used_at", time.Now()).Error
+	})
+
+	return p, err
+}
+
+func (s *GormStore) TouchPayer(address string) error {
+	return s.db.Model(&Payer{}).Where("address = ?", address).Update("last_used_at", time.Now()).Error
+}
+
+func (s *GormStore) UpdateBalance(address string, balance uint64, excluded bool) error {
+	return s.db.Model(&Payer{}).Where("address = ?", address).Updates(map[string]interface{}{
+		"balance":  balance,
+		"excluded": excluded,
+	}).Error
+}
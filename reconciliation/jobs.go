@@ -0,0 +1,39 @@
+package reconciliation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+)
+
+const ReconciliationJobType = "reconciliation"
+
+func (s *ServiceImpl) executeReconciliationJob(ctx context.Context, j *jobs.Job) error {
+	if j.Type != ReconciliationJobType {
+		return jobs.ErrInvalidJobType
+	}
+
+	j.ShouldSendNotification = true
+
+	var req Request
+	if err := json.Unmarshal(j.Attributes, &req); err != nil {
+		return err
+	}
+
+	result, err := s.reconcile(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	j.Result = fmt.Sprintf("%d discrepancies", len(result.Discrepancies))
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	j.Metadata = b
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package reconciliation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Reconciler schedules reconciliation jobs of all accounts in the
+// background.
+type Reconciler struct {
+	svc      Service
+	interval time.Duration
+	backfill bool
+
+	ticker      *time.Ticker
+	tickerMutex sync.Mutex
+	stopChan    chan struct{}
+	stopOnce    sync.Once
+}
+
+// NewReconciler initiates a new reconciler scheduling a job every interval.
+func NewReconciler(svc Service, interval time.Duration, backfill bool) *Reconciler {
+	return &Reconciler{
+		svc:      svc,
+		interval: interval,
+		backfill: backfill,
+		stopChan: make(chan struct{}),
+	}
+}
+
+func (r *Reconciler) Start() {
+	r.tickerMutex.Lock()
+	defer r.tickerMutex.Unlock()
+
+	if r.ticker != nil {
+		// Already started
+		return
+	}
+
+	r.ticker = time.NewTicker(r.interval)
+
+	go func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		entry := log.WithFields(log.Fields{
+			"package":  "reconciliation",
+			"function": "Reconciler.Start.goroutine",
+		})
+
+		for {
+			select {
+			case <-r.stopChan:
+				return
+			case <-r.ticker.C:
+				job, _, err := r.svc.Reconcile(ctx, false, Request{Backfill: r.backfill})
+				if err != nil {
+					entry.
+						WithFields(log.Fields{"error": err}).
+						Warn("Error while scheduling reconciliation")
+					continue
+				}
+				entry.
+					WithFields(log.Fields{"jobId": job.ID}).
+					Debug("Scheduled reconciliation")
+			}
+		}
+	}()
+}
+
+func (r *Reconciler) Stop() {
+	r.tickerMutex.Lock()
+	defer r.tickerMutex.Unlock()
+
+	r.stopOnce.Do(func() {
+		close(r.stopChan)
+	})
+
+	if r.ticker != nil {
+		r.ticker.Stop()
+	}
+}
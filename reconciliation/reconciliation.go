@@ -0,0 +1,118 @@
+// Package reconciliation provides functions for checking the recorded token
+// transfers of accounts against their on-chain balances.
+package reconciliation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/onflow/cadence"
+)
+
+// Request describes which accounts are reconciled and how.
+type Request struct {
+	// Reconcile a single account. All accounts are reconciled if empty.
+	Address string `json:"address,omitempty"`
+	// Rescan the blocks of a discrepancy for deposits missed by the chain
+	// events listener and register them.
+	Backfill bool `json:"backfill"`
+}
+
+// Checkpoint is the last block height at which the recorded transfers of a
+// fungible token of an account matched its on-chain balance.
+type Checkpoint struct {
+	ID             uint64    `gorm:"column:id;primaryKey"`
+	AccountAddress string    `gorm:"column:account_address;uniqueIndex:idx_reconciliation_checkpoints_account_token;not null"`
+	TokenName      string    `gorm:"column:token_name;uniqueIndex:idx_reconciliation_checkpoints_account_token;not null"`
+	BlockHeight    uint64    `gorm:"column:block_height"`
+	Balance        string    `gorm:"column:balance"`
+	CreatedAt      time.Time `gorm:"column:created_at"`
+	UpdatedAt      time.Time `gorm:"column:updated_at"`
+}
+
+func (Checkpoint) TableName() string {
+	return "reconciliation_checkpoints"
+}
+
+// Discrepancy is a mismatch between the on-chain balance of a fungible token
+// of an account and the balance expected from its checkpoint and the
+// transfers recorded since. An unresolved discrepancy is updated by later
+// reconciliations and resolved once the balances match again.
+type Discrepancy struct {
+	ID                 uint64     `json:"id" gorm:"column:id;primaryKey"`
+	AccountAddress     string     `json:"address" gorm:"column:account_address;index:idx_reconciliation_discrepancies_account_token;not null"`
+	TokenName          string     `json:"token" gorm:"column:token_name;index:idx_reconciliation_discrepancies_account_token;not null"`
+	FromHeight         uint64     `json:"fromHeight" gorm:"column:from_height"`
+	ToHeight           uint64     `json:"toHeight" gorm:"column:to_height"`
+	ExpectedBalance    string     `json:"expectedBalance" gorm:"column:expected_balance"`
+	ActualBalance      string     `json:"actualBalance" gorm:"column:actual_balance"`
+	Difference         string     `json:"difference" gorm:"column:difference"`
+	BackfilledDeposits int        `json:"backfilledDeposits" gorm:"column:backfilled_deposits"`
+	ResolvedAt         *time.Time `json:"resolvedAt" gorm:"column:resolved_at;index"`
+	CreatedAt          time.Time  `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt          time.Time  `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+func (Discrepancy) TableName() string {
+	return "reconciliation_discrepancies"
+}
+
+// Transfer is a recorded fungible token transfer of an account.
+type Transfer struct {
+	SenderAddress    string
+	RecipientAddress string
+	FtAmount         string
+	BlockHeight      uint64
+}
+
+// FailedAccount is an account token which could not be reconciled.
+type FailedAccount struct {
+	Address   string `json:"address"`
+	TokenName string `json:"token"`
+	Error     string `json:"error"`
+}
+
+// Result is the outcome of a reconciliation. It is also stored as the
+// metadata of the reconciliation job.
+type Result struct {
+	BlockHeight uint64 `json:"blockHeight"`
+	// Number of account tokens checked.
+	Checked int `json:"checked"`
+	// Number of account tokens seen for the first time, whose on-chain
+	// balance was taken as their first checkpoint.
+	Initialized int `json:"initialized"`
+	// Number of account tokens whose balances matched.
+	Reconciled int `json:"reconciled"`
+	// Number of account tokens skipped because of transfers whose
+	// transaction has not been sealed yet.
+	Pending            int             `json:"pending"`
+	BackfilledDeposits int             `json:"backfilledDeposits"`
+	Discrepancies      []Discrepancy   `json:"discrepancies"`
+	FailedAccounts     []FailedAccount `json:"failedAccounts"`
+}
+
+// netAmount returns the net fixed-point amount transferred to an address.
+func netAmount(address string, transfers []Transfer) (int64, error) {
+	var net int64
+	for _, t := range transfers {
+		amount, err := cadence.NewUFix64(t.FtAmount)
+		if err != nil {
+			return 0, fmt.Errorf("invalid transfer amount %q: %w", t.FtAmount, err)
+		}
+		if t.RecipientAddress == address {
+			net += int64(amount)
+		}
+		if t.SenderAddress == address {
+			net -= int64(amount)
+		}
+	}
+	return net, nil
+}
+
+// formatAmount formats a signed fixed-point amount like a UFix64.
+func formatAmount(amount int64) string {
+	if amount < 0 {
+		return "-" + cadence.UFix64(-amount).String()
+	}
+	return cadence.UFix64(amount).String()
+}
@@ -0,0 +1,36 @@
+package reconciliation
+
+import "testing"
+
+func TestNetAmount(t *testing.T) {
+	address := "0x01cf0e2f2f715450"
+	other := "0x179b6b1cb6755e31"
+
+	net, err := netAmount(address, []Transfer{
+		{SenderAddress: other, RecipientAddress: address, FtAmount: "5.00000000"},
+		{SenderAddress: address, RecipientAddress: other, FtAmount: "7.50000000"},
+		{SenderAddress: address, RecipientAddress: address, FtAmount: "1.00000000"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if net != -250000000 {
+		t.Fatalf("expected -2.5, got %s", formatAmount(net))
+	}
+
+	if _, err := netAmount(address, []Transfer{{RecipientAddress: address, FtAmount: "not a number"}}); err == nil {
+		t.Fatal("expected an invalid amount to fail")
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	for amount, expected := range map[int64]string{
+		0:          "0.00000000",
+		150000000:  "1.50000000",
+		-250000000: "-2.50000000",
+	} {
+		if got := formatAmount(amount); got != expected {
+			t.Errorf("expected %s, got %s", expected, got)
+		}
+	}
+}
@@ -0,0 +1,462 @@
+package reconciliation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/payers"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/tokens"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	// Reconcile compares the on-chain fungible token balances of accounts
+	// with their last checkpoint and the transfers recorded since, and
+	// flags the accounts whose balances do not match.
+	Reconcile(ctx context.Context, sync bool, req Request) (*jobs.Job, *Result, error)
+	// Discrepancies lists the discrepancies found, newest first.
+	Discrepancies(limit, offset int, unresolvedOnly bool) ([]Discrepancy, error)
+}
+
+// ServiceImpl defines the API for reconciliation.
+type ServiceImpl struct {
+	cfg        *configs.Config
+	store      Store
+	accounts   accounts.Store
+	payers     payers.Service
+	tokens     tokens.Service
+	tokenStore tokens.Store
+	temps      templates.Service
+	txs        transactions.Service
+	fc         flow_helpers.FlowClient
+	wp         jobs.WorkerPool
+}
+
+// NewService initiates a new reconciliation service.
+func NewService(
+	cfg *configs.Config,
+	store Store,
+	accountStore accounts.Store,
+	pas payers.Service,
+	tos tokens.Service,
+	tokenStore tokens.Store,
+	temps templates.Service,
+	txs transactions.Service,
+	fc flow_helpers.FlowClient,
+	wp jobs.WorkerPool,
+) Service {
+	svc := &ServiceImpl{cfg, store, accountStore, pas, tos, tokenStore, temps, txs, fc, wp}
+
+	if wp == nil {
+		panic("workerpool nil")
+	}
+
+	// Register asynchronous job executor.
+	wp.RegisterExecutor(ReconciliationJobType, svc.executeReconciliationJob)
+
+	return svc
+}
+
+func (s *ServiceImpl) Reconcile(ctx context.Context, sync bool, req Request) (*jobs.Job, *Result, error) {
+	log.WithFields(log.Fields{"sync": sync, "address": req.Address, "backfill": req.Backfill}).Trace("Reconcile")
+
+	if req.Address != "" {
+		address, err := flow_helpers.ValidateAddress(req.Address, s.cfg.ChainID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Make sure the account is known
+		if _, err := s.accounts.Account(address); err != nil {
+			return nil, nil, err
+		}
+
+		req.Address = address
+	}
+
+	if !sync {
+		attrBytes, err := json.Marshal(req)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		job, err := s.wp.CreateJob(ReconciliationJobType, "", jobs.WithAttributes(attrBytes))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := s.wp.Schedule(job); err != nil {
+			return nil, nil, err
+		}
+
+		return job, nil, nil
+	}
+
+	result, err := s.reconcile(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nil, result, nil
+}
+
+func (s *ServiceImpl) Discrepancies(limit, offset int, unresolvedOnly bool) ([]Discrepancy, error) {
+	o := datastore.ParseListOptions(limit, offset)
+	return s.store.Discrepancies(o, unresolvedOnly)
+}
+
+// reconcile reconciles the fungible tokens enabled for the requested
+// accounts at a single block height.
+func (s *ServiceImpl) reconcile(ctx context.Context, req Request) (*Result, error) {
+	height, err := s.reconciliationHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accountTokens, err := s.tokenStore.AccountTokensByType(templates.FT)
+	if err != nil {
+		return nil, err
+	}
+
+	feePayers, err := s.feePayers()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		BlockHeight:    height,
+		Discrepancies:  []Discrepancy{},
+		FailedAccounts: []FailedAccount{},
+	}
+
+	for _, at := range accountTokens {
+		if req.Address != "" && at.AccountAddress != req.Address {
+			continue
+		}
+
+		// FLOW balances of accounts paying transaction fees change without
+		// a recorded transfer
+		if at.TokenName == "FlowToken" && feePayers[at.AccountAddress] {
+			continue
+		}
+
+		token, err := s.temps.GetTokenByName(at.TokenName)
+		if err != nil {
+			// Token has been removed since it was enabled for the account
+			continue
+		}
+
+		result.Checked++
+
+		if err := s.reconcileAccountToken(ctx, height, token, at.AccountAddress, req.Backfill, result); err != nil {
+			if wallet_errors.IsChainConnectionError(err) {
+				return nil, err
+			}
+			result.FailedAccounts = append(result.FailedAccounts, FailedAccount{
+				Address:   at.AccountAddress,
+				TokenName: token.Name,
+				Error:     err.Error(),
+			})
+		}
+	}
+
+	log.
+		WithFields(log.Fields{
+			"blockHeight":   result.BlockHeight,
+			"checked":       result.Checked,
+			"discrepancies": len(result.Discrepancies),
+			"failed":        len(result.FailedAccounts),
+		}).
+		Info("Reconciled token balances")
+
+	return result, nil
+}
+
+// reconciliationHeight returns the latest sealed block height whose deposit
+// events have already been checked by the chain events listener.
+func (s *ServiceImpl) reconciliationHeight(ctx context.Context) (uint64, error) {
+	block, err := s.fc.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return 0, err
+	}
+
+	listenerHeight, ok, err := s.store.ListenerHeight()
+	if err != nil {
+		return 0, err
+	}
+
+	if ok && listenerHeight < block.Height {
+		return listenerHeight, nil
+	}
+
+	return block.Height, nil
+}
+
+// feePayers returns the addresses of the accounts which may pay transaction
+// fees, i.e. the admin account and the payer pool.
+func (s *ServiceImpl) feePayers() (map[string]bool, error) {
+	pp, err := s.payers.List()
+	if err != nil {
+		return nil, err
+	}
+
+	feePayers := map[string]bool{
+		flow_helpers.FormatAddress(flow.HexToAddress(s.cfg.AdminAddress)): true,
+	}
+	for _, p := range pp {
+		feePayers[p.Address] = true
+	}
+
+	return feePayers, nil
+}
+
+func (s *ServiceImpl) reconcileAccountToken(ctx context.Context, height uint64, token *templates.Token, address string, backfill bool, result *Result) error {
+	entry := log.WithFields(log.Fields{"address": address, "token": token.Name, "blockHeight": height})
+
+	checkpoint, err := s.store.Checkpoint(address, token.Name)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// First reconciliation of the account token, take its current
+		// balance as the starting point
+		balance, err := s.balance(ctx, height, token, address)
+		if err != nil {
+			return err
+		}
+
+		checkpoint = Checkpoint{
+			AccountAddress: address,
+			TokenName:      token.Name,
+			BlockHeight:    height,
+			Balance:        cadence.UFix64(balance).String(),
+		}
+		if err := s.store.SaveCheckpoint(&checkpoint); err != nil {
+			return err
+		}
+
+		result.Initialized++
+		return nil
+	}
+
+	if checkpoint.BlockHeight >= height {
+		// Nothing new since the checkpoint
+		result.Reconciled++
+		return nil
+	}
+
+	pending, err := s.store.PendingTransfers(address, token.Name, time.Now().Add(-s.cfg.ReconciliationGracePeriod))
+	if err != nil {
+		return err
+	}
+
+	if pending > 0 {
+		entry.WithFields(log.Fields{"pending": pending}).Debug("Skipping account token with pending transfers")
+		result.Pending++
+		return nil
+	}
+
+	actual, err := s.balance(ctx, height, token, address)
+	if err != nil {
+		return err
+	}
+
+	expected, err := s.expectedBalance(checkpoint, height)
+	if err != nil {
+		return err
+	}
+
+	backfilled := 0
+	if int64(actual) != expected && backfill {
+		backfilled, err = s.backfillDeposits(ctx, token, address, checkpoint.BlockHeight, height)
+		if err != nil {
+			return err
+		}
+		result.BackfilledDeposits += backfilled
+
+		if backfilled > 0 {
+			if expected, err = s.expectedBalance(checkpoint, height); err != nil {
+				return err
+			}
+		}
+	}
+
+	if int64(actual) == expected {
+		checkpoint.BlockHeight = height
+		checkpoint.Balance = actual.String()
+		if err := s.store.SaveCheckpoint(&checkpoint); err != nil {
+			return err
+		}
+
+		if err := s.store.ResolveDiscrepancies(address, token.Name, time.Now()); err != nil {
+			return err
+		}
+
+		result.Reconciled++
+		return nil
+	}
+
+	// Keep a single open discrepancy per account token, spanning from the
+	// last matching checkpoint
+	discrepancy, err := s.store.UnresolvedDiscrepancy(address, token.Name)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		discrepancy = Discrepancy{AccountAddress: address, TokenName: token.Name}
+	}
+
+	discrepancy.FromHeight = checkpoint.BlockHeight
+	discrepancy.ToHeight = height
+	discrepancy.ExpectedBalance = formatAmount(expected)
+	discrepancy.ActualBalance = actual.String()
+	discrepancy.Difference = formatAmount(int64(actual) - expected)
+	discrepancy.BackfilledDeposits += backfilled
+
+	if err := s.store.SaveDiscrepancy(&discrepancy); err != nil {
+		return err
+	}
+
+	entry.
+		WithFields(log.Fields{"expected": discrepancy.ExpectedBalance, "actual": discrepancy.ActualBalance}).
+		Warn("Token balance does not match recorded transfers")
+
+	result.Discrepancies = append(result.Discrepancies, discrepancy)
+
+	return nil
+}
+
+// expectedBalance returns the balance of the checkpoint plus the net amount
+// of the transfers recorded since, in fixed-point representation.
+func (s *ServiceImpl) expectedBalance(checkpoint Checkpoint, height uint64) (int64, error) {
+	balance, err := cadence.NewUFix64(checkpoint.Balance)
+	if err != nil {
+		return 0, fmt.Errorf("invalid checkpoint balance %q: %w", checkpoint.Balance, err)
+	}
+
+	transfers, err := s.store.Transfers(checkpoint.AccountAddress, checkpoint.TokenName, checkpoint.BlockHeight, height)
+	if err != nil {
+		return 0, err
+	}
+
+	net, err := netAmount(checkpoint.AccountAddress, transfers)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(balance) + net, nil
+}
+
+func (s *ServiceImpl) balance(ctx context.Context, height uint64, token *templates.Token, address string) (cadence.UFix64, error) {
+	value, err := s.txs.ExecuteScriptAtBlock(
+		ctx,
+		token.Balance,
+		[]transactions.Argument{cadence.NewAddress(flow.HexToAddress(address))},
+		transactions.ScriptBlock{Height: height},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	balance, ok := value.(cadence.UFix64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected %s balance type: %s", token.Name, value.Type().ID())
+	}
+
+	return balance, nil
+}
+
+// backfillDeposits rescans the blocks after fromHeight up to and including
+// toHeight for deposits of a token to an account and registers the ones
+// which have not been recorded. Returns the number of deposits registered.
+func (s *ServiceImpl) backfillDeposits(ctx context.Context, token *templates.Token, address string, fromHeight, toHeight uint64) (int, error) {
+	account, err := s.accounts.Account(address)
+	if err != nil {
+		return 0, err
+	}
+
+	eventType := templates.DepositEventTypeFromToken(templates.BasicToken{
+		Name:    token.Name,
+		Address: flow_helpers.FormatAddress(flow.HexToAddress(token.Address)),
+		Type:    token.Type,
+	})
+
+	maxBlocks := s.cfg.ChainListenerMaxBlocks
+	if maxBlocks < 1 {
+		maxBlocks = 1
+	}
+
+	registered := 0
+	for start := fromHeight + 1; start <= toHeight; start += maxBlocks {
+		end := start + maxBlocks - 1
+		if end > toHeight {
+			end = toHeight
+		}
+
+		blockEvents, err := s.fc.GetEventsForHeightRange(ctx, eventType, start, end)
+		if err != nil {
+			return registered, err
+		}
+
+		for _, be := range blockEvents {
+			for _, event := range be.Events {
+				if len(event.Value.Fields) < 2 {
+					continue
+				}
+
+				if recipient, ok := depositRecipient(event.Value.Fields[1]); !ok || recipient != address {
+					continue
+				}
+
+				txID := event.TransactionID.Hex()
+				if _, err := s.tokens.GetDeposit(address, token.Name, txID); err == nil {
+					// Already recorded
+					continue
+				}
+
+				amount := event.Value.Fields[0].String()
+				if err := s.tokens.RegisterDeposit(ctx, token, event.TransactionID, account, amount); err != nil {
+					return registered, err
+				}
+
+				log.
+					WithFields(log.Fields{"address": address, "token": token.Name, "transactionId": txID, "amount": amount}).
+					Info("Backfilled missing deposit")
+
+				registered++
+			}
+		}
+	}
+
+	return registered, nil
+}
+
+// depositRecipient returns the address of the optional recipient field of
+// a deposit event.
+func depositRecipient(v cadence.Value) (string, bool) {
+	if o, ok := v.(cadence.Optional); ok {
+		v = o.Value
+	}
+
+	a, ok := v.(cadence.Address)
+	if !ok {
+		return "", false
+	}
+
+	return flow_helpers.FormatAddress(flow.Address(a)), true
+}
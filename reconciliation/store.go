@@ -0,0 +1,35 @@
+package reconciliation
+
+import (
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+)
+
+// Store manages data regarding reconciliation.
+type Store interface {
+	// Get the checkpoint of a token of an account
+	Checkpoint(address, tokenName string) (Checkpoint, error)
+	// Insert or update a checkpoint
+	SaveCheckpoint(*Checkpoint) error
+
+	// Get the unresolved discrepancy of a token of an account
+	UnresolvedDiscrepancy(address, tokenName string) (Discrepancy, error)
+	// Insert or update a discrepancy
+	SaveDiscrepancy(*Discrepancy) error
+	// Resolve the discrepancies of a token of an account
+	ResolveDiscrepancies(address, tokenName string, at time.Time) error
+	// List discrepancies, newest first
+	Discrepancies(o datastore.ListOptions, unresolvedOnly bool) ([]Discrepancy, error)
+
+	// List the recorded transfers of a token of an account whose transaction
+	// was sealed in a block after fromHeight up to and including toHeight
+	Transfers(address, tokenName string, fromHeight, toHeight uint64) ([]Transfer, error)
+	// Count the recorded transfers of a token of an account created after
+	// since whose transaction has not been sealed yet
+	PendingTransfers(address, tokenName string, since time.Time) (int64, error)
+
+	// Get the latest block height checked by the chain events listener,
+	// returns false if the listener has not run
+	ListenerHeight() (uint64, bool, error)
+}
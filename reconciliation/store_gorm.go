@@ -0,0 +1,96 @@
+package reconciliation
+
+import (
+	"errors"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/chain_events"
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"gorm.io/gorm"
+)
+
+type GormStore struct {
+	db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) Store {
+	return &GormStore{db}
+}
+
+func (s *GormStore) Checkpoint(address, tokenName string) (c Checkpoint, err error) {
+	err = s.db.Where(&Checkpoint{AccountAddress: address, TokenName: tokenName}).First(&c).Error
+	return
+}
+
+func (s *GormStore) SaveCheckpoint(c *Checkpoint) error {
+	return s.db.Save(c).Error
+}
+
+func (s *GormStore) UnresolvedDiscrepancy(address, tokenName string) (d Discrepancy, err error) {
+	err = s.db.
+		Where(&Discrepancy{AccountAddress: address, TokenName: tokenName}).
+		Where("resolved_at IS NULL").
+		Order("id desc").
+		First(&d).Error
+	return
+}
+
+func (s *GormStore) SaveDiscrepancy(d *Discrepancy) error {
+	return s.db.Save(d).Error
+}
+
+func (s *GormStore) ResolveDiscrepancies(address, tokenName string, at time.Time) error {
+	return s.db.Model(&Discrepancy{}).
+		Where(&Discrepancy{AccountAddress: address, TokenName: tokenName}).
+		Where("resolved_at IS NULL").
+		Update("resolved_at", at).Error
+}
+
+func (s *GormStore) Discrepancies(o datastore.ListOptions, unresolvedOnly bool) (dd []Discrepancy, err error) {
+	q := s.db
+	if unresolvedOnly {
+		q = q.Where("resolved_at IS NULL")
+	}
+	err = q.
+		Order("id desc").
+		Limit(o.Limit).
+		Offset(o.Offset).
+		Find(&dd).Error
+	return
+}
+
+func (s *GormStore) transfers(address, tokenName string) *gorm.DB {
+	return s.db.Table("token_transfers").
+		Joins("JOIN transactions ON transactions.transaction_id = token_transfers.transaction_id").
+		Where("token_transfers.deleted_at IS NULL").
+		Where("token_transfers.token_name = ?", tokenName).
+		Where("(token_transfers.sender_address = ? OR token_transfers.recipient_address = ?)", address, address)
+}
+
+func (s *GormStore) Transfers(address, tokenName string, fromHeight, toHeight uint64) (tt []Transfer, err error) {
+	err = s.transfers(address, tokenName).
+		Select("token_transfers.sender_address, token_transfers.recipient_address, token_transfers.ft_amount, transactions.block_height").
+		Where("transactions.block_height > ? AND transactions.block_height <= ?", fromHeight, toHeight).
+		Order("token_transfers.id asc").
+		Scan(&tt).Error
+	return
+}
+
+func (s *GormStore) PendingTransfers(address, tokenName string, since time.Time) (count int64, err error) {
+	err = s.transfers(address, tokenName).
+		Where("(transactions.block_height = 0 OR transactions.block_height IS NULL)").
+		Where("token_transfers.created_at > ?", since).
+		Count(&count).Error
+	return
+}
+
+func (s *GormStore) ListenerHeight() (uint64, bool, error) {
+	var status chain_events.ListenerStatus
+	if err := s.db.First(&status).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return status.LatestHeight, true, nil
+}
@@ -0,0 +1,130 @@
+// Package reports provides reports over the accounts held in custody.
+package reports
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/onflow/cadence"
+)
+
+// LiabilitiesReport holds the fungible token balances and NFT counts of all
+// custodial accounts, read at a single sealed block.
+type LiabilitiesReport struct {
+	BlockHeight       uint64           `json:"blockHeight"`
+	BlockTimestamp    time.Time        `json:"blockTimestamp"`
+	GroupBy           string           `json:"groupBy,omitempty"`
+	FungibleTokens    []TokenLiability `json:"fungibleTokens"`
+	NonFungibleTokens []TokenLiability `json:"nonFungibleTokens"`
+	// Accounts whose balance of a token could not be read, these are not
+	// included in the totals
+	FailedAccounts []FailedAccount `json:"failedAccounts,omitempty"`
+}
+
+// TokenLiability is the total of a token held by custodial accounts. Total
+// is the sum of the balances of a fungible token or the number of NFTs in a
+// collection.
+type TokenLiability struct {
+	TokenName string           `json:"token"`
+	Accounts  int              `json:"accounts"`
+	Total     string           `json:"total"`
+	Groups    []GroupLiability `json:"groups,omitempty"`
+}
+
+// GroupLiability is the total of a token held by the accounts with the same
+// value of the label the report is grouped by. Label is empty for accounts
+// without the label.
+type GroupLiability struct {
+	Label    string `json:"label"`
+	Accounts int    `json:"accounts"`
+	Total    string `json:"total"`
+}
+
+// FailedAccount is an account whose balance of a token could not be read.
+type FailedAccount struct {
+	Address   string `json:"address"`
+	TokenName string `json:"token"`
+	Error     string `json:"error"`
+}
+
+// WriteCSV writes the totals and groups of the report as CSV, one row per
+// token total and one row per group. Accounts whose balance of a token could
+// not be read are counted in a failed row of the token.
+func (r *LiabilitiesReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	failed := make(map[string]int)
+	for _, f := range r.FailedAccounts {
+		failed[f.TokenName]++
+	}
+
+	if err := cw.Write([]string{"type", "token", "scope", "label", "accounts", "total"}); err != nil {
+		return err
+	}
+
+	write := func(tokenType templates.TokenType, ll []TokenLiability) error {
+		for _, l := range ll {
+			if err := cw.Write([]string{tokenType.String(), l.TokenName, "total", "", strconv.Itoa(l.Accounts), l.Total}); err != nil {
+				return err
+			}
+			for _, g := range l.Groups {
+				if err := cw.Write([]string{tokenType.String(), l.TokenName, "group", g.Label, strconv.Itoa(g.Accounts), g.Total}); err != nil {
+					return err
+				}
+			}
+			if n := failed[l.TokenName]; n > 0 {
+				if err := cw.Write([]string{tokenType.String(), l.TokenName, "failed", "", strconv.Itoa(n), ""}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := write(templates.FT, r.FungibleTokens); err != nil {
+		return err
+	}
+
+	if err := write(templates.NFT, r.NonFungibleTokens); err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// tally sums the balances of a token, in total and per group.
+type tally struct {
+	accounts int
+	total    uint64
+	groups   map[string]*tally
+}
+
+func (t *tally) add(group string, amount uint64, grouped bool) {
+	t.accounts++
+	t.total += amount
+
+	if !grouped {
+		return
+	}
+
+	if t.groups == nil {
+		t.groups = make(map[string]*tally)
+	}
+	g, ok := t.groups[group]
+	if !ok {
+		g = &tally{}
+		t.groups[group] = g
+	}
+	g.add("", amount, false)
+}
+
+func formatAmount(tokenType templates.TokenType, amount uint64) string {
+	if tokenType == templates.FT {
+		return cadence.UFix64(amount).String()
+	}
+	return strconv.FormatUint(amount, 10)
+}
@@ -0,0 +1,84 @@
+package reports
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/templates/template_strings"
+)
+
+func TestBatchedBalanceScript(t *testing.T) {
+	t.Run("fungible token", func(t *testing.T) {
+		code, ok := batchedBalanceScript(template_strings.GenericFungibleBalance, templates.FT)
+		if !ok {
+			t.Fatal("expected the balance script to be batched")
+		}
+		if strings.Count(code, "pub fun main(") != 1 {
+			t.Fatal("expected a single main function")
+		}
+		if !strings.Contains(code, "pub fun walletBalanceOf(account: Address)") {
+			t.Error("expected the single account balance function")
+		}
+		if !strings.Contains(code, "pub fun main(addresses: [Address]): [UFix64]") {
+			t.Error("expected a main function taking a list of addresses")
+		}
+		if !strings.Contains(code, "walletBalanceOf(account: address)") {
+			t.Error("expected the single account balance function to be called with its argument label")
+		}
+	})
+
+	t.Run("non-fungible token", func(t *testing.T) {
+		code, ok := batchedBalanceScript("pub fun main(owner: Address): [UInt64] { return [] }", templates.NFT)
+		if !ok {
+			t.Fatal("expected the balance script to be batched")
+		}
+		if !strings.Contains(code, "walletBalanceOf(owner: address).length") {
+			t.Error("expected the batched script to count the NFT IDs")
+		}
+	})
+
+	t.Run("unsupported script", func(t *testing.T) {
+		if _, ok := batchedBalanceScript("pub fun main(a: Address, b: Address): UFix64 { return 0.0 }", templates.FT); ok {
+			t.Error("expected a script with several arguments not to be batched")
+		}
+	})
+}
+
+func TestLiabilitiesCSV(t *testing.T) {
+	ft := &templates.Token{Name: "FlowToken", Type: templates.FT}
+
+	tl := &tally{}
+	tl.add("gold", 150000000, true)
+	tl.add("gold", 50000000, true)
+	tl.add("", 100000000, true)
+
+	r := &LiabilitiesReport{
+		FungibleTokens:    []TokenLiability{tokenLiability(ft, tl)},
+		NonFungibleTokens: []TokenLiability{{TokenName: "ExampleNFT", Accounts: 2, Total: "5"}},
+		FailedAccounts: []FailedAccount{
+			{Address: "0x01cf0e2f2f715450", TokenName: "FlowToken", Error: "vault not found"},
+			{Address: "0x179b6b1cb6755e31", TokenName: "FlowToken", Error: "vault not found"},
+		},
+	}
+
+	var b bytes.Buffer
+	if err := r.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		"type,token,scope,label,accounts,total",
+		"FT,FlowToken,total,,3,3.00000000",
+		"FT,FlowToken,group,,1,1.00000000",
+		"FT,FlowToken,group,gold,2,2.00000000",
+		"FT,FlowToken,failed,,2,",
+		"NFT,ExampleNFT,total,,2,5",
+		"",
+	}, "\n")
+
+	if b.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
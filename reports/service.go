@@ -0,0 +1,317 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/tokens"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+)
+
+// Matches the main function of a balance script taking a single address.
+var balanceScriptMain = regexp.MustCompile(`pub fun main\(\s*(\w+)\s*:\s*Address\s*\)`)
+
+type Service interface {
+	// Liabilities sums the balances of all tokens held by custodial accounts,
+	// optionally grouped by the value of an account label.
+	Liabilities(ctx context.Context, groupBy string) (*LiabilitiesReport, error)
+}
+
+// ServiceImpl defines the API for reports.
+type ServiceImpl struct {
+	cfg      *configs.Config
+	accounts accounts.Store
+	tokens   tokens.Store
+	temps    templates.Service
+	fc       flow_helpers.FlowClient
+}
+
+// NewService initiates a new reports service.
+func NewService(
+	cfg *configs.Config,
+	accountStore accounts.Store,
+	tokenStore tokens.Store,
+	temps templates.Service,
+	fc flow_helpers.FlowClient,
+) Service {
+	return &ServiceImpl{cfg, accountStore, tokenStore, temps, fc}
+}
+
+func (s *ServiceImpl) Liabilities(ctx context.Context, groupBy string) (*LiabilitiesReport, error) {
+	log.WithFields(log.Fields{"groupBy": groupBy}).Trace("Liabilities report")
+
+	if groupBy != "" {
+		// Validate the label key
+		if _, err := accounts.ParseLabelFilter([]string{groupBy}); err != nil {
+			return nil, err
+		}
+	}
+
+	aa, err := s.accounts.Accounts(datastore.ListOptions{Limit: -1}, accounts.ListFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	custodial := make(map[string]accounts.Account, len(aa))
+	for _, a := range aa {
+		if a.Type == accounts.AccountTypeCustodial {
+			custodial[a.Address] = a
+		}
+	}
+
+	// Read all balances at the same block so the totals are consistent
+	block, err := s.fc.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LiabilitiesReport{
+		BlockHeight:    block.Height,
+		BlockTimestamp: block.Timestamp,
+		GroupBy:        groupBy,
+	}
+
+	for _, tokenType := range []templates.TokenType{templates.FT, templates.NFT} {
+		liabilities, failed, err := s.tokenLiabilities(ctx, block.Height, tokenType, custodial, groupBy)
+		if err != nil {
+			return nil, err
+		}
+
+		if tokenType == templates.FT {
+			report.FungibleTokens = liabilities
+		} else {
+			report.NonFungibleTokens = liabilities
+		}
+		report.FailedAccounts = append(report.FailedAccounts, failed...)
+	}
+
+	return report, nil
+}
+
+// tokenLiabilities sums the balances of the tokens of a type enabled for the
+// given accounts.
+func (s *ServiceImpl) tokenLiabilities(ctx context.Context, height uint64, tokenType templates.TokenType, custodial map[string]accounts.Account, groupBy string) ([]TokenLiability, []FailedAccount, error) {
+	accountTokens, err := s.tokens.AccountTokensByType(tokenType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	addresses := make(map[string][]string)
+	tokenNames := []string{}
+	for _, at := range accountTokens {
+		if _, ok := custodial[at.AccountAddress]; !ok {
+			continue
+		}
+		if _, ok := addresses[at.TokenName]; !ok {
+			tokenNames = append(tokenNames, at.TokenName)
+		}
+		addresses[at.TokenName] = append(addresses[at.TokenName], at.AccountAddress)
+	}
+
+	sort.Strings(tokenNames)
+
+	liabilities := make([]TokenLiability, 0, len(tokenNames))
+	failed := []FailedAccount{}
+
+	for _, name := range tokenNames {
+		token, err := s.temps.GetTokenByName(name)
+		if err != nil {
+			log.
+				WithFields(log.Fields{"error": err, "token": name}).
+				Warn("Skipping token missing from liabilities report")
+			continue
+		}
+
+		balances, tokenFailed, err := s.balances(ctx, height, token, addresses[name])
+		if err != nil {
+			return nil, nil, err
+		}
+		failed = append(failed, tokenFailed...)
+
+		t := &tally{}
+		for _, address := range addresses[name] {
+			amount, ok := balances[address]
+			if !ok {
+				continue
+			}
+			label, _ := custodial[address].Label(groupBy)
+			t.add(label, amount, groupBy != "")
+		}
+
+		liabilities = append(liabilities, tokenLiability(token, t))
+	}
+
+	return liabilities, failed, nil
+}
+
+// balances reads the balances of a token of the given accounts at a block
+// height, using one script per batch of accounts. A batch whose script fails,
+// e.g. because one of the accounts has no vault, is read one account at a
+// time.
+func (s *ServiceImpl) balances(ctx context.Context, height uint64, token *templates.Token, addresses []string) (map[string]uint64, []FailedAccount, error) {
+	balances := make(map[string]uint64, len(addresses))
+	failed := []FailedAccount{}
+
+	batchSize := int(s.cfg.ReportBatchSize)
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	batchCode, batched := batchedBalanceScript(token.Balance, token.Type)
+
+	for start := 0; start < len(addresses); start += batchSize {
+		end := start + batchSize
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+		batch := addresses[start:end]
+
+		if batched {
+			amounts, err := s.batchBalances(ctx, height, batchCode, batch)
+			if err == nil {
+				for i, address := range batch {
+					balances[address] = amounts[i]
+				}
+				continue
+			}
+			log.
+				WithFields(log.Fields{"error": err, "token": token.Name}).
+				Debug("Batched balance script failed, reading balances one account at a time")
+		}
+
+		for _, address := range batch {
+			value, err := s.fc.ExecuteScriptAtBlockHeight(ctx, height, []byte(token.Balance), []cadence.Value{cadence.NewAddress(flow.HexToAddress(address))})
+			if err == nil {
+				var amount uint64
+				amount, err = balanceAmount(value)
+				if err == nil {
+					balances[address] = amount
+					continue
+				}
+			}
+
+			if errors.IsChainConnectionError(err) {
+				return nil, nil, err
+			}
+
+			failed = append(failed, FailedAccount{Address: address, TokenName: token.Name, Error: err.Error()})
+		}
+	}
+
+	return balances, failed, nil
+}
+
+func (s *ServiceImpl) batchBalances(ctx context.Context, height uint64, code string, addresses []string) ([]uint64, error) {
+	values := make([]cadence.Value, len(addresses))
+	for i, address := range addresses {
+		values[i] = cadence.NewAddress(flow.HexToAddress(address))
+	}
+
+	value, err := s.fc.ExecuteScriptAtBlockHeight(ctx, height, []byte(code), []cadence.Value{cadence.NewArray(values)})
+	if err != nil {
+		return nil, err
+	}
+
+	arr, ok := value.(cadence.Array)
+	if !ok || len(arr.Values) != len(addresses) {
+		return nil, fmt.Errorf("unexpected batched balance script result")
+	}
+
+	amounts := make([]uint64, len(arr.Values))
+	for i, v := range arr.Values {
+		if amounts[i], err = balanceAmount(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return amounts, nil
+}
+
+// batchedBalanceScript wraps a balance script taking a single address into a
+// script returning the balances of a list of addresses. NFT balance scripts
+// return the IDs in the collection, so the batched script returns their
+// count. Returns false if the script can not be wrapped.
+func batchedBalanceScript(code string, tokenType templates.TokenType) (string, bool) {
+	m := balanceScriptMain.FindStringSubmatchIndex(code)
+	if m == nil {
+		return "", false
+	}
+
+	label := code[m[2]:m[3]]
+	single := code[:m[0]] + "pub fun walletBalanceOf(" + code[m[0]+len("pub fun main("):]
+
+	switch tokenType {
+	case templates.FT:
+		return single + fmt.Sprintf(`
+pub fun main(addresses: [Address]): [UFix64] {
+    let balances: [UFix64] = []
+    for address in addresses {
+        balances.append(walletBalanceOf(%s: address))
+    }
+    return balances
+}
+`, label), true
+	case templates.NFT:
+		return single + fmt.Sprintf(`
+pub fun main(addresses: [Address]): [Int] {
+    let counts: [Int] = []
+    for address in addresses {
+        counts.append(walletBalanceOf(%s: address).length)
+    }
+    return counts
+}
+`, label), true
+	default:
+		return "", false
+	}
+}
+
+// balanceAmount returns a fungible token balance in its fixed-point
+// representation, or the number of NFTs for a list of IDs or a count.
+func balanceAmount(v cadence.Value) (uint64, error) {
+	switch v := v.(type) {
+	case cadence.UFix64:
+		return uint64(v), nil
+	case cadence.Int:
+		return v.Big().Uint64(), nil
+	case cadence.Array:
+		return uint64(len(v.Values)), nil
+	default:
+		return 0, fmt.Errorf("unexpected balance type: %s", v.Type().ID())
+	}
+}
+
+func tokenLiability(token *templates.Token, t *tally) TokenLiability {
+	l := TokenLiability{
+		TokenName: token.Name,
+		Accounts:  t.accounts,
+		Total:     formatAmount(token.Type, t.total),
+	}
+
+	labels := make([]string, 0, len(t.groups))
+	for label := range t.groups {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		g := t.groups[label]
+		l.Groups = append(l.Groups, GroupLiability{
+			Label:    label,
+			Accounts: g.accounts,
+			Total:    formatAmount(token.Type, g.total),
+		})
+	}
+
+	return l
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/datastore/gorm"
+	"github.com/flow-hydraulics/flow-wallet-api/reports"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/tokens"
+	access "github.com/onflow/flow-go-sdk/access/grpc"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// runLiabilitiesReport writes a CSV report of the total balances of all
+// tokens held by custodial accounts to path, or to stdout if path is "-".
+func runLiabilitiesReport(cfg *configs.Config, path, groupBy string) {
+	configs.ConfigureLogger(cfg.LogLevel)
+
+	fc, err := access.NewClient(
+		cfg.AccessAPIHost,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(cfg.GrpcMaxCallRecvMsgSize)),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer fc.Close()
+
+	db, err := gorm.New(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer gorm.Close(db)
+
+	templateService, err := templates.NewService(cfg, templates.NewGormStore(db))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	svc := reports.NewService(cfg, accounts.NewGormStore(db), tokens.NewGormStore(db), templateService, fc)
+
+	report, err := svc.Liabilities(context.Background(), groupBy)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := report.WriteCSV(w); err != nil {
+		log.Fatal(err)
+	}
+
+	if len(report.FailedAccounts) > 0 {
+		log.
+			WithFields(log.Fields{"failedAccounts": len(report.FailedAccounts)}).
+			Warn("Balances of some accounts could not be read and are not included in the report")
+	}
+
+	log.WithFields(log.Fields{"path": path, "blockHeight": report.BlockHeight}).Info("Liabilities report written")
+}
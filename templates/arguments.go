@@ -0,0 +1,33 @@
+package templates
+
+import (
+	"fmt"
+
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/onflow/cadence"
+)
+
+// CheckArguments checks that arguments match the parameters declared by a
+// Cadence template.
+func CheckArguments(params TemplateParameters, args []cadence.Value) error {
+	if len(args) != len(params) {
+		return fmt.Errorf("expected %d arguments, got %d", len(params), len(args))
+	}
+
+	for i, p := range params {
+		if err := checkType(p.Type, args[i]); err != nil {
+			return fmt.Errorf("invalid argument %q: %w", p.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// checkType checks a value against a declared Cadence type.
+func checkType(declared string, v cadence.Value) error {
+	t, err := flow_helpers.ParseType(declared)
+	if err != nil {
+		return err
+	}
+	return flow_helpers.CheckArgumentType(t, v)
+}
@@ -0,0 +1,44 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+)
+
+func TestCheckType(t *testing.T) {
+	address := cadence.NewAddress([8]byte{0, 0, 0, 0, 0, 0, 0, 1})
+	str := func(s string) cadence.Value { return cadence.String(s) }
+
+	cases := []struct {
+		declared string
+		value    cadence.Value
+		valid    bool
+	}{
+		{"UFix64", cadence.UFix64(1), true},
+		{"UFix64", cadence.UInt64(1), false},
+		{"Address", address, true},
+		{"String?", cadence.NewOptional(nil), true},
+		{"String?", cadence.NewOptional(str("a")), true},
+		{"String?", str("a"), true},
+		{"String?", cadence.UInt64(1), false},
+		{"[Address]", cadence.NewArray([]cadence.Value{address, address}), true},
+		{"[Address]", cadence.NewArray([]cadence.Value{address, str("a")}), false},
+		{"[UInt8; 2]", cadence.NewArray([]cadence.Value{cadence.UInt8(1)}), false},
+		{"{String: UInt64}", cadence.NewDictionary([]cadence.KeyValuePair{{Key: str("a"), Value: cadence.UInt64(1)}}), true},
+		{"{String: UInt64}", cadence.NewDictionary([]cadence.KeyValuePair{{Key: str("a"), Value: str("b")}}), false},
+		{"StoragePath", cadence.Path{Domain: "storage", Identifier: "vault"}, true},
+		{"PublicPath", cadence.Path{Domain: "storage", Identifier: "vault"}, false},
+		{"AnyStruct", str("a"), true},
+	}
+
+	for _, c := range cases {
+		err := checkType(c.declared, c.value)
+		if c.valid && err != nil {
+			t.Errorf("expected %v to be a valid %s, got: %s", c.value, c.declared, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("expected %v to be an invalid %s", c.value, c.declared)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package templates
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TemplateKind tells whether a Cadence template is a transaction or a script.
+type TemplateKind string
+
+const (
+	TransactionTemplate TemplateKind = "transaction"
+	ScriptTemplate      TemplateKind = "script"
+)
+
+// CadenceTemplate is a named and versioned Cadence transaction or script
+// which can be executed by name.
+type CadenceTemplate struct {
+	ID          uint64             `json:"id" gorm:"column:id;primaryKey"`
+	Name        string             `json:"name" gorm:"column:name;uniqueIndex:idx_cadence_templates_name_version;not null"`
+	Version     uint               `json:"version" gorm:"column:version;uniqueIndex:idx_cadence_templates_name_version;not null"`
+	Kind        TemplateKind       `json:"kind" gorm:"column:kind;not null"`
+	Description string             `json:"description,omitempty" gorm:"column:description"`
+	Token       string             `json:"token,omitempty" gorm:"column:token"` // Name of the token used for TOKEN_* substitution
+	Code        string             `json:"code" gorm:"column:code;not null"`
+	Parameters  TemplateParameters `json:"parameters" gorm:"column:parameters;type:text"`
+	Deprecated  bool               `json:"deprecated" gorm:"column:deprecated;index"`
+	// ComputeLimit overrides the default compute limit of transactions
+	// executed using the template
+	ComputeLimit uint64 `json:"computeLimit,omitempty" gorm:"column:compute_limit"`
+	// DeprecatedAt is set when the version was deprecated
+	DeprecatedAt *time.Time `json:"deprecatedAt,omitempty" gorm:"column:deprecated_at"`
+	CreatedAt    time.Time  `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt    time.Time  `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+func (CadenceTemplate) TableName() string {
+	return "cadence_templates"
+}
+
+// TemplateParameter declares a parameter of a Cadence template, in the same
+// order as in the transaction or script.
+type TemplateParameter struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // Cadence type, e.g. "UFix64", "[Address]" or "{String: UInt64}?"
+}
+
+// TemplateParameters are stored as JSON.
+type TemplateParameters []TemplateParameter
+
+func (p TemplateParameters) Value() (driver.Value, error) {
+	if p == nil {
+		p = TemplateParameters{}
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (p *TemplateParameters) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+		*p = TemplateParameters{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("unable to scan template parameters from %T", value)
+	}
+	return json.Unmarshal(b, p)
+}
@@ -0,0 +1,168 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/onflow/cadence"
+)
+
+var validTemplateName = regexp.MustCompile(`^[A-Za-z0-9_\-.]+$`)
+
+// AddCadenceTemplate stores a Cadence template as the next version of its
+// name. Known contract addresses and token variables in the code are replaced
+// before storing.
+func (s *ServiceImpl) AddCadenceTemplate(t *CadenceTemplate) error {
+	if !validTemplateName.MatchString(t.Name) {
+		return fmt.Errorf(`not a valid name: "%s"`, t.Name)
+	}
+
+	if t.Kind != TransactionTemplate && t.Kind != ScriptTemplate {
+		return fmt.Errorf(`not a valid kind: "%s", expected "%s" or "%s"`, t.Kind, TransactionTemplate, ScriptTemplate)
+	}
+
+	if strings.TrimSpace(t.Code) == "" {
+		return fmt.Errorf("code is required")
+	}
+
+	if t.ComputeLimit > 0 && t.Kind != TransactionTemplate {
+		return fmt.Errorf("compute limit can only be set for transactions")
+	}
+
+	if t.ComputeLimit > s.cfg.MaxComputeLimit {
+		return fmt.Errorf("compute limit %d exceeds the maximum of %d", t.ComputeLimit, s.cfg.MaxComputeLimit)
+	}
+
+	for _, p := range t.Parameters {
+		if p.Name == "" || strings.TrimSpace(p.Type) == "" {
+			return fmt.Errorf("parameters require a name and a type")
+		}
+		if _, err := flow_helpers.ParseType(p.Type); err != nil {
+			return fmt.Errorf("parameter %q: %w", p.Name, err)
+		}
+	}
+
+	var token *Token
+	if t.Token != "" {
+		var err error
+		token, err = s.store.GetByName(t.Token)
+		if err != nil {
+			return fmt.Errorf("token %s: %w", t.Token, err)
+		}
+	}
+
+	code, err := CadenceTemplateCode(s.cfg.ChainID, token, t.Code)
+	if err != nil {
+		return err
+	}
+
+	if err := checkTemplateParameters(t, code); err != nil {
+		return err
+	}
+
+	t.Code = code
+	t.Deprecated = false
+	t.DeprecatedAt = nil
+
+	return s.store.InsertCadenceTemplate(t)
+}
+
+// checkTemplateParameters checks the declared parameters of a template against
+// the parameters of its code. Parameters are derived from the code if none
+// were declared.
+func checkTemplateParameters(t *CadenceTemplate, code string) error {
+	params, err := flow_helpers.ParseParameters(code)
+	if err != nil {
+		return fmt.Errorf("invalid code: %w", err)
+	}
+
+	if len(t.Parameters) == 0 {
+		t.Parameters = make(TemplateParameters, len(params))
+		for i, p := range params {
+			t.Parameters[i] = TemplateParameter{Name: p.Name, Type: p.Type.String()}
+		}
+		return nil
+	}
+
+	if len(t.Parameters) != len(params) {
+		return fmt.Errorf("%d parameters declared, code has %d", len(t.Parameters), len(params))
+	}
+
+	for i, p := range params {
+		declared := t.Parameters[i]
+		if declared.Name != p.Name {
+			return fmt.Errorf("parameter %d: declared as %q, code has %q", i, declared.Name, p.Name)
+		}
+		// Already validated above
+		declaredType, _ := flow_helpers.ParseType(declared.Type)
+		if declaredType.String() != p.Type.String() {
+			return fmt.Errorf("parameter %q: declared type %s, code has %s", p.Name, declaredType, p.Type)
+		}
+	}
+
+	return nil
+}
+
+func (s *ServiceImpl) ListCadenceTemplates(name string, includeDeprecated bool) ([]CadenceTemplate, error) {
+	return s.store.ListCadenceTemplates(name, includeDeprecated)
+}
+
+// GetCadenceTemplate returns a version of a Cadence template, or the latest
+// non-deprecated version if version is 0.
+func (s *ServiceImpl) GetCadenceTemplate(name string, version uint) (*CadenceTemplate, error) {
+	if version == 0 {
+		return s.store.LatestCadenceTemplate(name)
+	}
+	return s.store.CadenceTemplate(name, version)
+}
+
+func (s *ServiceImpl) DeprecateCadenceTemplate(name string, version uint) (*CadenceTemplate, error) {
+	if version == 0 {
+		return nil, fmt.Errorf("version is required")
+	}
+
+	t, err := s.store.CadenceTemplate(name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Deprecated {
+		return t, nil
+	}
+
+	now := time.Now()
+	t.Deprecated = true
+	t.DeprecatedAt = &now
+
+	if err := s.store.UpdateCadenceTemplate(t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// ExecutableCadenceTemplate returns a Cadence template after checking the
+// arguments against its declared parameters.
+func (s *ServiceImpl) ExecutableCadenceTemplate(name string, version uint, kind TemplateKind, args []cadence.Value) (*CadenceTemplate, error) {
+	t, err := s.GetCadenceTemplate(name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Deprecated {
+		return nil, fmt.Errorf("version %d of template %s is deprecated", t.Version, t.Name)
+	}
+
+	if t.Kind != kind {
+		return nil, fmt.Errorf("template %s is a %s, not a %s", t.Name, t.Kind, kind)
+	}
+
+	if err := CheckArguments(t.Parameters, args); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
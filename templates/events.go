@@ -8,6 +8,8 @@ import (
 const (
 	EventTokensDeposited = "TokensDeposited" // FungibleToken
 	EventDeposit         = "Deposit"         // NonFungibleToken
+	EventTokensWithdrawn = "TokensWithdrawn" // FungibleToken
+	EventWithdraw        = "Withdraw"        // NonFungibleToken
 )
 
 func EventType(address, tokenName, eventName string) string {
@@ -30,3 +32,35 @@ func DepositEventTypeFromToken(token BasicToken) string {
 	eventName := DepositNameFromTokenType(token.Type.String())
 	return EventType(address, token.Name, eventName)
 }
+
+func WithdrawNameFromTokenType(tokenType string) string {
+	switch tokenType {
+	default:
+		return ""
+	case "FT":
+		return EventTokensWithdrawn
+	case "NFT":
+		return EventWithdraw
+	}
+}
+
+func WithdrawEventTypeFromToken(token BasicToken) string {
+	address := strings.TrimPrefix(token.Address, "0x")
+	eventName := WithdrawNameFromTokenType(token.Type.String())
+	return EventType(address, token.Name, eventName)
+}
+
+// TokenEventTypes returns the deposit and withdrawal event types of tokens.
+func TokenEventTypes(tokens []BasicToken) []string {
+	eventTypes := make([]string, 0, 2*len(tokens))
+	for _, token := range tokens {
+		eventTypes = append(eventTypes, DepositEventTypeFromToken(token), WithdrawEventTypeFromToken(token))
+	}
+	return eventTypes
+}
+
+// EventName returns the name of an event from its type,
+// e.g. TokensDeposited from A.0ae53cb6e3f42a79.FlowToken.TokensDeposited.
+func EventName(eventType string) string {
+	return eventType[strings.LastIndex(eventType, ".")+1:]
+}
@@ -13,6 +13,11 @@ var KnownAddresses = templateVariables{
 		flow.Testnet:  "0x631e88ae7f1d7c20",
 		flow.Mainnet:  "0x1d7e57aa55817448",
 	},
+	"MetadataViews.cdc": knownAddresses{
+		flow.Emulator: "0xf8d6e0586b0a20c7",
+		flow.Testnet:  "0x631e88ae7f1d7c20",
+		flow.Mainnet:  "0x1d7e57aa55817448",
+	},
 }
 
 func init() {
@@ -6,6 +6,7 @@ import (
 
 	"github.com/flow-hydraulics/flow-wallet-api/configs"
 	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
 	log "github.com/sirupsen/logrus"
 )
@@ -18,6 +19,12 @@ type Service interface {
 	GetTokenByName(name string) (*Token, error)
 	RemoveToken(id uint64) error
 	TokenFromEvent(e flow.Event) (*Token, error)
+
+	AddCadenceTemplate(t *CadenceTemplate) error
+	ListCadenceTemplates(name string, includeDeprecated bool) ([]CadenceTemplate, error)
+	GetCadenceTemplate(name string, version uint) (*CadenceTemplate, error)
+	DeprecateCadenceTemplate(name string, version uint) (*CadenceTemplate, error)
+	ExecutableCadenceTemplate(name string, version uint, kind TemplateKind, args []cadence.Value) (*CadenceTemplate, error)
 }
 
 type ServiceImpl struct {
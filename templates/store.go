@@ -11,4 +11,12 @@ type Store interface {
 	// Insert a token that is available only for this instances runtime (in-memory)
 	// Used when enabling a token via environment variables
 	InsertTemp(*Token)
+
+	// Insert a Cadence template as the next version of its name
+	InsertCadenceTemplate(*CadenceTemplate) error
+	ListCadenceTemplates(name string, includeDeprecated bool) ([]CadenceTemplate, error)
+	CadenceTemplate(name string, version uint) (*CadenceTemplate, error)
+	// Latest non-deprecated version of a Cadence template
+	LatestCadenceTemplate(name string) (*CadenceTemplate, error)
+	UpdateCadenceTemplate(*CadenceTemplate) error
 }
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"strings"
 
+	"github.com/flow-hydraulics/flow-wallet-api/datastore/lib"
 	"gorm.io/gorm"
 )
 
@@ -91,3 +92,63 @@ func (s *GormStore) Remove(id uint64) error {
 func (s *GormStore) InsertTemp(token *Token) {
 	s.tempStore[strings.ToLower(token.Name)] = token
 }
+
+func (s *GormStore) InsertCadenceTemplate(t *CadenceTemplate) error {
+	return lib.GormTransaction(s.db, func(tx *gorm.DB) error {
+		var latest uint
+		err := tx.Model(&CadenceTemplate{}).
+			Where(&CadenceTemplate{Name: t.Name}).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&latest).Error
+		if err != nil {
+			return err
+		}
+
+		t.Version = latest + 1
+
+		return tx.Omit("ID").Create(t).Error
+	})
+}
+
+func (s *GormStore) ListCadenceTemplates(name string, includeDeprecated bool) ([]CadenceTemplate, error) {
+	tt := []CadenceTemplate{}
+
+	q := s.db.Order("name asc, version asc")
+
+	if name != "" {
+		q = q.Where(&CadenceTemplate{Name: name})
+	}
+
+	if !includeDeprecated {
+		q = q.Where("deprecated = ?", false)
+	}
+
+	err := q.Find(&tt).Error
+	return tt, err
+}
+
+func (s *GormStore) CadenceTemplate(name string, version uint) (*CadenceTemplate, error) {
+	var t CadenceTemplate
+	err := s.db.Where(&CadenceTemplate{Name: name, Version: version}).First(&t).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *GormStore) LatestCadenceTemplate(name string) (*CadenceTemplate, error) {
+	var t CadenceTemplate
+	err := s.db.
+		Where(&CadenceTemplate{Name: name}).
+		Where("deprecated = ?", false).
+		Order("version desc").
+		First(&t).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *GormStore) UpdateCadenceTemplate(t *CadenceTemplate) error {
+	return s.db.Save(t).Error
+}
@@ -26,6 +26,12 @@ func CreateAccountAndSetupTransaction(i BatchedFungibleOpsInfo) (string, error)
 	return executeTemplate("CreateAccount", CreateAccountAndSetupTransactionTemplate, i)
 }
 
+// CreateAccountsAndSetupTransaction creates one account for each list of
+// public keys in a single transaction.
+func CreateAccountsAndSetupTransaction(i BatchedFungibleOpsInfo) (string, error) {
+	return executeTemplate("CreateAccounts", CreateAccountsAndSetupTransactionTemplate, i)
+}
+
 const CreateAccountAndSetupTransactionTemplate = `
 import Crypto
 import FungibleToken from {{ .FungibleTokenContractAddress }}
@@ -58,6 +64,40 @@ transaction(publicKeys: [Crypto.KeyListEntry]) {
 }
 `
 
+const CreateAccountsAndSetupTransactionTemplate = `
+import Crypto
+import FungibleToken from {{ .FungibleTokenContractAddress }}
+{{ range .Tokens }}
+import {{ .ContractName }} from {{ .Address }}
+{{ end }}
+
+transaction(publicKeyLists: [[Crypto.KeyListEntry]]) {
+	prepare(signer: AuthAccount) {
+		for publicKeys in publicKeyLists {
+			let account = AuthAccount(payer: signer)
+
+			// add all the keys to the account
+			for key in publicKeys {
+				account.keys.add(publicKey: key.publicKey, hashAlgorithm: key.hashAlgorithm, weight: key.weight)
+			}
+
+			{{ range .Tokens }}
+			// initializing vault for {{ .ContractName }}
+			account.save(<-{{ .ContractName }}.createEmptyVault(), to: {{ .VaultStoragePath }})
+			account.link<&{{ .ContractName }}.Vault{FungibleToken.Receiver}>(
+				{{ .ReceiverPublicPath }},
+				target: {{ .VaultStoragePath }}
+			)
+			account.link<&{{ .ContractName }}.Vault{FungibleToken.Balance}>(
+				{{ .BalancePublicPath }},
+				target: {{ .VaultStoragePath }}
+			)
+			{{ end }}
+		}
+	}
+}
+`
+
 const AddFungibleTokenVaultBatchTransactionTemplate = `
 import FungibleToken from {{ .FungibleTokenContractAddress }}
 {{ range .Tokens }}
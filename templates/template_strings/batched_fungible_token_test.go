@@ -49,6 +49,28 @@ func TestAccountCreation(t *testing.T) {
 	}
 }
 
+func TestBatchAccountCreation(t *testing.T) {
+	result, err := CreateAccountsAndSetupTransaction(tokens)
+	if err != nil {
+		t.Error(err)
+	}
+
+	checkStrings := []string{
+		"import FungibleToken from 0xFungibleTokenContractAddress",
+		"import TokenA from 0x1",
+		"transaction(publicKeyLists: [[Crypto.KeyListEntry]])",
+		"for publicKeys in publicKeyLists {",
+		"account.save(<-TokenA.createEmptyVault(), to: TokenA.VaultStoragePath)",
+		"account.save(<-TokenB.createEmptyVault(), to: /storage/tokenBVault)",
+	}
+
+	ok, failedCheck := containsAll(result, checkStrings)
+	if !ok {
+		fmt.Println(result)
+		t.Errorf("result doesn't contain: %s", failedCheck)
+	}
+}
+
 func TestAddFungibleTokens(t *testing.T) {
 	result, err := AddFungibleTokenVaultBatchTransaction(tokens)
 	if err != nil {
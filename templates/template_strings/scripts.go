@@ -14,3 +14,116 @@ pub fun main(account: Address): UFix64 {
     return vaultRef.balance
 }
 `
+
+const AvailableFlowBalance = `
+pub fun main(account: Address): UFix64 {
+    return getAccount(account).availableBalance
+}
+`
+
+// GenericNFTItems resolves the standard metadata views of NFTs in a
+// collection. NFTs of a collection which is not a MetadataViews resolver
+// collection are returned without metadata.
+const GenericNFTItems = `
+import NonFungibleToken from "./NonFungibleToken.cdc"
+import MetadataViews from "./MetadataViews.cdc"
+import TOKEN_DECLARATION_NAME from TOKEN_ADDRESS
+
+pub struct Edition {
+    pub let name: String?
+    pub let number: UInt64
+    pub let max: UInt64?
+
+    init(name: String?, number: UInt64, max: UInt64?) {
+        self.name = name
+        self.number = number
+        self.max = max
+    }
+}
+
+pub struct Royalty {
+    pub let receiver: Address
+    pub let cut: UFix64
+    pub let description: String
+
+    init(receiver: Address, cut: UFix64, description: String) {
+        self.receiver = receiver
+        self.cut = cut
+        self.description = description
+    }
+}
+
+pub struct Item {
+    pub let id: UInt64
+    pub let name: String?
+    pub let description: String?
+    pub let thumbnail: String?
+    pub let externalURL: String?
+    pub let serial: UInt64?
+    pub let editions: [Edition]
+    pub let royalties: [Royalty]
+    pub let views: [String]
+
+    init(id: UInt64, resolver: &{MetadataViews.Resolver}?) {
+        var name: String? = nil
+        var description: String? = nil
+        var thumbnail: String? = nil
+        var externalURL: String? = nil
+        var serial: UInt64? = nil
+        let editions: [Edition] = []
+        let royalties: [Royalty] = []
+        let views: [String] = []
+
+        if let r = resolver {
+            for view in r.getViews() {
+                views.append(view.identifier)
+            }
+            if let display = r.resolveView(Type<MetadataViews.Display>()) as! MetadataViews.Display? {
+                name = display.name
+                description = display.description
+                thumbnail = display.thumbnail.uri()
+            }
+            if let url = r.resolveView(Type<MetadataViews.ExternalURL>()) as! MetadataViews.ExternalURL? {
+                externalURL = url.url
+            }
+            if let s = r.resolveView(Type<MetadataViews.Serial>()) as! MetadataViews.Serial? {
+                serial = s.number
+            }
+            if let e = r.resolveView(Type<MetadataViews.Editions>()) as! MetadataViews.Editions? {
+                for info in e.infoList {
+                    editions.append(Edition(name: info.name, number: info.number, max: info.max))
+                }
+            }
+            if let rs = r.resolveView(Type<MetadataViews.Royalties>()) as! MetadataViews.Royalties? {
+                for royalty in rs.getRoyalties() {
+                    royalties.append(Royalty(receiver: royalty.receiver.address, cut: royalty.cut, description: royalty.description))
+                }
+            }
+        }
+
+        self.id = id
+        self.name = name
+        self.description = description
+        self.thumbnail = thumbnail
+        self.externalURL = externalURL
+        self.serial = serial
+        self.editions = editions
+        self.royalties = royalties
+        self.views = views
+    }
+}
+
+pub fun main(account: Address, ids: [UInt64]): [Item] {
+    let items: [Item] = []
+
+    let collection = getAccount(account)
+        .getCapability(NFT_COLLECTION_PUBLIC_PATH)
+        .borrow<&{MetadataViews.ResolverCollection}>()
+
+    for id in ids {
+        items.append(Item(id: id, resolver: collection?.borrowViewResolver(id: id)))
+    }
+
+    return items
+}
+`
@@ -110,3 +110,37 @@ transaction(publicKeys: [String]) {
   }
 }
 `
+
+const RevokeAccountKeysTransaction = `
+transaction(keyIndexes: [Int]) {
+  prepare(signer: AuthAccount) {
+    for keyIndex in keyIndexes {
+      signer.keys.revoke(keyIndex: keyIndex)
+    }
+  }
+}
+`
+
+const AddAccountContractTransaction = `
+transaction(name: String, code: String) {
+  prepare(signer: AuthAccount) {
+    signer.contracts.add(name: name, code: code.decodeHex())
+  }
+}
+`
+
+const UpdateAccountContractTransaction = `
+transaction(name: String, code: String) {
+  prepare(signer: AuthAccount) {
+    signer.contracts.update__experimental(name: name, code: code.decodeHex())
+  }
+}
+`
+
+const RemoveAccountContractTransaction = `
+transaction(name: String) {
+  prepare(signer: AuthAccount) {
+    signer.contracts.remove(name: name)
+  }
+}
+`
@@ -44,6 +44,16 @@ var chains []flow.ChainID = []flow.ChainID{
 
 var knownAddressesReplacers chainReplacers
 
+// Regex that matches all references to cadence source files
+// For example:
+// - "../../contracts/Source.cdc"
+// - "./Source.cdc"
+// - "Source.cdc"
+var matchCadenceFiles = regexp.MustCompile(`"(.*?)(\w+\.cdc)"`)
+
+// Replace all above matches with just the filename, without quotes
+const replaceCadenceFiles = "$2"
+
 func makeReplacers(t templateVariables) chainReplacers {
 	r := make(chainReplacers, len(chains))
 	for _, c := range chains {
@@ -67,16 +77,6 @@ func (token Token) BasicToken() BasicToken {
 
 func TokenCode(chainId flow.ChainID, token *Token, tmplStr string) (string, error) {
 
-	// Regex that matches all references to cadence source files
-	// For example:
-	// - "../../contracts/Source.cdc"
-	// - "./Source.cdc"
-	// - "Source.cdc"
-	matchCadenceFiles := regexp.MustCompile(`"(.*?)(\w+\.cdc)"`)
-
-	// Replace all above matches with just the filename, without quotes
-	replaceCadenceFiles := "$2"
-
 	// Replaces all TokenName.cdc's with TOKEN_ADDRESS
 	sourceFileReplacer := strings.NewReplacer(
 		fmt.Sprintf("%s.cdc", token.Name), "TOKEN_ADDRESS",
@@ -111,6 +111,20 @@ func TokenCode(chainId flow.ChainID, token *Token, tmplStr string) (string, erro
 	return code, nil
 }
 
+// CadenceTemplateCode replaces references to cadence source files with known
+// contract addresses in a Cadence template. Templates for a token get the
+// same replacements as TokenCode.
+func CadenceTemplateCode(chainId flow.ChainID, token *Token, tmplStr string) (string, error) {
+	if token != nil {
+		return TokenCode(chainId, token, tmplStr)
+	}
+
+	code := matchCadenceFiles.ReplaceAllString(tmplStr, replaceCadenceFiles)
+	code = knownAddressesReplacers[chainId].Replace(code)
+
+	return code, nil
+}
+
 func GetTokenPaths(
 	token *Token,
 ) (
@@ -169,6 +183,20 @@ func FungibleBalanceCode(chainId flow.ChainID, token *Token) (string, error) {
 	return TokenCode(chainId, token, template_strings.GenericFungibleBalance)
 }
 
+// NFTItemsCode returns the script resolving the metadata views of NFTs of a
+// token. The collection is borrowed from the receiver public path of the
+// token if set, otherwise from the CollectionPublicPath of its contract.
+func NFTItemsCode(chainId flow.ChainID, token *Token) (string, error) {
+	collectionPath := "TOKEN_DECLARATION_NAME.CollectionPublicPath"
+	if token.ReceiverPublicPath != "" {
+		collectionPath = token.ReceiverPublicPath
+	}
+
+	code := strings.ReplaceAll(template_strings.GenericNFTItems, "NFT_COLLECTION_PUBLIC_PATH", collectionPath)
+
+	return TokenCode(chainId, token, code)
+}
+
 func InitFungibleTokenVaultsCode(chainId flow.ChainID, tokens []template_strings.FungibleTokenInfo) (string, error) {
 	return template_strings.AddFungibleTokenVaultBatchTransaction(template_strings.BatchedFungibleOpsInfo{
 		FungibleTokenContractAddress: KnownAddresses["FungibleToken.cdc"][chainId],
@@ -182,3 +210,10 @@ func CreateAccountAndInitFungibleTokenVaultsCode(chainId flow.ChainID, tokens []
 		Tokens:                       tokens,
 	})
 }
+
+func CreateAccountsAndInitFungibleTokenVaultsCode(chainId flow.ChainID, tokens []template_strings.FungibleTokenInfo) (string, error) {
+	return template_strings.CreateAccountsAndSetupTransaction(template_strings.BatchedFungibleOpsInfo{
+		FungibleTokenContractAddress: KnownAddresses["FungibleToken.cdc"][chainId],
+		Tokens:                       tokens,
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
 	"github.com/onflow/flow-go-sdk"
 )
 
@@ -76,3 +77,72 @@ func TestParsing(t *testing.T) {
 		}
 	})
 }
+
+func TestTokenEventTypes(t *testing.T) {
+	tokens := []BasicToken{
+		{Name: "FlowToken", Address: "0x0ae53cb6e3f42a79", Type: FT},
+		{Name: "ExampleNFT", Address: "01cf0e2f2f715450", Type: NFT},
+	}
+
+	expected := []string{
+		"A.0ae53cb6e3f42a79.FlowToken.TokensDeposited",
+		"A.0ae53cb6e3f42a79.FlowToken.TokensWithdrawn",
+		"A.01cf0e2f2f715450.ExampleNFT.Deposit",
+		"A.01cf0e2f2f715450.ExampleNFT.Withdraw",
+	}
+
+	got := TokenEventTypes(tokens)
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d event types, got %d", len(expected), len(got))
+	}
+
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected %s, got %s", expected[i], got[i])
+		}
+	}
+
+	if name := EventName(expected[1]); name != EventTokensWithdrawn {
+		t.Errorf("expected event name %s, got %s", EventTokensWithdrawn, name)
+	}
+}
+
+func TestNFTItemsCode(t *testing.T) {
+	t.Run("contract collection path", func(t *testing.T) {
+		token := &Token{Name: "ExampleNFT", Address: "0x01cf0e2f2f715450", Type: NFT}
+		c, err := NFTItemsCode(flow.Testnet, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(c, ".cdc") {
+			t.Error("expected all cadence file references to have been replaced")
+		}
+		if !strings.Contains(c, "import MetadataViews from 0x631e88ae7f1d7c20") {
+			t.Error("expected MetadataViews to be imported from its testnet address")
+		}
+		if !strings.Contains(c, "import ExampleNFT from 0x01cf0e2f2f715450") {
+			t.Error("expected the token contract to be imported")
+		}
+		if !strings.Contains(c, ".getCapability(ExampleNFT.CollectionPublicPath)") {
+			t.Error("expected the collection to be borrowed from the contract collection path")
+		}
+		params, err := flow_helpers.ParseParameters(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(params) != 2 || params[0].Type.String() != "Address" || params[1].Type.String() != "[UInt64]" {
+			t.Errorf("unexpected script parameters: %+v", params)
+		}
+	})
+
+	t.Run("receiver public path", func(t *testing.T) {
+		token := &Token{Name: "ExampleNFT", Address: "0x01cf0e2f2f715450", Type: NFT, ReceiverPublicPath: "/public/exampleNFTCollection"}
+		c, err := NFTItemsCode(flow.Testnet, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(c, ".getCapability(/public/exampleNFTCollection)") {
+			t.Error("expected the collection to be borrowed from the receiver public path")
+		}
+	})
+}
@@ -4,8 +4,13 @@ import (
 	"context"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
 	"github.com/flow-hydraulics/flow-wallet-api/tests/test"
+	"gorm.io/datatypes"
 )
 
 func Test_Add_New_Non_Custodial_Account(t *testing.T) {
@@ -134,7 +139,7 @@ func Test_Add_Multiple_New_Custodial_Accounts(t *testing.T) {
 		t.Skip("skipped as \"cfg.AdminProposalKeyCount\" is less than or equal to 1")
 	}
 
-	if accounts, err := svcs[0].GetAccounts().List(0, 0); err != nil {
+	if accounts, err := svcs[0].GetAccounts().List(0, 0, accounts.ListFilter{}); err != nil {
 		t.Fatal(err)
 	} else if len(accounts) > 1 {
 		t.Fatal("expected there to be only 1 account")
@@ -171,9 +176,396 @@ func Test_Add_Multiple_New_Custodial_Accounts(t *testing.T) {
 	default:
 	}
 
-	if accounts, err := svcs[0].GetAccounts().List(0, 0); err != nil {
+	if accounts, err := svcs[0].GetAccounts().List(0, 0, accounts.ListFilter{}); err != nil {
 		t.Fatal(err)
 	} else if len(accounts) < 1+accountsToCreate {
 		t.Fatalf("expected there to be %d accounts", 1+accountsToCreate)
 	}
 }
+
+func Test_Create_Multi_Signature_Custodial_Account(t *testing.T) {
+	ctx := context.Background()
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+
+	specs := []accounts.KeySpec{
+		{Type: keys.AccountKeyTypeLocal, Weight: 500},
+		{Type: keys.AccountKeyTypeLocal, Weight: 500},
+	}
+
+	_, a, err := svcs.GetAccounts().Create(ctx, true, accounts.WithKeys(specs))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sets, err := svcs.GetAccounts().KeySets(a.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sets) != len(specs) {
+		t.Fatalf("expected %d key sets, got %d", len(specs), len(sets))
+	}
+
+	// Both keys are required to reach the signing threshold
+	tx, err := svcs.GetTransactions().Sign(ctx, a.Address, "transaction() { prepare(signer: AuthAccount) {} }", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tx.PayloadSignatures) != len(specs) {
+		t.Fatalf("expected %d payload signatures, got %d", len(specs), len(tx.PayloadSignatures))
+	}
+}
+
+func Test_Create_Account_With_Insufficient_Key_Weight_Fails(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svc := test.GetServices(t, cfg).GetAccounts()
+
+	specs := []accounts.KeySpec{{Type: keys.AccountKeyTypeLocal, Weight: 500}}
+
+	if _, _, err := svc.Create(context.Background(), true, accounts.WithKeys(specs)); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func Test_Import_Existing_Account(t *testing.T) {
+	ctx := context.Background()
+
+	// Create an account using another instance (database) of the service
+	source := test.GetServices(t, test.LoadConfig(t))
+	_, created, err := source.GetAccounts().Create(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privateKey, err := source.GetKeyManager().Load(created.Keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := test.GetServices(t, test.LoadConfig(t)).GetAccounts()
+
+	importKeys := []accounts.ImportKey{{Type: keys.AccountKeyTypeLocal, Value: privateKey.Value}}
+
+	a, err := svc.Import(ctx, created.Address, importKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Type != accounts.AccountTypeCustodial {
+		t.Fatalf("expected a.Type = %q, got %q", accounts.AccountTypeCustodial, a.Type)
+	}
+
+	if len(a.Keys) != len(created.Keys) {
+		t.Fatalf("expected %d keys, got %d", len(created.Keys), len(a.Keys))
+	}
+
+	// Importing the same account twice must fail
+	if _, err := svc.Import(ctx, created.Address, importKeys); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func Test_Import_Account_With_Unknown_Key_Fails(t *testing.T) {
+	ctx := context.Background()
+
+	source := test.GetServices(t, test.LoadConfig(t))
+	_, created, err := source.GetAccounts().Create(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := test.GetServices(t, test.LoadConfig(t)).GetAccounts()
+
+	// A freshly generated key is not present on the account
+	_, privateKey, err := source.GetKeyManager().GenerateDefault(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	importKeys := []accounts.ImportKey{{Type: keys.AccountKeyTypeLocal, Value: privateKey.Value}}
+
+	if _, err := svc.Import(ctx, created.Address, importKeys); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func Test_Import_Account_With_Duplicate_Keys_Fails(t *testing.T) {
+	ctx := context.Background()
+
+	source := test.GetServices(t, test.LoadConfig(t))
+
+	specs := []accounts.KeySpec{
+		{Type: keys.AccountKeyTypeLocal, Weight: 500},
+		{Type: keys.AccountKeyTypeLocal, Weight: 500},
+	}
+
+	_, created, err := source.GetAccounts().Create(ctx, true, accounts.WithKeys(specs))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privateKey, err := source.GetKeyManager().Load(created.Keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := test.GetServices(t, test.LoadConfig(t)).GetAccounts()
+
+	// The same key given twice must not count twice towards the threshold
+	importKeys := []accounts.ImportKey{
+		{Type: keys.AccountKeyTypeLocal, Value: privateKey.Value},
+		{Type: keys.AccountKeyTypeLocal, Value: privateKey.Value},
+	}
+
+	if _, err := svc.Import(ctx, created.Address, importKeys); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func Test_AccountStoreMetadata(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	store := accounts.NewGormStore(test.GetDatabase(t, cfg))
+
+	userA, userB := "user-a", "user-b"
+
+	aa := []accounts.Account{
+		{Address: "0x01cf0e2f2f715450", ExternalID: &userA, Labels: datatypes.JSON(`{"tier": "gold", "region": "eu"}`)},
+		{Address: "0x179b6b1cb6755e31", ExternalID: &userB, Labels: datatypes.JSON(`{"tier": "silver", "vip": true}`)},
+		{Address: "0xf3fcd2c1a78f5eee"},
+	}
+	for i := range aa {
+		if err := store.InsertAccount(&aa[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := store.InsertAccount(&accounts.Account{Address: "0xe03daebed8ca0615", ExternalID: &userA}); err == nil {
+		t.Fatal("expected a duplicate external id to fail")
+	}
+
+	cases := []struct {
+		name     string
+		filter   accounts.ListFilter
+		expected int
+	}{
+		{"no filter", accounts.ListFilter{}, 3},
+		{"external id", accounts.ListFilter{ExternalID: userB}, 1},
+		{"label value", accounts.ListFilter{Labels: map[string]string{"tier": "gold"}}, 1},
+		{"label key", accounts.ListFilter{Labels: map[string]string{"tier": ""}}, 2},
+		{"multiple labels", accounts.ListFilter{Labels: map[string]string{"tier": "silver", "region": "eu"}}, 0},
+		{"non-string label", accounts.ListFilter{Labels: map[string]string{"vip": "true"}}, 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			listed, err := store.Accounts(datastore.ListOptions{Limit: 10}, c.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(listed) != c.expected {
+				t.Fatalf("expected %d accounts, got %d", c.expected, len(listed))
+			}
+		})
+	}
+
+	a, err := store.AccountByExternalID(userA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Address != aa[0].Address {
+		t.Fatalf("expected account %s, got %s", aa[0].Address, a.Address)
+	}
+
+	a.ExternalID = nil
+	a.Labels = nil
+	if err := store.UpdateAccountMetadata(&a); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err = store.Account(aa[0].Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.ExternalID != nil || a.Labels != nil {
+		t.Fatalf("expected metadata to be cleared, got %v %s", a.ExternalID, a.Labels)
+	}
+
+	listed, err := store.Accounts(datastore.ListOptions{Limit: 10}, accounts.ListFilter{Labels: map[string]string{"tier": ""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 {
+		t.Fatalf("expected cleared labels to be removed from the index, got %d accounts", len(listed))
+	}
+}
+
+func Test_AccountStorePool(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	store := accounts.NewGormStore(test.GetDatabase(t, cfg))
+
+	aa := []accounts.Account{
+		{Address: "0x01cf0e2f2f715450", Type: accounts.AccountTypePooled, CreatedAt: time.Now().Add(-time.Minute)},
+		{Address: "0x179b6b1cb6755e31", Type: accounts.AccountTypePooled},
+		{Address: "0xf3fcd2c1a78f5eee", Type: accounts.AccountTypeCustodial},
+	}
+	for i := range aa {
+		if err := store.InsertAccount(&aa[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	listed, err := store.Accounts(datastore.ListOptions{Limit: 10}, accounts.ListFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 {
+		t.Fatalf("expected pooled accounts not to be listed, got %d accounts", len(listed))
+	}
+
+	count, err := store.PooledAccountCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 pooled accounts, got %d", count)
+	}
+
+	pooled, err := store.PooledAccounts(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pooled) != 1 || pooled[0].Address != aa[0].Address {
+		t.Fatalf("expected the oldest pooled account %s, got %v", aa[0].Address, pooled)
+	}
+
+	externalID := "user-a"
+	a := pooled[0]
+	a.ExternalID = &externalID
+	a.Labels = datatypes.JSON(`{"tier": "gold"}`)
+
+	claimed, err := store.ClaimPooledAccount(&a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !claimed {
+		t.Fatal("expected the pooled account to be claimed")
+	}
+
+	stale := pooled[0]
+	claimed, err = store.ClaimPooledAccount(&stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed {
+		t.Fatal("expected an account to be claimed only once")
+	}
+
+	a, err = store.AccountByExternalID(externalID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Type != accounts.AccountTypeCustodial {
+		t.Fatalf("expected a.Type = %q, got %q", accounts.AccountTypeCustodial, a.Type)
+	}
+
+	listed, err = store.Accounts(datastore.ListOptions{Limit: 10}, accounts.ListFilter{Labels: map[string]string{"tier": "gold"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 {
+		t.Fatalf("expected the claimed account to be labeled, got %d accounts", len(listed))
+	}
+}
+
+func Test_ClosedAccountGuard(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	store := accounts.NewGormStore(test.GetDatabase(t, cfg))
+	guard := accounts.NewClosedAccountGuard(store)
+
+	address := "0x01cf0e2f2f715450"
+	if err := store.InsertAccount(&accounts.Account{Address: address}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := guard.CheckAuthorizer(address); err != nil {
+		t.Fatalf("expected an open account to pass, got %s", err)
+	}
+
+	if err := guard.CheckAuthorizer("0x179b6b1cb6755e31"); err != nil {
+		t.Fatalf("expected an unknown account to pass, got %s", err)
+	}
+
+	if err := store.CloseAccount(address, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := store.Account(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.IsClosed() {
+		t.Fatal("expected the account to be closed")
+	}
+
+	if err := guard.CheckAuthorizer("01cf0e2f2f715450"); err == nil {
+		t.Fatal("expected a closed account to be rejected")
+	}
+}
+
+func Test_AccountContracts(t *testing.T) {
+	ctx := context.Background()
+	svc := test.GetServices(t, test.LoadConfig(t)).GetAccounts()
+
+	_, a, err := svc.Create(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := accounts.Contract{
+		Name: "HelloWorld",
+		Code: `pub contract HelloWorld { pub fun hello(): String { return "Hello, World!" } }`,
+	}
+
+	if _, _, err := svc.DeployContract(ctx, true, a.Address, c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deploying the same contract twice must fail
+	if _, _, err := svc.DeployContract(ctx, true, a.Address, c); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	c.Code = `pub contract HelloWorld { pub fun hello(): String { return "Hello again!" } }`
+	if _, _, err := svc.UpdateContract(ctx, true, a.Address, c); err != nil {
+		t.Fatal(err)
+	}
+
+	deployed, err := svc.Contract(ctx, a.Address, c.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if deployed.Code != c.Code {
+		t.Fatalf("expected updated code, got %q", deployed.Code)
+	}
+
+	if _, _, err := svc.RemoveContract(ctx, true, a.Address, c.Name); err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err := svc.Contracts(ctx, a.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(contracts) != 0 {
+		t.Fatalf("expected no contracts, got %d", len(contracts))
+	}
+
+	// Invalid contract names are rejected before sending a transaction
+	if _, _, err := svc.DeployContract(ctx, true, a.Address, accounts.Contract{Name: "1nvalid", Code: c.Code}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/tests/test"
+	"github.com/onflow/cadence"
+)
+
+func Test_CadenceTemplateRegistry(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svc, err := templates.NewService(cfg, templates.NewGormStore(test.GetDatabase(t, cfg)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := `import FungibleToken from "./FungibleToken.cdc"
+transaction(amount: UFix64, recipient: Address) { prepare(signer: AuthAccount) {} }`
+
+	params := templates.TemplateParameters{
+		{Name: "amount", Type: "UFix64"},
+		{Name: "recipient", Type: "Address"},
+	}
+
+	for i := 1; i <= 2; i++ {
+		tmpl := &templates.CadenceTemplate{Name: "transfer", Kind: templates.TransactionTemplate, Code: code, Parameters: params}
+		if err := svc.AddCadenceTemplate(tmpl); err != nil {
+			t.Fatal(err)
+		}
+		if tmpl.Version != uint(i) {
+			t.Fatalf("expected version %d, got %d", i, tmpl.Version)
+		}
+		if strings.Contains(tmpl.Code, ".cdc") {
+			t.Fatalf("expected cadence file references to be replaced, got: %s", tmpl.Code)
+		}
+	}
+
+	if _, err := svc.DeprecateCadenceTemplate("transfer", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := svc.GetCadenceTemplate("transfer", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Version != 1 {
+		t.Fatalf("expected latest non-deprecated version to be 1, got %d", latest.Version)
+	}
+	if len(latest.Parameters) != 2 || latest.Parameters[1].Type != "Address" {
+		t.Fatalf("expected parameters to be stored, got %+v", latest.Parameters)
+	}
+
+	listed, err := svc.ListCadenceTemplates("transfer", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 {
+		t.Fatalf("expected deprecated versions to be excluded, got %d templates", len(listed))
+	}
+
+	args := []cadence.Value{cadence.UFix64(100000000), cadence.NewAddress([8]byte{0, 0, 0, 0, 0, 0, 0, 1})}
+
+	if _, err := svc.ExecutableCadenceTemplate("transfer", 0, templates.TransactionTemplate, args); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.ExecutableCadenceTemplate("transfer", 2, templates.TransactionTemplate, args); err == nil {
+		t.Fatal("expected executing a deprecated version to fail")
+	}
+
+	if _, err := svc.ExecutableCadenceTemplate("transfer", 0, templates.ScriptTemplate, args); err == nil {
+		t.Fatal("expected executing a transaction template as a script to fail")
+	}
+
+	if _, err := svc.ExecutableCadenceTemplate("transfer", 0, templates.TransactionTemplate, args[:1]); err == nil {
+		t.Fatal("expected missing arguments to fail")
+	}
+
+	limited := &templates.CadenceTemplate{Name: "limited", Kind: templates.TransactionTemplate, Code: code, Parameters: params, ComputeLimit: 200}
+	if err := svc.AddCadenceTemplate(limited); err != nil {
+		t.Fatal(err)
+	}
+
+	executable, err := svc.ExecutableCadenceTemplate("limited", 0, templates.TransactionTemplate, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if executable.ComputeLimit != 200 {
+		t.Fatalf("expected compute limit to be stored, got %d", executable.ComputeLimit)
+	}
+
+	tooHigh := &templates.CadenceTemplate{Name: "limited", Kind: templates.TransactionTemplate, Code: code, Parameters: params, ComputeLimit: cfg.MaxComputeLimit + 1}
+	if err := svc.AddCadenceTemplate(tooHigh); err == nil {
+		t.Fatal("expected a compute limit above the maximum to fail")
+	}
+
+	mismatched := map[string]templates.TemplateParameters{
+		"type":  {{Name: "amount", Type: "String"}, {Name: "recipient", Type: "Address"}},
+		"name":  {{Name: "value", Type: "UFix64"}, {Name: "recipient", Type: "Address"}},
+		"count": {{Name: "amount", Type: "UFix64"}},
+	}
+	for k, p := range mismatched {
+		tmpl := &templates.CadenceTemplate{Name: "mismatched", Kind: templates.TransactionTemplate, Code: code, Parameters: p}
+		if err := svc.AddCadenceTemplate(tmpl); err == nil {
+			t.Fatalf("expected parameters with a mismatched %s to fail", k)
+		}
+	}
+
+	derived := &templates.CadenceTemplate{Name: "derived", Kind: templates.TransactionTemplate, Code: code}
+	if err := svc.AddCadenceTemplate(derived); err != nil {
+		t.Fatal(err)
+	}
+	if len(derived.Parameters) != 2 || derived.Parameters[1] != (templates.TemplateParameter{Name: "recipient", Type: "Address"}) {
+		t.Fatalf("expected parameters to be derived from the code, got %v", derived.Parameters)
+	}
+}
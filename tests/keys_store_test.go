@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/flow-hydraulics/flow-wallet-api/tests/test"
+)
+
+func Test_SequenceNumberTracking(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	store := keys.NewGormStore(test.GetDatabase(t, cfg))
+
+	fetchCount := 0
+	fetch := func() (uint64, error) {
+		fetchCount++
+		return 10, nil
+	}
+
+	for _, expected := range []uint64{10, 11, 12} {
+		n, err := store.NextSequenceNumber("0x01", 0, fetch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != expected {
+			t.Fatalf("expected sequence number %d, got %d", expected, n)
+		}
+	}
+
+	if fetchCount != 1 {
+		t.Fatalf("expected sequence number to be fetched once, got %d", fetchCount)
+	}
+
+	// Other keys of the same account are tracked separately
+	if n, err := store.NextSequenceNumber("0x01", 1, fetch); err != nil {
+		t.Fatal(err)
+	} else if n != 10 {
+		t.Fatalf("expected sequence number 10, got %d", n)
+	}
+
+	// Resync
+	if err := store.DeleteSequenceNumber("0x01", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := store.NextSequenceNumber("0x01", 0, fetch); err != nil {
+		t.Fatal(err)
+	} else if n != 10 {
+		t.Fatalf("expected sequence number 10 after resync, got %d", n)
+	}
+
+	if fetchCount != 3 {
+		t.Fatalf("expected sequence number to be fetched 3 times, got %d", fetchCount)
+	}
+
+	// Resyncing a key which is not tracked is a no-op
+	if err := store.DeleteSequenceNumber("0x02", 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_SequenceNumberRelease(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	store := keys.NewGormStore(test.GetDatabase(t, cfg))
+
+	fetch := func() (uint64, error) { return 10, nil }
+
+	next := func(expected uint64) {
+		t.Helper()
+		n, err := store.NextSequenceNumber("0x01", 0, fetch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != expected {
+			t.Fatalf("expected sequence number %d, got %d", expected, n)
+		}
+	}
+
+	next(10)
+	next(11)
+
+	// Releasing the last allocated sequence number decrements the tracked one
+	if err := store.ReleaseSequenceNumber("0x01", 0, 11); err != nil {
+		t.Fatal(err)
+	}
+	next(11)
+	next(12)
+
+	// Released sequence numbers are reused before new ones are allocated
+	if err := store.ReleaseSequenceNumber("0x01", 0, 11); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ReleaseSequenceNumber("0x01", 0, 10); err != nil {
+		t.Fatal(err)
+	}
+	next(10)
+	next(11)
+	next(13)
+}
+
+func Test_SequenceNumberResync(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	store := keys.NewGormStore(test.GetDatabase(t, cfg))
+
+	onChain := uint64(10)
+	fetch := func() (uint64, error) { return onChain, nil }
+
+	next := func(expected uint64) {
+		t.Helper()
+		n, err := store.NextSequenceNumber("0x01", 0, fetch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != expected {
+			t.Fatalf("expected sequence number %d, got %d", expected, n)
+		}
+	}
+
+	// 10, 11 and 12 are in flight
+	next(10)
+	next(11)
+	next(12)
+
+	// 11 was executed before 10 and rejected, 10 and 12 may still be sealed
+	if err := store.ResyncSequenceNumber("0x01", 0, 11, fetch); err != nil {
+		t.Fatal(err)
+	}
+	next(11)
+	next(13)
+
+	// Once nothing is in flight the on-chain sequence number is used
+	onChain = 14
+	if err := store.ResyncSequenceNumber("0x01", 0, 13, fetch); err != nil {
+		t.Fatal(err)
+	}
+	next(14)
+
+	// Resyncing a key which is not tracked is a no-op
+	if err := store.ResyncSequenceNumber("0x02", 0, 0, fetch); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,210 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/payers"
+	"github.com/flow-hydraulics/flow-wallet-api/tests/test"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/flow-go-sdk"
+)
+
+func Test_PayerPool(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := test.LoadConfig(t)
+	cfg.PayerPoolMinBalance = "0.0"
+	app := test.GetServices(t, cfg)
+	payerSvc := app.GetPayers()
+
+	_, account, err := app.GetAccounts().Create(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := payerSvc.Add(ctx, account.Address); err != nil {
+		t.Fatal(err)
+	}
+
+	// Adding the same payer twice must fail
+	if _, err := payerSvc.Add(ctx, account.Address); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	// Admin can not be added to the pool
+	if _, err := payerSvc.Add(ctx, cfg.AdminAddress); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	code := "transaction() { prepare(signer: AuthAccount){} execute {}}"
+
+	_, tx, err := app.GetTransactions().Create(ctx, true, cfg.AdminAddress, code, nil, transactions.General)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flowTx, err := flow.DecodeTransaction(tx.FlowTransaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flowTx.Payer != flow.HexToAddress(account.Address) {
+		t.Fatalf("expected payer to be %s, got %s", account.Address, flowTx.Payer)
+	}
+
+	if err := payerSvc.Remove(account.Address); err != nil {
+		t.Fatal(err)
+	}
+
+	// Falls back to admin when the pool is empty
+	signed, err := app.GetTransactions().Sign(ctx, cfg.AdminAddress, code, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if signed.Payer != flow.HexToAddress(cfg.AdminAddress) {
+		t.Fatalf("expected payer to be admin, got %s", signed.Payer)
+	}
+}
+
+func Test_PayerStoreNextPayer(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	store := payers.NewGormStore(test.GetDatabase(t, cfg))
+
+	now := time.Now()
+	for _, p := range []payers.Payer{
+		{Address: "0x01", Balance: 100, LastUsedAt: now.Add(-time.Minute)},
+		{Address: "0x02", Balance: 300, LastUsedAt: now.Add(-3 * time.Minute)},
+		{Address: "0x03", Balance: 500, LastUsedAt: now.Add(-5 * time.Minute), Excluded: true},
+	} {
+		p := p
+		if err := store.InsertPayer(&p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("by balance", func(t *testing.T) {
+		p, err := store.NextPayer("balance desc", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p == nil || p.Address != "0x02" {
+			t.Fatalf("expected payer 0x02, got %+v", p)
+		}
+	})
+
+	t.Run("rotate among top payers by balance", func(t *testing.T) {
+		// 0x02 was just used, so 0x01 is the least recently used of the top two
+		p, err := store.NextPayer("balance desc", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p == nil || p.Address != "0x01" {
+			t.Fatalf("expected payer 0x01, got %+v", p)
+		}
+
+		p, err = store.NextPayer("balance desc", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p == nil || p.Address != "0x02" {
+			t.Fatalf("expected payer 0x02, got %+v", p)
+		}
+	})
+
+	t.Run("least recently used", func(t *testing.T) {
+		// 0x02 was just used
+		p, err := store.NextPayer("last_used_at asc", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p == nil || p.Address != "0x01" {
+			t.Fatalf("expected payer 0x01, got %+v", p)
+		}
+	})
+
+	t.Run("no available payers", func(t *testing.T) {
+		for _, a := range []string{"0x01", "0x02"} {
+			if err := store.UpdateBalance(a, 0, true); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		p, err := store.NextPayer("balance desc", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != nil {
+			t.Fatalf("expected no payer, got %+v", p)
+		}
+	})
+}
+
+// balanceFlowClient returns the given balances of accounts, other accounts
+// can not be fetched.
+type balanceFlowClient struct {
+	flow_helpers.FlowClient
+	balances map[flow.Address]uint64
+}
+
+func (c *balanceFlowClient) GetAccount(ctx context.Context, address flow.Address) (*flow.Account, error) {
+	balance, ok := c.balances[address]
+	if !ok {
+		return nil, fmt.Errorf("account %s not found", address)
+	}
+	return &flow.Account{Address: address, Balance: balance}, nil
+}
+
+func Test_PayerPoolCheckBalancesSkipsFailedPayers(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	cfg.PayerPoolMinBalance = "1.0"
+	store := payers.NewGormStore(test.GetDatabase(t, cfg))
+
+	for _, p := range []payers.Payer{
+		{Address: "0x01", Balance: 500000000},
+		{Address: "0x02", Balance: 500000000},
+		{Address: "0x03", Balance: 500000000},
+	} {
+		p := p
+		if err := store.InsertPayer(&p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Balance of 0x01 can not be read, 0x02 has been drained
+	fc := &balanceFlowClient{balances: map[flow.Address]uint64{
+		flow.HexToAddress("0x02"): 10000000,
+		flow.HexToAddress("0x03"): 300000000,
+	}}
+
+	svc := payers.NewService(cfg, store, nil, fc, nil)
+
+	if err := svc.CheckBalances(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]struct {
+		balance  uint64
+		excluded bool
+	}{
+		"0x01": {500000000, false},
+		"0x02": {10000000, true},
+		"0x03": {300000000, false},
+	}
+
+	pp, err := store.Payers()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range pp {
+		e := expected[p.Address]
+		if p.Balance != e.balance || p.Excluded != e.excluded {
+			t.Fatalf("expected payer %s to have balance %d and excluded %t, got %d and %t", p.Address, e.balance, e.excluded, p.Balance, p.Excluded)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/flow-hydraulics/flow-wallet-api/reconciliation"
+	"github.com/flow-hydraulics/flow-wallet-api/tests/test"
+	"github.com/flow-hydraulics/flow-wallet-api/tokens"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+)
+
+func Test_ReconciliationStoreTransfers(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	db := test.GetDatabase(t, cfg)
+	store := reconciliation.NewGormStore(db)
+	tokenStore := tokens.NewGormStore(db)
+	txStore := transactions.NewGormStore(db)
+
+	address := "0x01cf0e2f2f715450"
+	other := "0x179b6b1cb6755e31"
+
+	transfers := []struct {
+		txID        string
+		blockHeight uint64
+		transfer    tokens.TokenTransfer
+	}{
+		{"tx-1", 10, tokens.TokenTransfer{SenderAddress: other, RecipientAddress: address, FtAmount: "5.00000000", TokenName: "FlowToken"}},
+		{"tx-2", 20, tokens.TokenTransfer{SenderAddress: address, RecipientAddress: other, FtAmount: "1.50000000", TokenName: "FlowToken"}},
+		{"tx-3", 30, tokens.TokenTransfer{SenderAddress: other, RecipientAddress: address, FtAmount: "2.00000000", TokenName: "FlowToken"}},
+		{"tx-4", 20, tokens.TokenTransfer{SenderAddress: other, RecipientAddress: address, FtAmount: "7.00000000", TokenName: "FUSD"}},
+		{"tx-5", 0, tokens.TokenTransfer{SenderAddress: address, RecipientAddress: other, FtAmount: "1.00000000", TokenName: "FlowToken"}},
+	}
+	for _, tr := range transfers {
+		if err := txStore.InsertTransaction(&transactions.Transaction{TransactionId: tr.txID, BlockHeight: tr.blockHeight}); err != nil {
+			t.Fatal(err)
+		}
+		tt := tr.transfer
+		tt.TransactionId = tr.txID
+		if err := tokenStore.InsertTokenTransfer(&tt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tt, err := store.Transfers(address, "FlowToken", 10, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tt) != 2 {
+		t.Fatalf("expected 2 transfers after height 10, got %d", len(tt))
+	}
+	if tt[0].BlockHeight != 20 || tt[0].FtAmount != "1.50000000" {
+		t.Fatalf("unexpected first transfer: %+v", tt[0])
+	}
+
+	pending, err := store.PendingTransfers(address, "FlowToken", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending != 1 {
+		t.Fatalf("expected 1 pending transfer, got %d", pending)
+	}
+
+	pending, err = store.PendingTransfers(address, "FlowToken", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending != 0 {
+		t.Fatalf("expected transfers created before since to be ignored, got %d", pending)
+	}
+}
+
+func Test_ReconciliationStoreDiscrepancies(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	store := reconciliation.NewGormStore(test.GetDatabase(t, cfg))
+
+	address := "0x01cf0e2f2f715450"
+
+	c := reconciliation.Checkpoint{AccountAddress: address, TokenName: "FlowToken", BlockHeight: 10, Balance: "1.00000000"}
+	if err := store.SaveCheckpoint(&c); err != nil {
+		t.Fatal(err)
+	}
+	c.BlockHeight = 20
+	if err := store.SaveCheckpoint(&c); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := store.Checkpoint(address, "FlowToken")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.BlockHeight != 20 {
+		t.Fatalf("expected the checkpoint to be updated, got height %d", saved.BlockHeight)
+	}
+
+	if _, err := store.UnresolvedDiscrepancy(address, "FlowToken"); err == nil {
+		t.Fatal("expected no unresolved discrepancy")
+	}
+
+	d := reconciliation.Discrepancy{AccountAddress: address, TokenName: "FlowToken", FromHeight: 20, ToHeight: 30, Difference: "-1.00000000"}
+	if err := store.SaveDiscrepancy(&d); err != nil {
+		t.Fatal(err)
+	}
+	other := reconciliation.Discrepancy{AccountAddress: address, TokenName: "FUSD", FromHeight: 20, ToHeight: 30, Difference: "2.00000000"}
+	if err := store.SaveDiscrepancy(&other); err != nil {
+		t.Fatal(err)
+	}
+
+	open, err := store.UnresolvedDiscrepancy(address, "FlowToken")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if open.ID != d.ID {
+		t.Fatalf("expected discrepancy %d, got %d", d.ID, open.ID)
+	}
+
+	if err := store.ResolveDiscrepancies(address, "FlowToken", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	unresolved, err := store.Discrepancies(datastore.ListOptions{Limit: 10}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unresolved) != 1 || unresolved[0].TokenName != "FUSD" {
+		t.Fatalf("expected only the FUSD discrepancy to be unresolved, got %+v", unresolved)
+	}
+
+	all, err := store.Discrepancies(datastore.ListOptions{Limit: 10}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[1].ResolvedAt == nil {
+		t.Fatalf("expected the resolved discrepancy to be listed, got %+v", all)
+	}
+}
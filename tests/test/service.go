@@ -17,6 +17,7 @@ import (
 	"github.com/flow-hydraulics/flow-wallet-api/keys"
 	"github.com/flow-hydraulics/flow-wallet-api/keys/basic"
 	"github.com/flow-hydraulics/flow-wallet-api/ops"
+	"github.com/flow-hydraulics/flow-wallet-api/payers"
 	"github.com/flow-hydraulics/flow-wallet-api/system"
 	"github.com/flow-hydraulics/flow-wallet-api/templates"
 	"github.com/flow-hydraulics/flow-wallet-api/tokens"
@@ -31,6 +32,7 @@ type Services interface {
 	GetTransactions() transactions.Service
 	GetSystem() system.Service
 	GetOps() ops.Service
+	GetPayers() payers.Service
 
 	GetKeyManager() keys.Manager
 	GetListener() chain_events.Listener
@@ -45,6 +47,7 @@ type svcs struct {
 	transactionService transactions.Service
 	systemService      system.Service
 	opsService         ops.Service
+	payerService       payers.Service
 
 	keyManager keys.Manager
 	listener   chain_events.Listener
@@ -114,7 +117,8 @@ func GetServices(t *testing.T, cfg *configs.Config) Services {
 	if err != nil {
 		t.Fatal(err)
 	}
-	transactionService := transactions.NewService(cfg, transactions.NewGormStore(db), km, fc, wp)
+	payerService := payers.NewService(cfg, payers.NewGormStore(db), km, fc, accounts.NewGormStore(db))
+	transactionService := transactions.NewService(cfg, transactions.NewGormStore(db), km, fc, wp, transactions.WithPayerProvider(payerService), transactions.WithAccountGuard(accounts.NewClosedAccountGuard(accounts.NewGormStore(db))))
 	accountService := accounts.NewService(cfg, accounts.NewGormStore(db), km, fc, wp, transactionService, templateService)
 	jobService := jobs.NewService(jobs.NewGormStore(db))
 	tokenService := tokens.NewService(cfg, tokens.NewGormStore(db), km, fc, wp, transactionService, templateService, accountService)
@@ -127,15 +131,8 @@ func GetServices(t *testing.T, cfg *configs.Config) Services {
 			return nil, err
 		}
 
-		token_count := len(tt)
-		event_types := make([]string, token_count)
-
-		// Listen for enabled tokens deposit events
-		for i, token := range tt {
-			event_types[i] = templates.DepositEventTypeFromToken(token)
-		}
-
-		return event_types, nil
+		// Listen for enabled tokens deposit and withdrawal events
+		return templates.TokenEventTypes(tt), nil
 	}
 
 	listener := chain_events.NewListener(
@@ -153,6 +150,12 @@ func GetServices(t *testing.T, cfg *configs.Config) Services {
 		TokenService:    tokenService,
 	})
 
+	var sealTracker *transactions.SealTracker
+	if cfg.EnableSealTracker {
+		sealTracker = transactions.NewSealTracker(cfg, transactions.NewGormStore(db), km, fc, wp)
+		sealTracker.Start()
+	}
+
 	err = accountService.InitAdminAccount(context.Background())
 	if err != nil {
 		t.Fatal(err)
@@ -167,6 +170,9 @@ func GetServices(t *testing.T, cfg *configs.Config) Services {
 		wp.Stop(false)
 		opsService.GetWorkerPool().Stop()
 		listener.Stop()
+		if sealTracker != nil {
+			sealTracker.Stop()
+		}
 	})
 
 	wp.Start()
@@ -180,6 +186,7 @@ func GetServices(t *testing.T, cfg *configs.Config) Services {
 		transactionService: transactionService,
 		systemService:      systemService,
 		opsService:         opsService,
+		payerService:       payerService,
 
 		keyManager: km,
 		listener:   listener,
@@ -211,6 +218,10 @@ func (s *svcs) GetOps() ops.Service {
 	return s.opsService
 }
 
+func (s *svcs) GetPayers() payers.Service {
+	return s.payerService
+}
+
 func (s *svcs) GetKeyManager() keys.Manager {
 	return s.keyManager
 }
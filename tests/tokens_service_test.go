@@ -11,6 +11,7 @@ import (
 
 	"github.com/flow-hydraulics/flow-wallet-api/jobs"
 	"github.com/flow-hydraulics/flow-wallet-api/tests/test"
+	"github.com/flow-hydraulics/flow-wallet-api/tokens"
 	"github.com/flow-hydraulics/flow-wallet-api/transactions"
 )
 
@@ -107,3 +108,117 @@ func Test_TokensSetup(t *testing.T) {
 		})
 	}
 }
+
+func Test_TokenStoreBalanceSnapshots(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	store := tokens.NewGormStore(test.GetDatabase(t, cfg))
+
+	address := "0x01cf0e2f2f715450"
+	day := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	ss := []tokens.BalanceSnapshot{
+		{AccountAddress: address, TokenName: "FlowToken", BlockHeight: 10, BlockTimestamp: day, Balance: "1.00000000"},
+		{AccountAddress: address, TokenName: "FlowToken", BlockHeight: 20, BlockTimestamp: day.Add(24 * time.Hour), Balance: "2.00000000"},
+		{AccountAddress: address, TokenName: "FlowToken", BlockHeight: 30, BlockTimestamp: day.Add(48 * time.Hour), Balance: "3.00000000"},
+		{AccountAddress: address, TokenName: "FUSD", BlockHeight: 20, BlockTimestamp: day.Add(24 * time.Hour), Balance: "5.00000000"},
+	}
+	for i := range ss {
+		if err := store.InsertBalanceSnapshot(&ss[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A snapshot of the same account, token and block height is ignored
+	duplicate := tokens.BalanceSnapshot{AccountAddress: address, TokenName: "FlowToken", BlockHeight: 20, BlockTimestamp: day, Balance: "9.00000000"}
+	if err := store.InsertBalanceSnapshot(&duplicate); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := store.BalanceSnapshots(address, "FlowToken", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 snapshots, got %d", len(all))
+	}
+	if all[1].Balance != "2.00000000" {
+		t.Fatalf("expected the first snapshot at a block height to be kept, got balance %s", all[1].Balance)
+	}
+
+	ranged, err := store.BalanceSnapshots(address, "FlowToken", day.Add(time.Hour), day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranged) != 1 || ranged[0].BlockHeight != 20 {
+		t.Fatalf("expected the snapshot at height 20, got %+v", ranged)
+	}
+
+	last, err := store.LastBalanceSnapshot(address, "FlowToken", day.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.BlockHeight != 10 {
+		t.Fatalf("expected the snapshot at height 10, got %+v", last)
+	}
+
+	if _, err := store.LastBalanceSnapshot(address, "FlowToken", day); err == nil || err.Error() != "record not found" {
+		t.Fatalf("expected record not found, got %v", err)
+	}
+}
+
+func Test_TokenStoreNftMetadataCache(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	store := tokens.NewGormStore(test.GetDatabase(t, cfg))
+
+	address := "0x01cf0e2f2f715450"
+
+	cc := []tokens.CachedNftItem{
+		{TokenName: "ExampleNFT", NftID: 1, OwnerAddress: address, Item: `{"id":1,"name":"First"}`},
+		{TokenName: "ExampleNFT", NftID: 2, OwnerAddress: address, Item: `{"id":2,"name":"Second"}`},
+		{TokenName: "OtherNFT", NftID: 1, OwnerAddress: address, Item: `{"id":1,"name":"Other"}`},
+	}
+	if err := store.SaveCachedNftItems(cc); err != nil {
+		t.Fatal(err)
+	}
+
+	// Saving an NFT again replaces its cached metadata
+	updated := []tokens.CachedNftItem{
+		{TokenName: "ExampleNFT", NftID: 2, OwnerAddress: "0x179b6b1cb6755e31", Item: `{"id":2,"name":"Updated"}`},
+	}
+	if err := store.SaveCachedNftItems(updated); err != nil {
+		t.Fatal(err)
+	}
+
+	cached, err := store.CachedNftItems("ExampleNFT", []uint64{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cached) != 2 {
+		t.Fatalf("expected 2 cached items, got %d", len(cached))
+	}
+	for _, c := range cached {
+		if c.NftID == 2 && (c.OwnerAddress != "0x179b6b1cb6755e31" || c.Item != `{"id":2,"name":"Updated"}`) {
+			t.Fatalf("expected the cached item to be replaced, got %+v", c)
+		}
+	}
+
+	if err := store.DeleteCachedNftItem("ExampleNFT", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	cached, err = store.CachedNftItems("ExampleNFT", []uint64{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cached) != 1 || cached[0].NftID != 2 {
+		t.Fatalf("expected only NFT 2 to be cached, got %+v", cached)
+	}
+
+	other, err := store.CachedNftItems("OtherNFT", []uint64{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(other) != 1 {
+		t.Fatal("expected the NFT of another token to be kept")
+	}
+}
@@ -2,12 +2,21 @@ package tests
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
 	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
 	"github.com/flow-hydraulics/flow-wallet-api/tests/test"
 	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/common"
 	"github.com/onflow/flow-go-sdk"
 )
 
@@ -112,6 +121,64 @@ func Test_TransactionProposalKeySequenceNumber(t *testing.T) {
 		}
 	})
 
+	t.Run("tracked sequence numbers for in-flight transactions", func(t *testing.T) {
+		cfg := test.LoadConfig(t)
+		cfg.AdminProposalKeyCount = 1
+		cfg.WorkerCount = 1
+		cfg.SequenceNumberTracking = true
+		app := test.GetServices(t, cfg)
+		txSvc := app.GetTransactions()
+		system := app.GetSystem()
+
+		// Pause, so the transactions won't get immediately sent
+		if err := system.Pause(); err != nil {
+			t.Fatal(err)
+		}
+
+		ctx := context.Background()
+
+		job1, tx1, err := txSvc.Create(ctx, false, cfg.AdminAddress, "transaction() { prepare(signer: AuthAccount){} execute {}}", nil, transactions.General)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		job2, tx2, err := txSvc.Create(ctx, false, cfg.AdminAddress, "transaction() { prepare(signer: AuthAccount){} execute {}}", nil, transactions.General)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		flowTx1, err := flow.DecodeTransaction(tx1.FlowTransaction)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		flowTx2, err := flow.DecodeTransaction(tx2.FlowTransaction)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if flowTx1.ProposalKey.KeyIndex != flowTx2.ProposalKey.KeyIndex {
+			t.Fatal("expected key indexes to match")
+		}
+
+		if flowTx2.ProposalKey.SequenceNumber != flowTx1.ProposalKey.SequenceNumber+1 {
+			t.Fatalf("expected consecutive sequence numbers, got %d and %d", flowTx1.ProposalKey.SequenceNumber, flowTx2.ProposalKey.SequenceNumber)
+		}
+
+		// Resume when both transactions are in the queue
+		if err := system.Resume(); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := test.WaitForJob(app.GetJobs(), job1.ID.String()); err != nil {
+			t.Error(err)
+		}
+
+		if _, err := test.WaitForJob(app.GetJobs(), job2.ID.String()); err != nil {
+			t.Error(err)
+		}
+	})
+
 	t.Run("update sequence number during job run", func(t *testing.T) {
 		t.Skip("not supported currently")
 
@@ -172,3 +239,297 @@ func Test_TransactionProposalKeySequenceNumber(t *testing.T) {
 	})
 
 }
+
+func Test_TransactionSealTracker(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	cfg.EnableSealTracker = true
+	cfg.SealTrackerInterval = 100 * time.Millisecond
+	app := test.GetServices(t, cfg)
+	txSvc := app.GetTransactions()
+
+	ctx := context.Background()
+
+	job, tx, err := txSvc.Create(ctx, false, cfg.AdminAddress, "transaction() { prepare(signer: AuthAccount){} execute {}}", nil, transactions.General)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := test.WaitForJob(app.GetJobs(), job.ID.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err = txSvc.Details(ctx, tx.TransactionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tx.Status != transactions.StatusSealed {
+		t.Fatalf("expected transaction status %s, got %s", transactions.StatusSealed, tx.Status)
+	}
+
+	if tx.BlockId == "" || tx.BlockHeight == 0 {
+		t.Fatalf("expected stored block of sealed transaction, got %q at height %d", tx.BlockId, tx.BlockHeight)
+	}
+}
+
+func Test_TransactionStorePendingTransactions(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	store := transactions.NewGormStore(test.GetDatabase(t, cfg))
+
+	for i, status := range []string{transactions.StatusPending, transactions.StatusSealed, transactions.StatusPending} {
+		tx := &transactions.Transaction{
+			TransactionId: fmt.Sprintf("%064x", i),
+			Status:        status,
+		}
+		if err := store.InsertTransaction(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pending, err := store.PendingTransactions(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 pending transactions, got %d", len(pending))
+	}
+
+	limited, err := store.PendingTransactions(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(limited) != 1 || limited[0].TransactionId != pending[0].TransactionId {
+		t.Fatalf("expected oldest pending transaction first, got %+v", limited)
+	}
+}
+
+func Test_TransactionStoreAttempts(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	store := transactions.NewGormStore(test.GetDatabase(t, cfg))
+
+	txId := fmt.Sprintf("%064x", 1)
+
+	tx := &transactions.Transaction{
+		TransactionId: txId,
+		Attempts:      []transactions.TransactionAttempt{{TransactionId: txId, FlowTransactionId: txId}},
+	}
+	if err := store.InsertTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+
+	second := &transactions.TransactionAttempt{TransactionId: txId, FlowTransactionId: fmt.Sprintf("%064x", 2)}
+	if err := store.InsertAttempt(second); err != nil {
+		t.Fatal(err)
+	}
+
+	tx.Attempts = append(tx.Attempts, *second)
+	tx.Status = transactions.StatusPending
+	if err := store.UpdateTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+
+	second.Status = transactions.StatusSealed
+	if err := store.UpdateAttempt(second); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := store.Transaction(txId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stored.Attempts) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(stored.Attempts))
+	}
+
+	if stored.Attempts[1].FlowTransactionId != second.FlowTransactionId || stored.Attempts[1].Status != transactions.StatusSealed {
+		t.Fatalf("unexpected latest attempt: %+v", stored.Attempts[1])
+	}
+}
+
+func Test_TransactionStoreEvents(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	store := transactions.NewGormStore(test.GetDatabase(t, cfg))
+
+	eventType := "A.0ae53cb6e3f42a79.FlowToken.TokensDeposited"
+
+	event := cadence.NewEvent([]cadence.Value{cadence.UFix64(100000000)}).WithType(&cadence.EventType{
+		Location:            common.AddressLocation{Address: common.MustBytesToAddress(flow.HexToAddress("0ae53cb6e3f42a79").Bytes()), Name: "FlowToken"},
+		QualifiedIdentifier: "FlowToken.TokensDeposited",
+		Fields:              []cadence.Field{{Identifier: "amount", Type: cadence.UFix64Type{}}},
+	})
+
+	payload, err := jsoncdc.Encode(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		tx := &transactions.Transaction{TransactionId: fmt.Sprintf("%064x", i), Status: transactions.StatusSealed}
+		if err := store.InsertTransaction(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	txId := fmt.Sprintf("%064x", 1)
+	events := []transactions.TransactionEvent{{TransactionId: txId, FlowTransactionId: txId, Type: eventType, Payload: payload}}
+
+	// Replacing twice should not duplicate events
+	for i := 0; i < 2; i++ {
+		if err := store.ReplaceEvents(txId, events); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tt, err := store.TransactionsByEventType(eventType, datastore.ParseListOptions(0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tt) != 1 || tt[0].TransactionId != txId {
+		t.Fatalf("expected only transaction %s, got %+v", txId, tt)
+	}
+
+	res := tt[0].ToJSONResponse()
+
+	if len(res.Events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(res.Events))
+	}
+
+	if res.Events[0].Type != eventType || res.Events[0].Value.Fields[0] != cadence.UFix64(100000000) {
+		t.Fatalf("unexpected stored event: %+v", res.Events[0])
+	}
+}
+
+func Test_TransactionWithMultipleAuthorizers(t *testing.T) {
+	ctx := context.Background()
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+	txSvc := svcs.GetTransactions()
+
+	_, acc1, err := svcs.GetAccounts().Create(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, acc2, err := svcs.GetAccounts().Create(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := "transaction() { prepare(a: AuthAccount, b: AuthAccount, c: AuthAccount){} execute {}}"
+
+	t.Run("custodial accounts and admin co-sign", func(t *testing.T) {
+		_, tx, err := txSvc.CreateWithAuthorizers(ctx, true, []string{acc1.Address, acc2.Address, cfg.AdminAddress}, code, nil, transactions.General)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		flowTx, err := flow.DecodeTransaction(tx.FlowTransaction)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(flowTx.Authorizers) != 3 {
+			t.Fatalf("expected 3 authorizers, got %d", len(flowTx.Authorizers))
+		}
+
+		if flowTx.ProposalKey.Address != flow.HexToAddress(acc1.Address) {
+			t.Fatalf("expected first authorizer to be the proposer, got %s", flowTx.ProposalKey.Address)
+		}
+
+		for _, address := range []string{acc1.Address, acc2.Address} {
+			if !addressExists(address, flowTx.PayloadSignatures) {
+				t.Fatalf("expected a payload signature from %s", address)
+			}
+		}
+	})
+
+	t.Run("duplicate authorizer fails", func(t *testing.T) {
+		_, _, err := txSvc.CreateWithAuthorizers(ctx, true, []string{acc1.Address, acc1.Address}, code, nil, transactions.General)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+
+	t.Run("non-custodial authorizer fails", func(t *testing.T) {
+		_, _, err := txSvc.CreateWithAuthorizers(ctx, true, []string{acc1.Address, "0x01cf0e2f2f715450"}, code, nil, transactions.General)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
+
+func Test_TransactionStoreAllowedCodes(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	store := transactions.NewGormStore(test.GetDatabase(t, cfg))
+
+	hash := transactions.CodeHash("transaction() {}")
+
+	entries := []transactions.AllowedCode{
+		{Hash: hash},
+		{Hash: hash, Address: "0x01cf0e2f2f715450"},
+		{Hash: transactions.CodeHash("transaction() { execute {} }")},
+	}
+	for i := range entries {
+		if err := store.InsertAllowedCode(&entries[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := store.InsertAllowedCode(&transactions.AllowedCode{Hash: hash}); err == nil {
+		t.Fatal("expected duplicate allowlist entry to fail")
+	}
+
+	byHash, err := store.AllowedCodes(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byHash) != 2 {
+		t.Fatalf("expected 2 entries for hash, got %d", len(byHash))
+	}
+
+	if err := store.DeleteAllowedCode(entries[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.DeleteAllowedCode(entries[0].ID); err == nil {
+		t.Fatal("expected deleting a removed entry to fail")
+	}
+
+	all, err := store.AllowedCodes("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(all))
+	}
+}
+
+func Test_TransactionServiceEnforcesCodeAllowlist(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	cfg.EnableCodeAllowlist = true
+	txSvc := test.GetServices(t, cfg).GetTransactions()
+
+	ctx := context.Background()
+	code := "transaction() { prepare(signer: AuthAccount){} execute {}}"
+
+	var reqErr *wallet_errors.RequestError
+
+	_, err := txSvc.Sign(ctx, cfg.AdminAddress, code, nil)
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected a 403 error, got %v", err)
+	}
+
+	_, _, err = txSvc.Create(ctx, true, cfg.AdminAddress, code, nil, transactions.General)
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected a 403 error, got %v", err)
+	}
+
+	err = txSvc.RemoveAllowedCode(12345)
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected a 404 error, got %v", err)
+	}
+}
@@ -1,6 +1,8 @@
 package tokens
 
 import (
+	"context"
+
 	"github.com/flow-hydraulics/flow-wallet-api/accounts"
 	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
 	"github.com/flow-hydraulics/flow-wallet-api/templates"
@@ -14,6 +16,14 @@ type AccountAddedHandler struct {
 
 func (h *AccountAddedHandler) Handle(payload accounts.AccountAddedPayload) {
 	address := flow_helpers.FormatAddress(payload.Address)
+	if payload.NonCustodial {
+		if _, err := h.TokenService.DiscoverAccountTokens(context.Background(), address); err != nil {
+			log.
+				WithFields(log.Fields{"error": err, "address": address}).
+				Warn("Error while discovering tokens of watchlist account")
+		}
+		return
+	}
 	h.addToken("FlowToken", address)
 	for _, t := range payload.InitializedFungibleTokens {
 		h.addToken(t.Name, address)
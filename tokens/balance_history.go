@@ -0,0 +1,268 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+)
+
+// Sources of balance snapshots
+const (
+	BalanceSnapshotSourcePeriodic = "periodic"
+	BalanceSnapshotSourceEvent    = "event"
+)
+
+// BalanceSnapshot is the fungible token balance of an account at a block height.
+type BalanceSnapshot struct {
+	ID             uint64    `json:"-" gorm:"column:id;primaryKey"`
+	AccountAddress string    `json:"-" gorm:"column:account_address;uniqueIndex:idx_balance_snapshots_account_token_height;not null"`
+	TokenName      string    `json:"token" gorm:"column:token_name;uniqueIndex:idx_balance_snapshots_account_token_height;not null"`
+	BlockHeight    uint64    `json:"blockHeight" gorm:"column:block_height;uniqueIndex:idx_balance_snapshots_account_token_height;not null"`
+	BlockTimestamp time.Time `json:"blockTimestamp" gorm:"column:block_timestamp;index"`
+	Balance        string    `json:"balance" gorm:"column:balance"`
+	Source         string    `json:"source" gorm:"column:source"`
+	CreatedAt      time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (BalanceSnapshot) TableName() string {
+	return "balance_snapshots"
+}
+
+// BalanceHistory returns the balance snapshots of a fungible token of an
+// account with a block timestamp between from and to, oldest first. A zero
+// from or to leaves the range open. The last snapshot before from is included
+// as well, as it holds the balance at from.
+func (s *ServiceImpl) BalanceHistory(tokenName, address string, from, to time.Time) ([]BalanceSnapshot, error) {
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.templates.GetTokenByName(tokenName)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Type != templates.FT {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("balance history is only kept for fungible tokens"),
+		}
+	}
+
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("to is before from"),
+		}
+	}
+
+	snapshots, err := s.store.BalanceSnapshots(address, token.Name, from, to)
+	if err != nil || from.IsZero() {
+		return snapshots, err
+	}
+
+	last, err := s.store.LastBalanceSnapshot(address, token.Name, from)
+	if err != nil {
+		if err.Error() == "record not found" {
+			return snapshots, nil
+		}
+		return nil, err
+	}
+
+	return append([]BalanceSnapshot{last}, snapshots...), nil
+}
+
+// SnapshotBalances stores a snapshot of the balance of every fungible token
+// enabled for an account at the latest sealed block. Returns the number of
+// snapshots taken.
+func (s *ServiceImpl) SnapshotBalances(ctx context.Context) (int, error) {
+	accountTokens, err := s.store.AccountTokensByType(templates.FT)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(accountTokens) == 0 {
+		return 0, nil
+	}
+
+	block, err := s.fc.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return 0, err
+	}
+
+	taken := 0
+	for _, at := range accountTokens {
+		token, err := s.templates.GetTokenByName(at.TokenName)
+		if err != nil {
+			// Token has been removed since it was enabled for the account
+			continue
+		}
+
+		if _, err := s.snapshotBalance(ctx, block, token, at.AccountAddress, BalanceSnapshotSourcePeriodic); err != nil {
+			if wallet_errors.IsChainConnectionError(err) {
+				return taken, err
+			}
+			log.
+				WithFields(log.Fields{"error": err, "address": at.AccountAddress, "token": token.Name}).
+				Warn("Could not take balance snapshot")
+			continue
+		}
+
+		taken++
+	}
+
+	return taken, nil
+}
+
+// snapshotBalanceOnEvent takes a snapshot of the balance of an account at the
+// block of a transfer of a fungible token, if enabled. Called when a transfer
+// is seen on chain or a withdrawal sent by the service has been sealed.
+func (s *ServiceImpl) snapshotBalanceOnEvent(ctx context.Context, token *templates.Token, address string, transactionId flow.Identifier) {
+	if !s.cfg.BalanceSnapshotOnEvents || token.Type != templates.FT {
+		return
+	}
+
+	block, err := s.transactionBlock(ctx, transactionId)
+	if err == nil {
+		_, err = s.snapshotBalance(ctx, block, token, address, BalanceSnapshotSourceEvent)
+	}
+
+	if err != nil {
+		log.
+			WithFields(log.Fields{"error": err, "address": address, "token": token.Name, "transactionId": transactionId.Hex()}).
+			Warn("Could not take balance snapshot")
+	}
+}
+
+// transactionBlock returns the header of the block a transaction was
+// executed in.
+func (s *ServiceImpl) transactionBlock(ctx context.Context, transactionId flow.Identifier) (*flow.BlockHeader, error) {
+	result, err := s.fc.GetTransactionResult(ctx, transactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.BlockID == flow.EmptyID {
+		return nil, fmt.Errorf("transaction %s has not been executed", transactionId.Hex())
+	}
+
+	return s.fc.GetBlockHeaderByID(ctx, result.BlockID)
+}
+
+func (s *ServiceImpl) snapshotBalance(ctx context.Context, block *flow.BlockHeader, token *templates.Token, address, source string) (*BalanceSnapshot, error) {
+	value, err := s.transactions.ExecuteScriptAtBlock(
+		ctx,
+		token.Balance,
+		[]transactions.Argument{cadence.NewAddress(flow.HexToAddress(address))},
+		transactions.ScriptBlock{Height: block.Height},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, ok := value.(cadence.UFix64)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %s balance type: %s", token.Name, value.Type().ID())
+	}
+
+	snapshot := &BalanceSnapshot{
+		AccountAddress: address,
+		TokenName:      token.Name,
+		BlockHeight:    block.Height,
+		BlockTimestamp: block.Timestamp,
+		Balance:        balance.String(),
+		Source:         source,
+	}
+
+	if err := s.store.InsertBalanceSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// BalanceSnapshotter takes snapshots of the fungible token balances of all
+// accounts in the background.
+type BalanceSnapshotter struct {
+	svc      Service
+	interval time.Duration
+
+	ticker      *time.Ticker
+	tickerMutex sync.Mutex
+	stopChan    chan struct{}
+	stopOnce    sync.Once
+}
+
+// NewBalanceSnapshotter initiates a new balance snapshotter taking snapshots
+// every interval.
+func NewBalanceSnapshotter(svc Service, interval time.Duration) *BalanceSnapshotter {
+	return &BalanceSnapshotter{
+		svc:      svc,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+func (b *BalanceSnapshotter) Start() {
+	b.tickerMutex.Lock()
+	defer b.tickerMutex.Unlock()
+
+	if b.ticker != nil {
+		// Already started
+		return
+	}
+
+	b.ticker = time.NewTicker(b.interval)
+
+	go func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		entry := log.WithFields(log.Fields{
+			"package":  "tokens",
+			"function": "BalanceSnapshotter.Start.goroutine",
+		})
+
+		for {
+			select {
+			case <-b.stopChan:
+				return
+			case <-b.ticker.C:
+				taken, err := b.svc.SnapshotBalances(ctx)
+				if err != nil {
+					entry.
+						WithFields(log.Fields{"error": err}).
+						Warn("Error while taking balance snapshots")
+				}
+				entry.
+					WithFields(log.Fields{"snapshots": taken}).
+					Debug("Took balance snapshots")
+			}
+		}
+	}()
+}
+
+func (b *BalanceSnapshotter) Stop() {
+	b.tickerMutex.Lock()
+	defer b.tickerMutex.Unlock()
+
+	b.stopOnce.Do(func() {
+		close(b.stopChan)
+	})
+
+	if b.ticker != nil {
+		b.ticker.Stop()
+	}
+}
@@ -2,12 +2,12 @@ package tokens
 
 import (
 	"context"
-	"strings"
 
 	"github.com/flow-hydraulics/flow-wallet-api/accounts"
 	"github.com/flow-hydraulics/flow-wallet-api/chain_events"
 	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
 	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
 	log "github.com/sirupsen/logrus"
 )
@@ -20,9 +20,11 @@ type ChainEventHandler struct {
 }
 
 func (h *ChainEventHandler) Handle(ctx context.Context, event flow.Event) {
-	isDeposit := strings.Contains(event.Type, "Deposit")
-	if isDeposit {
+	switch templates.EventName(event.Type) {
+	case templates.EventTokensDeposited, templates.EventDeposit:
 		h.handleDeposit(ctx, event)
+	case templates.EventTokensWithdrawn, templates.EventWithdraw:
+		h.handleWithdrawal(ctx, event)
 	}
 }
 
@@ -40,6 +42,8 @@ func (h *ChainEventHandler) handleDeposit(ctx context.Context, event flow.Event)
 	amountOrNftID := event.Value.Fields[0]
 	accountAddress := event.Value.Fields[1]
 
+	h.invalidateNftItem(token, amountOrNftID)
+
 	// Get the target account from database
 	account, err := h.AccountService.Details(flow_helpers.HexString(accountAddress.String()))
 	if err != nil {
@@ -61,3 +65,78 @@ func (h *ChainEventHandler) handleDeposit(ctx context.Context, event flow.Event)
 		}).
 		Debug("New deposit")
 }
+
+func (h *ChainEventHandler) handleWithdrawal(ctx context.Context, event flow.Event) {
+	token, err := h.TemplateService.TokenFromEvent(event)
+	if err != nil {
+		log.
+			WithFields(log.Fields{"error": err}).
+			Warn("Failed to extract token from event")
+		return
+	}
+
+	amountOrNftID := event.Value.Fields[0]
+
+	h.invalidateNftItem(token, amountOrNftID)
+
+	// Tokens withdrawn from vaults without an owner have no address
+	address, ok := eventAddress(event.Value.Fields[1])
+	if !ok {
+		return
+	}
+
+	// Withdrawals of custodial accounts are recorded when they are sent,
+	// only record withdrawals of watchlist accounts
+	account, err := h.AccountService.Details(address)
+	if err != nil || account.Type != accounts.AccountTypeNonCustodial {
+		return
+	}
+
+	if err = h.TokenService.RegisterWithdrawal(ctx, token, event.TransactionID, account, amountOrNftID.String()); err != nil {
+		log.
+			WithFields(log.Fields{"error": err}).
+			Warn("Error while registering a withdrawal")
+		return
+	}
+
+	log.
+		WithFields(log.Fields{
+			"token":         token.Name,
+			"account":       address,
+			"amountOrNftID": amountOrNftID,
+		}).
+		Debug("New withdrawal")
+}
+
+// invalidateNftItem drops the cached metadata of an NFT which changed hands,
+// whether or not the accounts involved are known to the wallet.
+func (h *ChainEventHandler) invalidateNftItem(token *templates.Token, nftID cadence.Value) {
+	if token.Type != templates.NFT {
+		return
+	}
+
+	id, ok := nftID.(cadence.UInt64)
+	if !ok {
+		return
+	}
+
+	if err := h.TokenService.InvalidateNftItem(token.Name, uint64(id)); err != nil {
+		log.
+			WithFields(log.Fields{"error": err, "token": token.Name, "nftId": uint64(id)}).
+			Warn("Error while invalidating cached NFT metadata")
+	}
+}
+
+// eventAddress returns the address of an optional address field of an event.
+func eventAddress(v cadence.Value) (string, bool) {
+	if o, ok := v.(cadence.Optional); ok {
+		v = o.Value
+	}
+
+	a, ok := v.(cadence.Address)
+	if !ok {
+		return "", false
+	}
+
+	return flow_helpers.FormatAddress(flow.Address(a)), true
+}
@@ -26,7 +26,7 @@ func (s *ServiceImpl) executeCreateWithdrawalJob(ctx context.Context, j *jobs.Jo
 		return err
 	}
 
-	transaction, err := s.createWithdrawal(ctx, attrs.Sender, attrs.Request)
+	transaction, err := s.createWithdrawal(ctx, attrs.Sender, attrs.Request, s.cfg.EnableSealTracker)
 	if err != nil {
 		return err
 	}
@@ -34,5 +34,10 @@ func (s *ServiceImpl) executeCreateWithdrawalJob(ctx context.Context, j *jobs.Jo
 	j.TransactionID = transaction.TransactionId
 	j.Result = transaction.TransactionId
 
+	if s.cfg.EnableSealTracker {
+		// Seal tracker resolves the job
+		j.State = jobs.AwaitingResult
+	}
+
 	return nil
 }
@@ -0,0 +1,391 @@
+package tokens
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+)
+
+// NftItem is an NFT owned by an account with its resolved metadata views.
+// Views lists the identifiers of all views the NFT resolves.
+type NftItem struct {
+	ID          uint64       `json:"id"`
+	Name        string       `json:"name,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Thumbnail   string       `json:"thumbnail,omitempty"`
+	ExternalURL string       `json:"externalUrl,omitempty"`
+	Serial      *uint64      `json:"serial,omitempty"`
+	Editions    []NftEdition `json:"editions,omitempty"`
+	Royalties   []NftRoyalty `json:"royalties,omitempty"`
+	Views       []string     `json:"views"`
+}
+
+// NftEdition is an edition of an NFT from the MetadataViews.Editions view.
+type NftEdition struct {
+	Name   string  `json:"name,omitempty"`
+	Number uint64  `json:"number"`
+	Max    *uint64 `json:"max,omitempty"`
+}
+
+// NftRoyalty is a royalty of an NFT from the MetadataViews.Royalties view.
+type NftRoyalty struct {
+	Receiver    string `json:"receiver"`
+	Cut         string `json:"cut"`
+	Description string `json:"description,omitempty"`
+}
+
+// CachedNftItem is the resolved metadata of an NFT, cached per token and NFT
+// ID along with the account owning the NFT when it was resolved.
+type CachedNftItem struct {
+	ID           uint64    `gorm:"column:id;primaryKey"`
+	TokenName    string    `gorm:"column:token_name;uniqueIndex:idx_nft_metadata_cache_token_nft;not null"`
+	NftID        uint64    `gorm:"column:nft_id;uniqueIndex:idx_nft_metadata_cache_token_nft;not null"`
+	OwnerAddress string    `gorm:"column:owner_address"`
+	Item         string    `gorm:"column:item"` // JSON encoded NftItem
+	CreatedAt    time.Time `gorm:"column:created_at"`
+	UpdatedAt    time.Time `gorm:"column:updated_at"`
+}
+
+func (CachedNftItem) TableName() string {
+	return "nft_metadata_cache"
+}
+
+// NftItems lists the NFTs of a token owned by an account ordered by ID, with
+// their metadata views resolved from chain or from the cache. NFTs whose
+// metadata can not be resolved, e.g. because the collection does not
+// implement MetadataViews, are listed without metadata.
+func (s *ServiceImpl) NftItems(ctx context.Context, tokenName, address string, limit, offset int) ([]NftItem, error) {
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.templates.GetTokenByName(tokenName)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Type != templates.NFT {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("items are only listed for non-fungible tokens"),
+		}
+	}
+
+	// The balance script of an NFT returns the IDs in the collection
+	value, err := s.transactions.ExecuteScript(ctx, token.Balance, []transactions.Argument{cadence.NewAddress(flow.HexToAddress(address))})
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := nftIDs(token, value)
+	if err != nil {
+		return nil, err
+	}
+
+	ids = paginateIDs(ids, datastore.ParseListOptions(limit, offset))
+
+	items, err := s.cachedNftItems(token.Name, address, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := items[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+
+	resolved, err := s.resolveNftItems(ctx, token, address, missing)
+	if err != nil {
+		if wallet_errors.IsChainConnectionError(err) {
+			return nil, err
+		}
+		log.
+			WithFields(log.Fields{"error": err, "address": address, "token": token.Name}).
+			Warn("Could not resolve NFT metadata, listing NFTs without metadata")
+	}
+
+	for _, item := range resolved {
+		items[item.ID] = item
+	}
+
+	if err := s.cacheNftItems(token.Name, address, resolved); err != nil {
+		log.
+			WithFields(log.Fields{"error": err, "token": token.Name}).
+			Warn("Could not cache NFT metadata")
+	}
+
+	res := make([]NftItem, len(ids))
+	for i, id := range ids {
+		item, ok := items[id]
+		if !ok {
+			item = NftItem{ID: id, Views: []string{}}
+		}
+		res[i] = item
+	}
+
+	return res, nil
+}
+
+// InvalidateNftItem drops the cached metadata of an NFT.
+func (s *ServiceImpl) InvalidateNftItem(tokenName string, nftID uint64) error {
+	return s.store.DeleteCachedNftItem(tokenName, nftID)
+}
+
+// cachedNftItems returns the unexpired cached metadata of NFTs resolved while
+// owned by the account.
+func (s *ServiceImpl) cachedNftItems(tokenName, address string, ids []uint64) (map[uint64]NftItem, error) {
+	items := make(map[uint64]NftItem, len(ids))
+
+	if s.cfg.NftMetadataCacheTTL <= 0 || len(ids) == 0 {
+		return items, nil
+	}
+
+	cached, err := s.store.CachedNftItems(tokenName, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	expired := time.Now().Add(-s.cfg.NftMetadataCacheTTL)
+	for _, c := range cached {
+		if c.OwnerAddress != address || c.UpdatedAt.Before(expired) {
+			continue
+		}
+
+		var item NftItem
+		if err := json.Unmarshal([]byte(c.Item), &item); err != nil {
+			continue
+		}
+		items[c.NftID] = item
+	}
+
+	return items, nil
+}
+
+func (s *ServiceImpl) cacheNftItems(tokenName, address string, items []NftItem) error {
+	if s.cfg.NftMetadataCacheTTL <= 0 || len(items) == 0 {
+		return nil
+	}
+
+	cached := make([]CachedNftItem, len(items))
+	for i, item := range items {
+		b, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		cached[i] = CachedNftItem{
+			TokenName:    tokenName,
+			NftID:        item.ID,
+			OwnerAddress: address,
+			Item:         string(b),
+		}
+	}
+
+	return s.store.SaveCachedNftItems(cached)
+}
+
+// resolveNftItems resolves the metadata views of NFTs of an account, with one
+// script per batch of NFTs. Returns the NFTs resolved before an error.
+func (s *ServiceImpl) resolveNftItems(ctx context.Context, token *templates.Token, address string, ids []uint64) ([]NftItem, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	code, err := templates.NFTItemsCode(s.cfg.ChainID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := int(s.cfg.NftMetadataBatchSize)
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	items := make([]NftItem, 0, len(ids))
+
+	for start := 0; start < len(ids); start += batchSize {
+		end := start + batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		values := make([]cadence.Value, 0, end-start)
+		for _, id := range ids[start:end] {
+			values = append(values, cadence.NewUInt64(id))
+		}
+
+		value, err := s.transactions.ExecuteScript(ctx, code, []transactions.Argument{
+			cadence.NewAddress(flow.HexToAddress(address)),
+			cadence.NewArray(values),
+		})
+		if err != nil {
+			return items, err
+		}
+
+		arr, ok := value.(cadence.Array)
+		if !ok {
+			return items, fmt.Errorf("unexpected NFT items type: %s", value.Type().ID())
+		}
+
+		for _, v := range arr.Values {
+			item, err := decodeNftItem(v)
+			if err != nil {
+				return items, err
+			}
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+// nftIDs returns the IDs returned by the balance script of an NFT, sorted.
+func nftIDs(token *templates.Token, value cadence.Value) ([]uint64, error) {
+	arr, ok := value.(cadence.Array)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %s balance type: %s", token.Name, value.Type().ID())
+	}
+
+	ids := make([]uint64, len(arr.Values))
+	for i, v := range arr.Values {
+		id, ok := v.(cadence.UInt64)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %s NFT ID type: %s", token.Name, v.Type().ID())
+		}
+		ids[i] = uint64(id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	return ids, nil
+}
+
+func paginateIDs(ids []uint64, o datastore.ListOptions) []uint64 {
+	if o.Offset >= len(ids) {
+		return []uint64{}
+	}
+
+	ids = ids[o.Offset:]
+	if o.Limit >= 0 && o.Limit < len(ids) {
+		ids = ids[:o.Limit]
+	}
+
+	return ids
+}
+
+// decodeNftItem decodes an Item struct returned by the NFT items script.
+func decodeNftItem(v cadence.Value) (NftItem, error) {
+	fields, err := structFields(v)
+	if err != nil {
+		return NftItem{}, err
+	}
+
+	item := NftItem{
+		ID:          uint64Field(fields["id"]),
+		Name:        stringField(fields["name"]),
+		Description: stringField(fields["description"]),
+		Thumbnail:   stringField(fields["thumbnail"]),
+		ExternalURL: stringField(fields["externalURL"]),
+		Views:       []string{},
+	}
+
+	if serial, ok := optional(fields["serial"]).(cadence.UInt64); ok {
+		n := uint64(serial)
+		item.Serial = &n
+	}
+
+	if editions, ok := fields["editions"].(cadence.Array); ok {
+		for _, e := range editions.Values {
+			ef, err := structFields(e)
+			if err != nil {
+				return NftItem{}, err
+			}
+			edition := NftEdition{
+				Name:   stringField(ef["name"]),
+				Number: uint64Field(ef["number"]),
+			}
+			if max, ok := optional(ef["max"]).(cadence.UInt64); ok {
+				n := uint64(max)
+				edition.Max = &n
+			}
+			item.Editions = append(item.Editions, edition)
+		}
+	}
+
+	if royalties, ok := fields["royalties"].(cadence.Array); ok {
+		for _, r := range royalties.Values {
+			rf, err := structFields(r)
+			if err != nil {
+				return NftItem{}, err
+			}
+			royalty := NftRoyalty{Description: stringField(rf["description"])}
+			if receiver, ok := rf["receiver"].(cadence.Address); ok {
+				royalty.Receiver = flow_helpers.FormatAddress(flow.Address(receiver))
+			}
+			if cut, ok := rf["cut"].(cadence.UFix64); ok {
+				royalty.Cut = cut.String()
+			}
+			item.Royalties = append(item.Royalties, royalty)
+		}
+	}
+
+	if views, ok := fields["views"].(cadence.Array); ok {
+		for _, view := range views.Values {
+			item.Views = append(item.Views, stringField(view))
+		}
+	}
+
+	return item, nil
+}
+
+func structFields(v cadence.Value) (map[string]cadence.Value, error) {
+	s, ok := v.(cadence.Struct)
+	if !ok || s.StructType == nil || len(s.StructType.Fields) != len(s.Fields) {
+		return nil, fmt.Errorf("unexpected NFT metadata type: %s", v.Type().ID())
+	}
+
+	fields := make(map[string]cadence.Value, len(s.Fields))
+	for i, f := range s.StructType.Fields {
+		fields[f.Identifier] = s.Fields[i]
+	}
+
+	return fields, nil
+}
+
+func optional(v cadence.Value) cadence.Value {
+	if o, ok := v.(cadence.Optional); ok {
+		return o.Value
+	}
+	return v
+}
+
+func stringField(v cadence.Value) string {
+	if s, ok := optional(v).(cadence.String); ok {
+		return string(s)
+	}
+	return ""
+}
+
+func uint64Field(v cadence.Value) uint64 {
+	if n, ok := optional(v).(cadence.UInt64); ok {
+		return uint64(n)
+	}
+	return 0
+}
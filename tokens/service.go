@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/flow-hydraulics/flow-wallet-api/accounts"
 	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
 	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
 	"github.com/flow-hydraulics/flow-wallet-api/jobs"
 	"github.com/flow-hydraulics/flow-wallet-api/keys"
@@ -35,6 +38,18 @@ type Service interface {
 	GetWithdrawal(address, tokenName, transactionId string) (*TokenWithdrawal, error)
 	GetDeposit(address, tokenName, transactionId string) (*TokenDeposit, error)
 	RegisterDeposit(ctx context.Context, token *templates.Token, transactionId flow.Identifier, recipient accounts.Account, amountOrNftID string) error
+	// RegisterWithdrawal records a withdrawal of a watchlist account seen on chain.
+	RegisterWithdrawal(ctx context.Context, token *templates.Token, transactionId flow.Identifier, sender accounts.Account, amountOrNftID string) error
+	// BalanceHistory lists the balance snapshots of a fungible token of an account.
+	BalanceHistory(tokenName, address string, from, to time.Time) ([]BalanceSnapshot, error)
+	// SnapshotBalances takes a snapshot of the fungible token balances of all accounts.
+	SnapshotBalances(ctx context.Context) (int, error)
+	// DiscoverAccountTokens adds the enabled tokens an account holds to the account.
+	DiscoverAccountTokens(ctx context.Context, address string) ([]AccountToken, error)
+	// NftItems lists the NFTs of a token owned by an account with their metadata views.
+	NftItems(ctx context.Context, tokenName, address string, limit, offset int) ([]NftItem, error)
+	// InvalidateNftItem drops the cached metadata of an NFT.
+	InvalidateNftItem(tokenName string, nftID uint64) error
 
 	// DeployTokenContractForAccount is only used in tests
 	DeployTokenContractForAccount(ctx context.Context, runSync bool, tokenName, address string) error
@@ -83,11 +98,15 @@ func (s *ServiceImpl) Setup(ctx context.Context, sync bool, tokenName, address s
 	}
 
 	// Check if the account with given address exists
-	_, err = s.accounts.Details(address)
+	account, err := s.accounts.Details(address)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	if err := requireKeys(account); err != nil {
+		return nil, nil, err
+	}
+
 	token, err := s.templates.GetTokenByName(tokenName)
 	if err != nil {
 		return nil, nil, err
@@ -102,7 +121,7 @@ func (s *ServiceImpl) Setup(ctx context.Context, sync bool, tokenName, address s
 		txType = transactions.NftSetup
 	}
 
-	job, tx, err := s.transactions.Create(ctx, sync, address, token.Setup, nil, txType)
+	job, tx, err := s.transactions.Create(ctx, sync, address, token.Setup, nil, txType, transactions.TrustedCode())
 
 	if err == nil || strings.Contains(err.Error(), "vault exists") {
 		// Handle adding token to account in database
@@ -189,6 +208,13 @@ func (s *ServiceImpl) Details(ctx context.Context, tokenName, address string) (*
 func (s *ServiceImpl) CreateWithdrawal(ctx context.Context, sync bool, sender string, request WithdrawalRequest) (*jobs.Job, *transactions.Transaction, error) {
 	log.WithFields(log.Fields{"sync": sync}).Trace("Create withdrawal")
 
+	// Watchlist accounts can not send transactions, check before scheduling
+	if account, err := s.accounts.Details(sender); err == nil {
+		if err := requireKeys(account); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	if !sync {
 		// Async
 		attrs := withdrawalCreateJobAttributes{sender, request}
@@ -211,7 +237,7 @@ func (s *ServiceImpl) CreateWithdrawal(ctx context.Context, sync bool, sender st
 
 	} else {
 		// Sync
-		transaction, err := s.createWithdrawal(ctx, sender, request)
+		transaction, err := s.createWithdrawal(ctx, sender, request, false)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -332,41 +358,14 @@ func (s *ServiceImpl) GetDeposit(address, tokenName, transactionId string) (*Tok
 
 // RegisterDeposit is an internal API for registering token deposits from on-chain events.
 func (s *ServiceImpl) RegisterDeposit(ctx context.Context, token *templates.Token, transactionId flow.Identifier, recipient accounts.Account, amountOrNftID string) error {
-	var (
-		ftAmount string
-		nftId    uint64
-	)
-
-	switch token.Type {
-	case templates.FT:
-		ftAmount = amountOrNftID
-	case templates.NFT:
-		var err error
-		nftId, err = strconv.ParseUint(amountOrNftID, 10, 64)
-		if err != nil {
-			return err
-		}
-	default:
-		return fmt.Errorf("unsupported token type: %s", token.Type)
-	}
-
-	// TODO (latenssi): db lock for transaction; could it also allow "syncing" when running multiple instances?
-
-	// Get existing transaction or create one
-	transaction := s.transactions.GetOrCreateTransaction(transactionId.Hex())
-	flowTx, err := s.fc.GetTransaction(ctx, transactionId)
+	ftAmount, nftId, err := parseAmountOrNftID(token, amountOrNftID)
 	if err != nil {
 		return err
 	}
 
-	if transaction.TransactionType == transactions.Unknown {
-		// Transaction was just created
-		// Transfer most likely did not originate in this wallet service
-		transaction.TransactionType = transactions.FtTransfer
-		transaction.ProposerAddress = flow_helpers.FormatAddress(flowTx.ProposalKey.Address)
-		if err := s.transactions.UpdateTransaction(transaction); err != nil {
-			return err
-		}
+	transaction, flowTx, err := s.registerTransaction(ctx, token, transactionId)
+	if err != nil {
+		return err
 	}
 
 	// Make sure the token is enabled in the database for the recipient account
@@ -407,12 +406,241 @@ func (s *ServiceImpl) RegisterDeposit(ctx context.Context, token *templates.Toke
 		return err
 	}
 
+	if s.cfg.EnableDepositWebhooks {
+		n := DepositNotification{
+			Type:         DepositNotificationType,
+			TokenDeposit: transfer.Deposit(),
+			Recipient:    recipient.Address,
+			Metadata:     recipient.Metadata(),
+		}
+		if err := s.wp.ScheduleNotification(n); err != nil {
+			log.
+				WithFields(log.Fields{"error": err, "transactionId": transfer.TransactionId}).
+				Warn("Could not schedule a deposit notification")
+		}
+	}
+
+	s.snapshotBalanceOnEvent(ctx, token, recipient.Address, transactionId)
+
+	return nil
+}
+
+// RegisterWithdrawal records a withdrawal of a watchlist account seen on
+// chain. The recipient is resolved from the matching deposit event of the
+// same transaction, if there is one.
+func (s *ServiceImpl) RegisterWithdrawal(ctx context.Context, token *templates.Token, transactionId flow.Identifier, sender accounts.Account, amountOrNftID string) error {
+	ftAmount, nftId, err := parseAmountOrNftID(token, amountOrNftID)
+	if err != nil {
+		return err
+	}
+
+	transaction, _, err := s.registerTransaction(ctx, token, transactionId)
+	if err != nil {
+		return err
+	}
+
+	// The token must be set up for the sender since it was withdrawn from it
+	err = s.store.InsertAccountToken(&AccountToken{
+		AccountAddress: sender.Address,
+		TokenAddress:   token.Address,
+		TokenName:      token.Name,
+		TokenType:      token.Type,
+	})
+	if err != nil {
+		return err
+	}
+
+	recipient, err := s.withdrawalRecipient(ctx, token, transactionId, amountOrNftID)
+	if err != nil {
+		return err
+	}
+
+	transfer := &TokenTransfer{
+		TransactionId:    transaction.TransactionId,
+		RecipientAddress: recipient,
+		SenderAddress:    sender.Address,
+		FtAmount:         ftAmount,
+		NftID:            nftId,
+		TokenName:        token.Name,
+	}
+
+	// Check for an existing withdrawal, the same events may be handled again
+	exists, err := s.store.TokenTransferExists(transfer)
+	if err != nil || exists {
+		return err
+	}
+
+	if err := s.store.InsertTokenTransfer(transfer); err != nil {
+		return err
+	}
+
+	s.snapshotBalanceOnEvent(ctx, token, sender.Address, transactionId)
+
+	return nil
+}
+
+// DiscoverAccountTokens checks which enabled tokens an account holds a vault
+// or collection of by executing the balance scripts of the tokens, and adds
+// the held tokens to the account. No keys are needed, so this works for
+// watchlist accounts.
+func (s *ServiceImpl) DiscoverAccountTokens(ctx context.Context, address string) ([]AccountToken, error) {
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if the account with given address exists
+	if _, err := s.accounts.Details(address); err != nil {
+		return nil, err
+	}
+
+	tt, err := s.templates.ListTokensFull(templates.NotSpecified)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, token := range tt {
+		if token.Balance == "" {
+			continue
+		}
+
+		// The balance script fails if the account has no vault or collection
+		_, err := s.transactions.ExecuteScript(ctx, token.Balance, []transactions.Argument{cadence.NewAddress(flow.HexToAddress(address))})
+		if err != nil {
+			if wallet_errors.IsChainConnectionError(err) {
+				return nil, err
+			}
+			continue
+		}
+
+		if err := s.store.InsertAccountToken(&AccountToken{
+			AccountAddress: address,
+			TokenAddress:   token.Address,
+			TokenName:      token.Name,
+			TokenType:      token.Type,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.store.AccountTokens(address, templates.NotSpecified)
+}
+
+// registerTransaction gets or creates the transaction of a transfer seen on
+// chain. A transfer which did not originate in this wallet service is stored
+// as a transfer of the type of the token.
+func (s *ServiceImpl) registerTransaction(ctx context.Context, token *templates.Token, transactionId flow.Identifier) (*transactions.Transaction, *flow.Transaction, error) {
+	// TODO (latenssi): db lock for transaction; could it also allow "syncing" when running multiple instances?
+
+	// Get existing transaction or create one
+	transaction := s.transactions.GetOrCreateTransaction(transactionId.Hex())
+	flowTx, err := s.fc.GetTransaction(ctx, transactionId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if transaction.TransactionType == transactions.Unknown {
+		// Transaction was just created
+		// Transfer most likely did not originate in this wallet service
+		transaction.TransactionType = transactions.FtTransfer
+		if token.Type == templates.NFT {
+			transaction.TransactionType = transactions.NftTransfer
+		}
+		transaction.ProposerAddress = flow_helpers.FormatAddress(flowTx.ProposalKey.Address)
+		s.setTransactionBlock(ctx, transaction, transactionId)
+		if err := s.transactions.UpdateTransaction(transaction); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return transaction, flowTx, nil
+}
+
+// setTransactionBlock records the block of a transaction seen on chain, so
+// transfers which did not originate in this wallet service can be placed by
+// block height when reconciling balances.
+func (s *ServiceImpl) setTransactionBlock(ctx context.Context, transaction *transactions.Transaction, transactionId flow.Identifier) {
+	result, err := s.fc.GetTransactionResult(ctx, transactionId)
+	if err == nil && result.BlockID != flow.EmptyID {
+		transaction.BlockId = result.BlockID.Hex()
+
+		var header *flow.BlockHeader
+		header, err = s.fc.GetBlockHeaderByID(ctx, result.BlockID)
+		if err == nil {
+			transaction.BlockHeight = header.Height
+		}
+	}
+
+	if err != nil {
+		log.
+			WithFields(log.Fields{"error": err, "transactionId": transaction.TransactionId}).
+			Warn("Could not get block of transaction")
+	}
+}
+
+// withdrawalRecipient returns the address of the first deposit of the
+// withdrawn amount or NFT of a token in a transaction, or an empty string if
+// there is no such deposit to an account.
+func (s *ServiceImpl) withdrawalRecipient(ctx context.Context, token *templates.Token, transactionId flow.Identifier, amountOrNftID string) (string, error) {
+	result, err := s.fc.GetTransactionResult(ctx, transactionId)
+	if err != nil {
+		return "", err
+	}
+
+	depositType := templates.DepositEventTypeFromToken(templates.BasicToken{
+		Name:    token.Name,
+		Address: flow_helpers.FormatAddress(flow.HexToAddress(token.Address)),
+		Type:    token.Type,
+	})
+
+	for _, e := range result.Events {
+		if e.Type != depositType || len(e.Value.Fields) < 2 {
+			continue
+		}
+		if e.Value.Fields[0].String() != amountOrNftID {
+			continue
+		}
+		if address, ok := eventAddress(e.Value.Fields[1]); ok {
+			return address, nil
+		}
+	}
+
+	return "", nil
+}
+
+// requireKeys returns an error if the wallet holds no keys for the account.
+func requireKeys(a accounts.Account) error {
+	if a.Type == accounts.AccountTypeNonCustodial {
+		return &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("watchlist account has no keys"),
+		}
+	}
 	return nil
 }
 
+func parseAmountOrNftID(token *templates.Token, amountOrNftID string) (string, uint64, error) {
+	switch token.Type {
+	case templates.FT:
+		return amountOrNftID, 0, nil
+	case templates.NFT:
+		nftId, err := strconv.ParseUint(amountOrNftID, 10, 64)
+		if err != nil {
+			return "", 0, err
+		}
+		return "", nftId, nil
+	default:
+		return "", 0, fmt.Errorf("unsupported token type: %s", token.Type)
+	}
+}
+
 // createWithdrawal will synchronously create a withdrawal and store the transfer.
 // Used in job execution and sync API calls.
-func (s *ServiceImpl) createWithdrawal(ctx context.Context, sender string, request WithdrawalRequest) (*transactions.Transaction, error) {
+// createWithdrawal sends a withdrawal transaction and stores the transfer.
+// If submitOnly is true the transaction is not waited on but left for the
+// seal tracker to resolve.
+func (s *ServiceImpl) createWithdrawal(ctx context.Context, sender string, request WithdrawalRequest, submitOnly bool) (*transactions.Transaction, error) {
 	// Check if the sender is a valid address
 	sender, err := flow_helpers.ValidateAddress(sender, s.cfg.ChainID)
 	if err != nil {
@@ -450,8 +678,13 @@ func (s *ServiceImpl) createWithdrawal(ctx context.Context, sender string, reque
 		return nil, fmt.Errorf("createWithdrawal unsupported token type: %s", token.Type)
 	}
 
-	// Create the transaction, must be sync here
-	_, transaction, err := s.transactions.Create(ctx, true, sender, token.Transfer, arguments, txType)
+	var transaction *transactions.Transaction
+	if submitOnly {
+		transaction, err = s.transactions.Submit(ctx, sender, token.Transfer, arguments, txType, transactions.TrustedCode())
+	} else {
+		// Create the transaction, must be sync here
+		_, transaction, err = s.transactions.Create(ctx, true, sender, token.Transfer, arguments, txType, transactions.TrustedCode())
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -470,5 +703,9 @@ func (s *ServiceImpl) createWithdrawal(ctx context.Context, sender string, reque
 		return nil, err
 	}
 
+	if !submitOnly {
+		s.snapshotBalanceOnEvent(ctx, token, sender, flow.HexToID(transaction.TransactionId))
+	}
+
 	return transaction, nil
 }
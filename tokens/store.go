@@ -1,18 +1,45 @@
 package tokens
 
-import "github.com/flow-hydraulics/flow-wallet-api/templates"
+import (
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+)
 
 // Store manages data regarding tokens.
 type Store interface {
 	// List an accounts enabled tokens
 	AccountTokens(address string, tokenType templates.TokenType) ([]AccountToken, error)
 
+	// List the enabled tokens of a type of all accounts
+	AccountTokensByType(tokenType templates.TokenType) ([]AccountToken, error)
+
 	// Enable a token for an account
 	InsertAccountToken(at *AccountToken) error
 
+	// Store a balance snapshot, snapshots of the same account, token and
+	// block height are ignored
+	InsertBalanceSnapshot(*BalanceSnapshot) error
+	// List the balance snapshots of a token of an account by block timestamp
+	BalanceSnapshots(address, tokenName string, from, to time.Time) ([]BalanceSnapshot, error)
+	// Get the last balance snapshot of a token of an account with a block
+	// timestamp before the given time
+	LastBalanceSnapshot(address, tokenName string, before time.Time) (BalanceSnapshot, error)
+
 	InsertTokenTransfer(*TokenTransfer) error
+	// Check whether a transfer with the same transaction, addresses, token
+	// and amount or NFT ID exists
+	TokenTransferExists(*TokenTransfer) (bool, error)
+	DeleteTokenTransfer(transactionId string) error
 	TokenWithdrawals(address string, token *templates.Token) ([]*TokenTransfer, error)
 	TokenWithdrawal(address, transactionId string, token *templates.Token) (*TokenTransfer, error)
 	TokenDeposits(address string, token *templates.Token) ([]*TokenTransfer, error)
 	TokenDeposit(address, transactionId string, token *templates.Token) (*TokenTransfer, error)
+
+	// List the cached metadata of NFTs of a token
+	CachedNftItems(tokenName string, nftIDs []uint64) ([]CachedNftItem, error)
+	// Insert or replace the cached metadata of NFTs
+	SaveCachedNftItems([]CachedNftItem) error
+	// Drop the cached metadata of an NFT
+	DeleteCachedNftItem(tokenName string, nftID uint64) error
 }
@@ -2,6 +2,7 @@ package tokens
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/flow-hydraulics/flow-wallet-api/templates"
 	"github.com/flow-hydraulics/flow-wallet-api/transactions"
@@ -30,6 +31,39 @@ func (s *GormStore) AccountTokens(address string, tokenType templates.TokenType)
 	return
 }
 
+func (s *GormStore) AccountTokensByType(tokenType templates.TokenType) (att []AccountToken, err error) {
+	err = s.db.
+		Where(&AccountToken{TokenType: tokenType}).
+		Order("account_address asc, token_name asc").
+		Find(&att).Error
+	return
+}
+
+func (s *GormStore) InsertBalanceSnapshot(b *BalanceSnapshot) error {
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(b).Error
+}
+
+func (s *GormStore) BalanceSnapshots(address, tokenName string, from, to time.Time) (bb []BalanceSnapshot, err error) {
+	q := s.db.Where(&BalanceSnapshot{AccountAddress: address, TokenName: tokenName})
+	if !from.IsZero() {
+		q = q.Where("block_timestamp >= ?", from)
+	}
+	if !to.IsZero() {
+		q = q.Where("block_timestamp <= ?", to)
+	}
+	err = q.Order("block_height asc").Find(&bb).Error
+	return
+}
+
+func (s *GormStore) LastBalanceSnapshot(address, tokenName string, before time.Time) (b BalanceSnapshot, err error) {
+	err = s.db.
+		Where(&BalanceSnapshot{AccountAddress: address, TokenName: tokenName}).
+		Where("block_timestamp < ?", before).
+		Order("block_height desc").
+		First(&b).Error
+	return
+}
+
 func (s *GormStore) InsertAccountToken(at *AccountToken) error {
 	// FirstOrCreate as that will just return the first match instead of throwing
 	// a duplicate key error
@@ -40,6 +74,20 @@ func (s *GormStore) InsertTokenTransfer(t *TokenTransfer) error {
 	return s.db.Create(t).Error
 }
 
+func (s *GormStore) TokenTransferExists(t *TokenTransfer) (bool, error) {
+	var count int64
+	err := s.db.Model(&TokenTransfer{}).
+		Where("transaction_id = ?", t.TransactionId).
+		Where("sender_address = ? AND recipient_address = ?", t.SenderAddress, t.RecipientAddress).
+		Where("token_name = ? AND ft_amount = ? AND nft_id = ?", t.TokenName, t.FtAmount, t.NftID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (s *GormStore) DeleteTokenTransfer(transactionId string) error {
+	return s.db.Where("transaction_id = ?", transactionId).Delete(&TokenTransfer{}).Error
+}
+
 func tokenToTransferType(token *templates.Token) (*transactions.Type, error) {
 	var txType transactions.Type
 	switch token.Type {
@@ -128,3 +176,25 @@ func (s *GormStore) TokenDeposit(address, transactionId string, token *templates
 		First(&t).Error
 	return
 }
+
+func (s *GormStore) CachedNftItems(tokenName string, nftIDs []uint64) (cc []CachedNftItem, err error) {
+	err = s.db.
+		Where(&CachedNftItem{TokenName: tokenName}).
+		Where("nft_id IN ?", nftIDs).
+		Find(&cc).Error
+	return
+}
+
+func (s *GormStore) SaveCachedNftItems(cc []CachedNftItem) error {
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token_name"}, {Name: "nft_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"owner_address", "item", "updated_at"}),
+	}).Create(&cc).Error
+}
+
+func (s *GormStore) DeleteCachedNftItem(tokenName string, nftID uint64) error {
+	return s.db.
+		Where(&CachedNftItem{TokenName: tokenName}).
+		Where("nft_id = ?", nftID).
+		Delete(&CachedNftItem{}).Error
+}
@@ -82,6 +82,17 @@ type TokenDeposit struct {
 	SenderAddress string `json:"sender"`
 }
 
+const DepositNotificationType = "deposit"
+
+// DepositNotification is sent to the job status webhook when a deposit is
+// registered. It includes the metadata of the recipient account.
+type DepositNotification struct {
+	Type string `json:"type"`
+	TokenDeposit
+	Recipient string `json:"recipient"`
+	accounts.Metadata
+}
+
 func baseFromTransfer(t *TokenTransfer) TokenTransferBase {
 	return TokenTransferBase{
 		TransactionId: t.TransactionId,
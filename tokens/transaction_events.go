@@ -0,0 +1,24 @@
+package tokens
+
+import (
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	log "github.com/sirupsen/logrus"
+)
+
+// TransactionResolvedHandler removes the token transfers of transactions
+// which the seal tracker resolved as failed or expired.
+type TransactionResolvedHandler struct {
+	Store Store
+}
+
+func (h *TransactionResolvedHandler) Handle(payload transactions.TransactionResolvedPayload) {
+	if payload.Status == transactions.StatusSealed {
+		return
+	}
+
+	if err := h.Store.DeleteTokenTransfer(payload.TransactionId); err != nil {
+		log.
+			WithFields(log.Fields{"error": err, "transactionId": payload.TransactionId}).
+			Warn("Error while removing token transfer of unsuccessful transaction")
+	}
+}
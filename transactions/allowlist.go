@@ -0,0 +1,205 @@
+package transactions
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	log "github.com/sirupsen/logrus"
+)
+
+// AllowedCode is an entry in the code allowlist. An empty address allows the
+// code for all accounts.
+type AllowedCode struct {
+	ID          uint64    `json:"id" gorm:"column:id;primaryKey"`
+	Hash        string    `json:"hash" gorm:"column:hash;uniqueIndex:idx_allowed_codes_hash_address;not null"`
+	Address     string    `json:"address,omitempty" gorm:"column:address;uniqueIndex:idx_allowed_codes_hash_address"`
+	Description string    `json:"description,omitempty" gorm:"column:description"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (AllowedCode) TableName() string {
+	return "allowed_codes"
+}
+
+// Code allowlist JSON HTTP request. Either the code or its normalized hash
+// should be given.
+type AllowedCodeJSONRequest struct {
+	Code        string `json:"code,omitempty"`
+	Hash        string `json:"hash,omitempty"`
+	Address     string `json:"address,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// NormalizeCode removes comments and collapses whitespace outside of string
+// literals so that formatting does not change the hash of the code.
+func NormalizeCode(code string) string {
+	var b strings.Builder
+
+	space := false
+	inString := false
+	commentDepth := 0 // Block comments can be nested in Cadence
+
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		next := byte(0)
+		if i+1 < len(code) {
+			next = code[i+1]
+		}
+
+		switch {
+		case commentDepth > 0:
+			if c == '/' && next == '*' {
+				commentDepth++
+				i++
+			} else if c == '*' && next == '/' {
+				commentDepth--
+				i++
+				space = true
+			}
+			continue
+		case inString:
+			b.WriteByte(c)
+			if c == '\\' && next != 0 {
+				b.WriteByte(next)
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		case c == '/' && next == '/':
+			for i < len(code) && code[i] != '\n' {
+				i++
+			}
+			space = true
+			continue
+		case c == '/' && next == '*':
+			commentDepth = 1
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			continue
+		}
+
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+
+		if c == '"' {
+			inString = true
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+// CodeHash returns the hex encoded SHA3-256 hash of normalized code.
+func CodeHash(code string) string {
+	return ScriptHash([]byte(NormalizeCode(code)))
+}
+
+// CheckCode checks that code is in the code allowlist for each of the
+// authorizers. Always passes if the allowlist is not enabled.
+func (s *ServiceImpl) CheckCode(authorizers []string, code string) error {
+	if !s.cfg.EnableCodeAllowlist {
+		return nil
+	}
+
+	hash := CodeHash(code)
+
+	allowed, err := s.store.AllowedCodes(hash)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range authorizers {
+		address, err := flow_helpers.ValidateAddress(a, s.cfg.ChainID)
+		if err != nil {
+			return err
+		}
+
+		if !codeAllowedFor(allowed, address) {
+			log.
+				WithFields(log.Fields{"hash": hash, "address": address}).
+				Warn("Rejected code which is not in the allowlist")
+
+			return &errors.RequestError{
+				StatusCode: http.StatusForbidden,
+				Err:        fmt.Errorf("code with hash %s is not allowed for account %s", hash, address),
+			}
+		}
+	}
+
+	return nil
+}
+
+func codeAllowedFor(allowed []AllowedCode, address string) bool {
+	for _, a := range allowed {
+		if a.Address == "" || a.Address == address {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ServiceImpl) ListAllowedCodes() ([]AllowedCode, error) {
+	return s.store.AllowedCodes("")
+}
+
+func (s *ServiceImpl) AddAllowedCode(req AllowedCodeJSONRequest) (*AllowedCode, error) {
+	hash := strings.ToLower(strings.TrimPrefix(req.Hash, "0x"))
+
+	if req.Code != "" {
+		codeHash := CodeHash(req.Code)
+		if hash != "" && hash != codeHash {
+			return nil, &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("hash %s does not match the hash of the code %s", hash, codeHash),
+			}
+		}
+		hash = codeHash
+	}
+
+	if b, err := hex.DecodeString(hash); err != nil || len(b) != 32 {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("code or a hex encoded SHA3-256 hash is required"),
+		}
+	}
+
+	a := &AllowedCode{Hash: hash, Description: req.Description}
+
+	if req.Address != "" {
+		address, err := flow_helpers.ValidateAddress(req.Address, s.cfg.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		a.Address = address
+	}
+
+	if err := s.store.InsertAllowedCode(a); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (s *ServiceImpl) RemoveAllowedCode(id uint64) error {
+	if err := s.store.DeleteAllowedCode(id); err != nil {
+		if err.Error() == "record not found" {
+			return &errors.RequestError{
+				StatusCode: http.StatusNotFound,
+				Err:        fmt.Errorf("allowed code %d not found", id),
+			}
+		}
+		return err
+	}
+	return nil
+}
@@ -2,7 +2,11 @@ package transactions
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
 
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
 	"github.com/onflow/cadence"
 	c_json "github.com/onflow/cadence/encoding/json"
 )
@@ -43,3 +47,46 @@ func MustDecodeArgs(aa []Argument) []cadence.Value {
 
 	return cc
 }
+
+// decodeArguments decodes arguments and checks their count and types
+// against the parameters declared by the transaction or script code.
+func decodeArguments(code string, args []Argument) ([]cadence.Value, error) {
+	params, err := flow_helpers.ParseParameters(code)
+	if err != nil {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid Cadence code: %w", err),
+		}
+	}
+
+	if len(args) != len(params) {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("expected %d arguments, got %d", len(params), len(args)),
+		}
+	}
+
+	values := make([]cadence.Value, len(args))
+	for i, a := range args {
+		p := params[i]
+
+		v, err := ArgAsCadence(a)
+		if err != nil {
+			return nil, &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("invalid argument %q of type %s: %w", p.Name, p.Type, err),
+			}
+		}
+
+		if err := flow_helpers.CheckArgumentType(p.Type, v); err != nil {
+			return nil, &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("invalid argument %q: %w", p.Name, err),
+			}
+		}
+
+		values[i] = v
+	}
+
+	return values, nil
+}
@@ -3,6 +3,7 @@ package transactions
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -72,3 +73,81 @@ func Test_AsCadence(t *testing.T) {
 		})
 	}
 }
+
+func Test_DecodeArguments(t *testing.T) {
+	code := `
+transaction(amount: UFix64, recipient: Address, memo: String?, ids: [UInt64], meta: {String: String}) {
+	prepare(signer: AuthAccount) {}
+}`
+
+	valid := `[
+		{"type":"UFix64","value":"1.5"},
+		{"type":"Address","value":"0x01cf0e2f2f715450"},
+		{"type":"Optional","value":null},
+		{"type":"Array","value":[{"type":"UInt64","value":"1"}]},
+		{"type":"Dictionary","value":[{"key":{"type":"String","value":"a"},"value":{"type":"String","value":"b"}}]}
+	]`
+
+	testCases := []struct {
+		name      string
+		code      string
+		inputJson string
+		expectErr string
+	}{
+		{"valid arguments", code, valid, ""},
+		{"script arguments", `pub fun main(a: Int): Int { return a }`, `[{"type":"Int","value":"1"}]`, ""},
+		{"invalid code", `transaction(a: ) {}`, `[]`, "invalid Cadence code"},
+		{"missing arguments", code, `[{"type":"UFix64","value":"1.5"}]`, "expected 5 arguments, got 1"},
+		{
+			"wrong type", `transaction(amount: UFix64) {}`, `[{"type":"UInt64","value":"1"}]`,
+			`invalid argument "amount": expected UFix64, got UInt64`,
+		},
+		{
+			"UFix64 precision", `transaction(amount: UFix64) {}`, `[{"type":"UFix64","value":"1.123456789"}]`,
+			`invalid argument "amount" of type UFix64`,
+		},
+		{
+			"optional value", `transaction(memo: String?) {}`, `[{"type":"Optional","value":{"type":"Int","value":"1"}}]`,
+			`invalid argument "memo": expected String, got Int`,
+		},
+		{"non-optional value for optional", `transaction(memo: String?) {}`, `[{"type":"String","value":"a"}]`, ""},
+		{
+			"non-optional value of wrong type for optional", `transaction(memo: String?) {}`, `[{"type":"Int","value":"1"}]`,
+			`invalid argument "memo": expected String, got Int`,
+		},
+		{
+			"array element", `transaction(ids: [UInt64]) {}`, `[{"type":"Array","value":[{"type":"UInt64","value":"1"},{"type":"String","value":"2"}]}]`,
+			`invalid argument "ids": element 1: expected UInt64, got String`,
+		},
+		{
+			"dictionary key", `transaction(meta: {String: String}) {}`, `[{"type":"Dictionary","value":[{"key":{"type":"Int","value":"1"},"value":{"type":"String","value":"b"}}]}]`,
+			`invalid argument "meta": dictionary key: expected String, got Int`,
+		},
+		{
+			"address", `transaction(recipient: Address) {}`, `[{"type":"String","value":"0x01cf0e2f2f715450"}]`,
+			`invalid argument "recipient": expected Address, got String`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var args []Argument
+			if err := json.Unmarshal([]byte(tc.inputJson), &args); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := decodeArguments(tc.code, args)
+
+			if tc.expectErr == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %s", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tc.expectErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
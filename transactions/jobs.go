@@ -20,6 +20,17 @@ func (s *ServiceImpl) executeTransactionJob(ctx context.Context, j *jobs.Job) er
 		return err
 	}
 
+	if s.cfg.EnableSealTracker {
+		// Only send the transaction, seal tracker resolves the job
+		if err := s.submitTransaction(ctx, &tx); err != nil {
+			return err
+		}
+
+		j.State = jobs.AwaitingResult
+
+		return nil
+	}
+
 	err = s.sendTransaction(ctx, &tx)
 	if err != nil {
 		return err
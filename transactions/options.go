@@ -9,3 +9,51 @@ func WithTxRatelimiter(limiter ratelimit.Limiter) ServiceOption {
 		svc.txRateLimiter = limiter
 	}
 }
+
+// WithPayerProvider sets the provider of transaction payers, e.g. a payer pool.
+// By default the admin account pays for all transactions.
+func WithPayerProvider(p PayerProvider) ServiceOption {
+	return func(svc *ServiceImpl) {
+		svc.payers = p
+	}
+}
+
+// WithAccountGuard sets a guard checking the authorizers of new transactions,
+// e.g. to reject transactions of closed accounts.
+func WithAccountGuard(g AccountGuard) ServiceOption {
+	return func(svc *ServiceImpl) {
+		svc.guard = g
+	}
+}
+
+type TransactionOption func(*transactionParams)
+
+type transactionParams struct {
+	computeLimit       uint64
+	ignoreAccountGuard bool
+	trustedCode        bool
+}
+
+// WithComputeLimit overrides the compute limit of a transaction. The limit
+// may not exceed the configured maximum compute limit.
+func WithComputeLimit(limit uint64) TransactionOption {
+	return func(p *transactionParams) {
+		p.computeLimit = limit
+	}
+}
+
+// TrustedCode skips the code allowlist check for a transaction. It should
+// only be used for code from the templates of the service itself.
+func TrustedCode() TransactionOption {
+	return func(p *transactionParams) {
+		p.trustedCode = true
+	}
+}
+
+// IgnoreAccountGuard skips the account guard for a transaction, e.g. to sweep
+// the funds of an account being closed.
+func IgnoreAccountGuard() TransactionOption {
+	return func(p *transactionParams) {
+		p.ignoreAccountGuard = true
+	}
+}
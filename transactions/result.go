@@ -0,0 +1,84 @@
+package transactions
+
+import (
+	"context"
+	"strings"
+
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+)
+
+const feesDeductedEventSuffix = ".FlowFees.FeesDeducted"
+
+// storeResult stores the block and events of an executed transaction.
+// The transaction itself is saved when its status is updated.
+func storeResult(ctx context.Context, store Store, fc flow_helpers.FlowClient, tx *Transaction, result *flow.TransactionResult) error {
+	if result.BlockID != flow.EmptyID {
+		tx.BlockId = result.BlockID.Hex()
+
+		header, err := fc.GetBlockHeaderByID(ctx, result.BlockID)
+		if err != nil {
+			log.
+				WithFields(log.Fields{"error": err, "transactionId": tx.TransactionId}).
+				Warn("Could not get block header of transaction")
+		} else {
+			tx.BlockHeight = header.Height
+		}
+	}
+
+	events := make([]TransactionEvent, len(result.Events))
+	for i, e := range result.Events {
+		events[i] = TransactionEvent{
+			TransactionId:     tx.TransactionId,
+			FlowTransactionId: e.TransactionID.Hex(),
+			Type:              e.Type,
+			TransactionIndex:  e.TransactionIndex,
+			EventIndex:        e.EventIndex,
+			Payload:           e.Payload,
+		}
+	}
+
+	if err := store.ReplaceEvents(tx.TransactionId, events); err != nil {
+		return err
+	}
+
+	tx.Events = result.Events
+	tx.StoredEvents = events
+	tx.ExecutionEffort = executionEffort(result.Events)
+
+	return nil
+}
+
+// executionEffort returns the execution effort of a transaction as reported
+// by the fee deduction event, or zero if fees were not deducted.
+func executionEffort(events []flow.Event) uint64 {
+	for _, e := range events {
+		if !strings.HasSuffix(e.Type, feesDeductedEventSuffix) || e.Value.EventType == nil {
+			continue
+		}
+		for i, f := range e.Value.EventType.Fields {
+			if f.Identifier != "executionEffort" || i >= len(e.Value.Fields) {
+				continue
+			}
+			// Computation used is passed to the fee contract as is, i.e.
+			// the raw fixed point value equals the computation used.
+			if v, ok := e.Value.Fields[i].(cadence.UFix64); ok {
+				return uint64(v)
+			}
+		}
+	}
+	return 0
+}
+
+// hasStoredResult tells if the result of the transaction has been stored
+// and it does not need to be fetched from chain.
+func (t Transaction) hasStoredResult() bool {
+	switch t.Status {
+	case StatusSealed, StatusFailed, StatusExpired:
+		return true
+	default:
+		return false
+	}
+}
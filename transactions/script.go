@@ -0,0 +1,205 @@
+package transactions
+
+import (
+	"container/list"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go-sdk"
+	"golang.org/x/crypto/sha3"
+)
+
+// Script result output formats
+const (
+	ScriptFormatDefault = ""
+	ScriptFormatJsonCdc = "json-cdc"
+	ScriptFormatSimple  = "simple"
+)
+
+// ScriptBlock selects the block a script is executed at. The latest block is
+// used if neither the height nor the ID is set.
+type ScriptBlock struct {
+	Height uint64
+	ID     flow.Identifier
+}
+
+func (b ScriptBlock) isLatest() bool {
+	return b.Height == 0 && b.ID == flow.EmptyID
+}
+
+func (b ScriptBlock) String() string {
+	if b.ID != flow.EmptyID {
+		return b.ID.Hex()
+	}
+	return strconv.FormatUint(b.Height, 10)
+}
+
+// FormatScriptResult converts a script result to the given output format.
+func FormatScriptResult(v cadence.Value, format string) (interface{}, error) {
+	switch format {
+	case ScriptFormatDefault:
+		return v, nil
+	case ScriptFormatJsonCdc:
+		b, err := jsoncdc.Encode(v)
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(b), nil
+	case ScriptFormatSimple:
+		return simpleValue(v), nil
+	}
+
+	return nil, &errors.RequestError{
+		StatusCode: http.StatusBadRequest,
+		Err:        fmt.Errorf("unknown format %q, expected %q or %q", format, ScriptFormatJsonCdc, ScriptFormatSimple),
+	}
+}
+
+// simpleValue converts a Cadence value to plain JSON values. Numbers are
+// returned as strings to keep their precision.
+func simpleValue(v cadence.Value) interface{} {
+	switch v := v.(type) {
+	case nil, cadence.Void:
+		return nil
+	case cadence.Optional:
+		return simpleValue(v.Value)
+	case cadence.Bool:
+		return bool(v)
+	case cadence.String:
+		return string(v)
+	case cadence.Character:
+		return string(v)
+	case cadence.Address:
+		return v.String()
+	case cadence.Path:
+		return v.String()
+	case cadence.TypeValue:
+		if v.StaticType == nil {
+			return nil
+		}
+		return v.StaticType.ID()
+	case cadence.Array:
+		res := make([]interface{}, len(v.Values))
+		for i, e := range v.Values {
+			res[i] = simpleValue(e)
+		}
+		return res
+	case cadence.Dictionary:
+		res := make(map[string]interface{}, len(v.Pairs))
+		for _, p := range v.Pairs {
+			key, ok := simpleValue(p.Key).(string)
+			if !ok {
+				key = p.Key.String()
+			}
+			res[key] = simpleValue(p.Value)
+		}
+		return res
+	case cadence.Struct:
+		return simpleComposite(v.StructType, v.Fields)
+	case cadence.Resource:
+		return simpleComposite(v.ResourceType, v.Fields)
+	case cadence.Event:
+		return simpleComposite(v.EventType, v.Fields)
+	case cadence.Contract:
+		return simpleComposite(v.ContractType, v.Fields)
+	case cadence.Enum:
+		return simpleComposite(v.EnumType, v.Fields)
+	case cadence.Capability:
+		return map[string]interface{}{
+			"path":    v.Path.String(),
+			"address": v.Address.String(),
+		}
+	}
+
+	// Numbers and any other values
+	return v.String()
+}
+
+func simpleComposite(t cadence.CompositeType, fields []cadence.Value) interface{} {
+	res := make(map[string]interface{}, len(fields))
+	var ff []cadence.Field
+	if t != nil {
+		ff = t.CompositeFields()
+	}
+	for i, f := range fields {
+		name := strconv.Itoa(i)
+		if i < len(ff) {
+			name = ff[i].Identifier
+		}
+		res[name] = simpleValue(f)
+	}
+	return res
+}
+
+// scriptCache is a least recently used cache of script results.
+type scriptCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+type scriptCacheEntry struct {
+	key   string
+	value cadence.Value
+}
+
+func newScriptCache(size int) *scriptCache {
+	return &scriptCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+// scriptCacheKey hashes the code and arguments of a script executed at a block.
+func scriptCacheKey(code string, args []cadence.Value, block ScriptBlock) (string, error) {
+	h := sha3.New256()
+	h.Write([]byte(code)) // nolint
+	for _, a := range args {
+		b, err := jsoncdc.Encode(a)
+		if err != nil {
+			return "", err
+		}
+		h.Write(b) // nolint
+	}
+	return hex.EncodeToString(h.Sum(nil)) + ":" + block.String(), nil
+}
+
+func (c *scriptCache) get(key string) (cadence.Value, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*scriptCacheEntry).value, true
+}
+
+func (c *scriptCache) add(key string, value cadence.Value) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		e.Value.(*scriptCacheEntry).value = value
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&scriptCacheEntry{key, value})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*scriptCacheEntry).key)
+	}
+}
@@ -0,0 +1,98 @@
+package transactions
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/google/go-cmp/cmp"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+type countingFlowClient struct {
+	flow_helpers.FlowClient
+	calls int
+}
+
+func (c *countingFlowClient) ExecuteScriptAtBlockHeight(ctx context.Context, height uint64, script []byte, arguments []cadence.Value) (cadence.Value, error) {
+	c.calls++
+	return cadence.NewUInt64(height), nil
+}
+
+func TestExecuteScriptAtBlockCache(t *testing.T) {
+	fc := &countingFlowClient{}
+	svc := &ServiceImpl{fc: fc, scripts: newScriptCache(1)}
+
+	code := "pub fun main(a: Int): Int { return a }"
+	args := []Argument{cadence.NewInt(1)}
+
+	for i := 0; i < 2; i++ {
+		res, err := svc.ExecuteScriptAtBlock(context.Background(), code, args, ScriptBlock{Height: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res != cadence.NewUInt64(10) {
+			t.Fatalf("unexpected result %v", res)
+		}
+	}
+
+	if fc.calls != 1 {
+		t.Fatalf("expected a repeated query to be cached, got %d calls", fc.calls)
+	}
+
+	// Different arguments and heights are cached separately and evict the
+	// least recently used result
+	if _, err := svc.ExecuteScriptAtBlock(context.Background(), code, []Argument{cadence.NewInt(2)}, ScriptBlock{Height: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ExecuteScriptAtBlock(context.Background(), code, args, ScriptBlock{Height: 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	if fc.calls != 3 {
+		t.Fatalf("expected evicted results to be fetched again, got %d calls", fc.calls)
+	}
+}
+
+func TestFormatScriptResult(t *testing.T) {
+	address := cadence.NewAddress(flow.HexToAddress("0x01cf0e2f2f715450"))
+	balance, _ := cadence.NewUFix64("1.5")
+
+	value := cadence.NewDictionary([]cadence.KeyValuePair{
+		{Key: cadence.String("address"), Value: address},
+		{Key: cadence.String("balance"), Value: balance},
+		{Key: cadence.String("ids"), Value: cadence.NewArray([]cadence.Value{cadence.NewUInt64(1)})},
+		{Key: cadence.String("name"), Value: cadence.NewOptional(nil)},
+	})
+
+	res, err := FormatScriptResult(value, ScriptFormatSimple)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"address":"0x01cf0e2f2f715450","balance":"1.50000000","ids":["1"],"name":null}`
+	if diff := cmp.Diff(expected, string(b)); diff != "" {
+		t.Fatalf("unexpected simple result (-want +got):\n%s", diff)
+	}
+
+	res, err = FormatScriptResult(balance, ScriptFormatJsonCdc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ = json.Marshal(res)
+	if string(b) != `{"type":"UFix64","value":"1.50000000"}` {
+		t.Fatalf("unexpected JSON-CDC result %s", b)
+	}
+
+	if _, err := FormatScriptResult(balance, "xml"); err == nil {
+		t.Fatal("expected an unknown format to fail")
+	}
+}
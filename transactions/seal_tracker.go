@@ -0,0 +1,281 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/access/grpc"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+)
+
+// SealTracker polls the results of submitted transactions and resolves
+// the jobs waiting on them once the transactions are sealed or expired.
+type SealTracker struct {
+	cfg   *configs.Config
+	store Store
+	km    keys.Manager
+	fc    flow_helpers.FlowClient
+	wp    jobs.WorkerPool
+
+	ticker      *time.Ticker
+	tickerMutex sync.Mutex
+	stopChan    chan struct{}
+	stopOnce    sync.Once
+}
+
+// NewSealTracker initiates a new seal tracker.
+func NewSealTracker(
+	cfg *configs.Config,
+	store Store,
+	km keys.Manager,
+	fc flow_helpers.FlowClient,
+	wp jobs.WorkerPool,
+) *SealTracker {
+	return &SealTracker{
+		cfg:      cfg,
+		store:    store,
+		km:       km,
+		fc:       fc,
+		wp:       wp,
+		stopChan: make(chan struct{}),
+	}
+}
+
+func (t *SealTracker) Start() {
+	t.tickerMutex.Lock()
+	defer t.tickerMutex.Unlock()
+
+	if t.ticker != nil {
+		// Already started
+		return
+	}
+
+	t.ticker = time.NewTicker(t.cfg.SealTrackerInterval)
+
+	go func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		entry := log.WithFields(log.Fields{
+			"package":  "transactions",
+			"function": "SealTracker.Start.goroutine",
+		})
+
+		for {
+			select {
+			case <-t.stopChan:
+				return
+			case <-t.ticker.C:
+				if err := t.Poll(ctx); err != nil {
+					entry.
+						WithFields(log.Fields{"error": err}).
+						Warn("Error while polling transaction results")
+				}
+			}
+		}
+	}()
+}
+
+func (t *SealTracker) Stop() {
+	t.tickerMutex.Lock()
+	defer t.tickerMutex.Unlock()
+
+	t.stopOnce.Do(func() {
+		close(t.stopChan)
+	})
+
+	if t.ticker != nil {
+		t.ticker.Stop()
+	}
+}
+
+// Poll fetches the results for a batch of pending transactions and resolves
+// the ones that have been sealed or expired.
+func (t *SealTracker) Poll(ctx context.Context) error {
+	pending, err := t.store.PendingTransactions(t.cfg.SealTrackerBatchSize)
+	if err != nil {
+		return err
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// Used to check if the reference blocks of pending attempts have expired
+	sealed, err := t.fc.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	wg := sync.WaitGroup{}
+	for i := range pending {
+		wg.Add(1)
+		go func(tx *Transaction) {
+			defer wg.Done()
+			t.check(ctx, tx, sealed.Height)
+		}(&pending[i])
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// check resolves the transaction if its latest attempt has been sealed,
+// failed or expired. An attempt is only considered expired if the network
+// says so or if it can no longer be included in a block, so an attempt which
+// may still be sealed is never rebuilt.
+func (t *SealTracker) check(ctx context.Context, tx *Transaction, sealedHeight uint64) {
+	entry := log.WithFields(log.Fields{
+		"package":       "transactions",
+		"function":      "SealTracker.check",
+		"transactionId": tx.TransactionId,
+	})
+
+	result, err := t.fc.GetTransactionResult(ctx, tx.flowID())
+	if err != nil {
+		entry.
+			WithFields(log.Fields{"error": err}).
+			Debug("Could not get transaction result")
+
+		// Transactions unknown to the access node (e.g. dropped) are expired
+		// once they can no longer be included in a block
+		if isNotFound(err) && t.expired(ctx, tx, sealedHeight) {
+			t.resolve(tx, StatusExpired, fmt.Errorf("transaction expired: %w", err))
+			return
+		}
+
+		t.touch(tx)
+		return
+	}
+
+	if result.Error != nil {
+		if flowTx, err := flow.DecodeTransaction(tx.FlowTransaction); err == nil {
+			keys.ResyncOnSequenceNumberError(ctx, t.km, flowTx, result.Error)
+		}
+		if err := storeResult(ctx, t.store, t.fc, tx, result); err != nil {
+			entry.WithFields(log.Fields{"error": err}).Warn("Could not store transaction result")
+			return
+		}
+		t.resolve(tx, StatusFailed, result.Error)
+		return
+	}
+
+	switch result.Status {
+	case flow.TransactionStatusSealed:
+		if err := storeResult(ctx, t.store, t.fc, tx, result); err != nil {
+			entry.WithFields(log.Fields{"error": err}).Warn("Could not store transaction result")
+			return
+		}
+		t.resolve(tx, StatusSealed, nil)
+	case flow.TransactionStatusExpired:
+		t.resolve(tx, StatusExpired, fmt.Errorf("transaction expired"))
+	case flow.TransactionStatusUnknown, flow.TransactionStatusPending:
+		// Not yet included in a block
+		if t.expired(ctx, tx, sealedHeight) {
+			t.resolve(tx, StatusExpired, fmt.Errorf("transaction expired"))
+			return
+		}
+		t.touch(tx)
+	default:
+		// Included in a block, will be sealed
+		t.touch(tx)
+	}
+}
+
+// expired returns true if the latest attempt of tx is older than
+// cfg.SealTrackerTimeout and its reference block has expired. Errors are
+// logged and treated as not expired.
+func (t *SealTracker) expired(ctx context.Context, tx *Transaction, sealedHeight uint64) bool {
+	if time.Since(attemptCreatedAt(tx)) < t.cfg.SealTrackerTimeout {
+		return false
+	}
+
+	flowTx, err := flow.DecodeTransaction(tx.FlowTransaction)
+	if err != nil {
+		return false
+	}
+
+	expired, err := flow_helpers.IsReferenceBlockExpired(ctx, t.fc, flowTx.ReferenceBlockID, sealedHeight)
+	if err != nil {
+		log.
+			WithFields(log.Fields{"error": err, "transactionId": tx.TransactionId}).
+			Debug("Could not check reference block of pending transaction")
+		return false
+	}
+
+	return expired
+}
+
+// touch bumps the updated_at timestamp so other pending transactions get
+// their turn in the next batch.
+func (t *SealTracker) touch(tx *Transaction) {
+	if err := t.store.UpdateTransaction(tx); err != nil {
+		log.
+			WithFields(log.Fields{"error": err, "transactionId": tx.TransactionId}).
+			Warn("Could not update pending transaction")
+	}
+}
+
+// resolve stores the outcome of the latest attempt of the transaction and
+// resolves the jobs waiting on it. Jobs of transactions which can be rebuilt
+// are returned to the worker pool to be retried, other failures are permanent.
+func (t *SealTracker) resolve(tx *Transaction, status string, txErr error) {
+	entry := log.WithFields(log.Fields{
+		"package":       "transactions",
+		"function":      "SealTracker.resolve",
+		"transactionId": tx.TransactionId,
+		"status":        status,
+	})
+
+	if err := setAttemptStatus(t.store, tx, status, txErr); err != nil {
+		entry.WithFields(log.Fields{"error": err}).Warn("Could not update transaction status")
+		return
+	}
+
+	if status == StatusExpired {
+		// The sequence number of an expired transaction is left unused
+		if flowTx, err := flow.DecodeTransaction(tx.FlowTransaction); err == nil {
+			keys.ReleaseProposalKey(t.km, flowTx)
+		}
+	}
+
+	jobErr := txErr
+	if txErr != nil && !tx.rebuildable() {
+		jobErr = jobs.PermanentFailure(txErr)
+	}
+
+	if err := t.wp.ResolveJobs(tx.TransactionId, jobErr); err != nil {
+		entry.WithFields(log.Fields{"error": err}).Warn("Could not resolve jobs")
+	}
+
+	TransactionResolved.Trigger(TransactionResolvedPayload{
+		TransactionId: tx.TransactionId,
+		Status:        status,
+		Error:         txErr,
+		Events:        tx.Events,
+	})
+
+	entry.Debug("Transaction resolved")
+}
+
+// attemptCreatedAt returns the creation time of the latest attempt of tx.
+func attemptCreatedAt(tx *Transaction) time.Time {
+	if a := tx.latestAttempt(); a != nil {
+		return a.CreatedAt
+	}
+	return tx.CreatedAt
+}
+
+// isNotFound returns true if err is a gRPC NotFound error.
+func isNotFound(err error) bool {
+	rpcErr, ok := err.(grpc.RPCError)
+	return ok && rpcErr.GRPCStatus().Code() == codes.NotFound
+}
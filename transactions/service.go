@@ -12,6 +12,7 @@ import (
 	"github.com/flow-hydraulics/flow-wallet-api/jobs"
 	"github.com/flow-hydraulics/flow-wallet-api/keys"
 	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
 	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/access/grpc"
 	"go.uber.org/ratelimit"
@@ -19,15 +20,45 @@ import (
 )
 
 type Service interface {
-	Create(ctx context.Context, sync bool, proposerAddress string, code string, args []Argument, tType Type) (*jobs.Job, *Transaction, error)
-	Sign(ctx context.Context, proposerAddress string, code string, args []Argument) (*SignedTransaction, error)
+	Create(ctx context.Context, sync bool, proposerAddress string, code string, args []Argument, tType Type, opts ...TransactionOption) (*jobs.Job, *Transaction, error)
+	// Sponsor pays the fees of a transaction signed by non-custodial accounts.
+	// The transaction is validated against the sponsorship policy and sent
+	// after the admin account has signed the envelope.
+	Sponsor(ctx context.Context, sync bool, encoded []byte) (*jobs.Job, *Transaction, error)
+	// CreateWithAuthorizers works like Create but the transaction is authorized
+	// by each of the given custodial accounts. The first authorizer acts as the proposer.
+	CreateWithAuthorizers(ctx context.Context, sync bool, authorizers []string, code string, args []Argument, tType Type, opts ...TransactionOption) (*jobs.Job, *Transaction, error)
+	// Submit creates and sends a transaction without waiting for its result.
+	// The transaction is left pending for the seal tracker to resolve.
+	Submit(ctx context.Context, proposerAddress string, code string, args []Argument, tType Type, opts ...TransactionOption) (*Transaction, error)
+	Sign(ctx context.Context, proposerAddress string, code string, args []Argument, opts ...TransactionOption) (*SignedTransaction, error)
 	List(limit, offset int) ([]Transaction, error)
+	ListByEventType(eventType string, limit, offset int) ([]Transaction, error)
 	ListForAccount(tType Type, address string, limit, offset int) ([]Transaction, error)
 	Details(ctx context.Context, transactionId string) (*Transaction, error)
 	DetailsForAccount(ctx context.Context, tType Type, address, transactionId string) (*Transaction, error)
 	ExecuteScript(ctx context.Context, code string, args []Argument) (cadence.Value, error)
+	// ExecuteScriptAtBlock executes a script at a given block height or ID.
+	ExecuteScriptAtBlock(ctx context.Context, code string, args []Argument, block ScriptBlock) (cadence.Value, error)
 	UpdateTransaction(t *Transaction) error
 	GetOrCreateTransaction(transactionId string) *Transaction
+	// CheckCode checks raw transaction code against the code allowlist,
+	// returning a 403 error if the code is not allowed for an authorizer.
+	// New transactions are checked automatically unless TrustedCode is used.
+	CheckCode(authorizers []string, code string) error
+	ListAllowedCodes() ([]AllowedCode, error)
+	AddAllowedCode(req AllowedCodeJSONRequest) (*AllowedCode, error)
+	RemoveAllowedCode(id uint64) error
+}
+
+// PayerProvider provides the Authorizer used as the payer of transactions.
+type PayerProvider interface {
+	PayerAuthorizer(ctx context.Context) (keys.Authorizer, error)
+}
+
+// AccountGuard checks whether an account may authorize new transactions.
+type AccountGuard interface {
+	CheckAuthorizer(address string) error
 }
 
 // ServiceImpl defines the API for transaction HTTP handlers.
@@ -38,6 +69,9 @@ type ServiceImpl struct {
 	wp            jobs.WorkerPool
 	cfg           *configs.Config
 	txRateLimiter ratelimit.Limiter
+	payers        PayerProvider
+	scripts       *scriptCache
+	guard         AccountGuard
 }
 
 // NewService initiates a new transaction service.
@@ -52,7 +86,11 @@ func NewService(
 	var defaultTxRatelimiter = ratelimit.NewUnlimited()
 
 	// TODO(latenssi): safeguard against nil config?
-	svc := &ServiceImpl{store, km, fc, wp, cfg, defaultTxRatelimiter}
+	svc := &ServiceImpl{store, km, fc, wp, cfg, defaultTxRatelimiter, nil, nil, nil}
+
+	if cfg.ScriptCacheSize > 0 {
+		svc.scripts = newScriptCache(cfg.ScriptCacheSize)
+	}
 
 	for _, opt := range opts {
 		opt(svc)
@@ -68,13 +106,24 @@ func NewService(
 	return svc
 }
 
-func (s *ServiceImpl) Create(ctx context.Context, sync bool, proposerAddress string, code string, args []Argument, tType Type) (*jobs.Job, *Transaction, error) {
-	transaction, err := s.newTransaction(ctx, proposerAddress, code, args, tType)
+func (s *ServiceImpl) Create(ctx context.Context, sync bool, proposerAddress string, code string, args []Argument, tType Type, opts ...TransactionOption) (*jobs.Job, *Transaction, error) {
+	return s.CreateWithAuthorizers(ctx, sync, []string{proposerAddress}, code, args, tType, opts...)
+}
+
+func (s *ServiceImpl) CreateWithAuthorizers(ctx context.Context, sync bool, authorizers []string, code string, args []Argument, tType Type, opts ...TransactionOption) (*jobs.Job, *Transaction, error) {
+	transaction, err := s.newTransaction(ctx, authorizers, code, args, tType, opts...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error while getting new transaction: %w", err)
 	}
 
+	return s.insertAndSend(ctx, sync, transaction)
+}
+
+// insertAndSend stores a new transaction and sends it, either synchronously
+// or by scheduling a transaction job.
+func (s *ServiceImpl) insertAndSend(ctx context.Context, sync bool, transaction *Transaction) (*jobs.Job, *Transaction, error) {
 	if err := s.store.InsertTransaction(transaction); err != nil {
+		s.releaseProposalKey(transaction)
 		return nil, nil, fmt.Errorf("error while inserting transaction in db: %w", err)
 	}
 
@@ -101,8 +150,41 @@ func (s *ServiceImpl) Create(ctx context.Context, sync bool, proposerAddress str
 	}
 }
 
-func (s *ServiceImpl) Sign(ctx context.Context, proposerAddress string, code string, args []Argument) (*SignedTransaction, error) {
-	flowTx, err := s.buildFlowTransaction(ctx, proposerAddress, code, args)
+func (s *ServiceImpl) Submit(ctx context.Context, proposerAddress string, code string, args []Argument, tType Type, opts ...TransactionOption) (*Transaction, error) {
+	transaction, err := s.newTransaction(ctx, []string{proposerAddress}, code, args, tType, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting new transaction: %w", err)
+	}
+
+	if err := s.store.InsertTransaction(transaction); err != nil {
+		s.releaseProposalKey(transaction)
+		return nil, fmt.Errorf("error while inserting transaction in db: %w", err)
+	}
+
+	if err := s.submitTransaction(ctx, transaction); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (s *ServiceImpl) Sign(ctx context.Context, proposerAddress string, code string, args []Argument, opts ...TransactionOption) (*SignedTransaction, error) {
+	if err := s.checkAuthorizers([]string{proposerAddress}, opts...); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkCode([]string{proposerAddress}, code, opts...); err != nil {
+		return nil, err
+	}
+
+	computeLimit, err := s.computeLimit(General, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The transaction is not sent by the service, so no tracked sequence
+	// number is allocated for it
+	flowTx, err := s.buildFlowTransaction(ctx, []string{proposerAddress}, code, args, computeLimit, true)
 	if err != nil {
 		return nil, err
 	}
@@ -116,6 +198,12 @@ func (s *ServiceImpl) List(limit, offset int) ([]Transaction, error) {
 	return s.store.Transactions(o)
 }
 
+// ListByEventType returns transactions which emitted an event of the given type.
+func (s *ServiceImpl) ListByEventType(eventType string, limit, offset int) ([]Transaction, error) {
+	o := datastore.ParseListOptions(limit, offset)
+	return s.store.TransactionsByEventType(eventType, o)
+}
+
 // ListForAccount returns all transactions in the datastore for a given account.
 func (s *ServiceImpl) ListForAccount(tType Type, address string, limit, offset int) ([]Transaction, error) {
 	// Check if the input is a valid address
@@ -147,7 +235,11 @@ func (s *ServiceImpl) Details(ctx context.Context, transactionId string) (*Trans
 		return nil, err
 	}
 
-	result, err := s.fc.GetTransactionResult(ctx, flow.HexToID(transactionId))
+	if transaction.hasStoredResult() {
+		return &transaction, nil
+	}
+
+	result, err := s.fc.GetTransactionResult(ctx, transaction.flowID())
 	if err != nil {
 		return nil, err
 	}
@@ -181,7 +273,11 @@ func (s *ServiceImpl) DetailsForAccount(ctx context.Context, tType Type, address
 		return nil, err
 	}
 
-	result, err := s.fc.GetTransactionResult(ctx, flow.HexToID(transactionId))
+	if transaction.hasStoredResult() {
+		return &transaction, nil
+	}
+
+	result, err := s.fc.GetTransactionResult(ctx, transaction.flowID())
 	if err != nil {
 		return nil, err
 	}
@@ -193,11 +289,46 @@ func (s *ServiceImpl) DetailsForAccount(ctx context.Context, tType Type, address
 
 // Execute a script
 func (s *ServiceImpl) ExecuteScript(ctx context.Context, code string, args []Argument) (cadence.Value, error) {
-	return s.fc.ExecuteScriptAtLatestBlock(
-		ctx,
-		[]byte(code),
-		MustDecodeArgs(args),
-	)
+	return s.ExecuteScriptAtBlock(ctx, code, args, ScriptBlock{})
+}
+
+// Execute a script at a given block, using cached results if enabled
+func (s *ServiceImpl) ExecuteScriptAtBlock(ctx context.Context, code string, args []Argument, block ScriptBlock) (cadence.Value, error) {
+	values, err := decodeArguments(code, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if block.isLatest() {
+		return s.fc.ExecuteScriptAtLatestBlock(ctx, []byte(code), values)
+	}
+
+	var key string
+	if s.scripts != nil {
+		key, err = scriptCacheKey(code, values, block)
+		if err != nil {
+			return nil, err
+		}
+		if res, ok := s.scripts.get(key); ok {
+			return res, nil
+		}
+	}
+
+	var res cadence.Value
+	if block.ID != flow.EmptyID {
+		res, err = s.fc.ExecuteScriptAtBlockID(ctx, block.ID, []byte(code), values)
+	} else {
+		res, err = s.fc.ExecuteScriptAtBlockHeight(ctx, block.Height, []byte(code), values)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.scripts != nil {
+		s.scripts.add(key, res)
+	}
+
+	return res, nil
 }
 
 func (s *ServiceImpl) UpdateTransaction(t *Transaction) error {
@@ -208,137 +339,501 @@ func (s *ServiceImpl) GetOrCreateTransaction(transactionId string) *Transaction
 	return s.store.GetOrCreateTransaction(transactionId)
 }
 
-func (s *ServiceImpl) buildFlowTransaction(ctx context.Context, proposerAddress, code string, arguments []Argument) (*flow.Transaction, error) {
+// computeLimit returns the compute limit of a transaction of the given type.
+// A limit requested using WithComputeLimit overrides the configured limit.
+func (s *ServiceImpl) computeLimit(tType Type, opts ...TransactionOption) (uint64, error) {
+	var p transactionParams
+	for _, opt := range opts {
+		opt(&p)
+	}
+
+	if p.computeLimit == 0 {
+		return s.cfg.ComputeLimit(tType.String()), nil
+	}
+
+	if p.computeLimit > s.cfg.MaxComputeLimit {
+		return 0, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("compute limit %d exceeds the maximum of %d", p.computeLimit, s.cfg.MaxComputeLimit),
+		}
+	}
+
+	return p.computeLimit, nil
+}
+
+// checkAuthorizers checks the authorizers of a new transaction against the
+// account guard, if one is set.
+func (s *ServiceImpl) checkAuthorizers(authorizers []string, opts ...TransactionOption) error {
+	var p transactionParams
+	for _, opt := range opts {
+		opt(&p)
+	}
+
+	if s.guard == nil || p.ignoreAccountGuard {
+		return nil
+	}
+
+	for _, a := range authorizers {
+		if err := s.guard.CheckAuthorizer(a); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkCode checks the code of a new transaction against the code allowlist
+// unless the code is trusted.
+func (s *ServiceImpl) checkCode(authorizers []string, code string, opts ...TransactionOption) error {
+	var p transactionParams
+	for _, opt := range opts {
+		opt(&p)
+	}
+
+	if p.trustedCode {
+		return nil
+	}
+
+	return s.CheckCode(authorizers, code)
+}
+
+func (s *ServiceImpl) buildFlowTransaction(ctx context.Context, authorizerAddresses []string, code string, arguments []Argument, computeLimit uint64, external bool) (*flow.Transaction, error) {
+	values, err := decodeArguments(code, arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	rawArguments := make([][]byte, len(values))
+	for i, cv := range values {
+		rawArguments[i], err = jsoncdc.Encode(cv)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.buildRawFlowTransaction(ctx, authorizerAddresses, []byte(code), rawArguments, computeLimit, external)
+}
+
+// buildRawFlowTransaction builds and signs a transaction with a fresh
+// reference block and proposal key sequence number. If external is set the
+// transaction is not sent by the service and the on-chain sequence number of
+// the proposal key is used instead of allocating a tracked one.
+func (s *ServiceImpl) buildRawFlowTransaction(ctx context.Context, authorizerAddresses []string, script []byte, arguments [][]byte, computeLimit uint64, external bool) (*flow.Transaction, error) {
 	latestBlockID, err := flow_helpers.LatestBlockId(ctx, s.fc)
 	if err != nil {
 		return nil, err
 	}
 
-	// Admin should always be the payer of the transaction fees.
-	payer, err := s.km.AdminAuthorizer(ctx)
+	payer, err := s.getPayer(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error while getting admin authorizer for payer: %w", err)
+		return nil, err
 	}
 
-	proposer, err := s.getProposalAuthorizer(ctx, proposerAddress)
+	authorizers, accounts, err := s.getAuthorizers(ctx, authorizerAddresses, external)
 	if err != nil {
 		return nil, err
 	}
 
+	// First authorizer of the set acts as the proposer.
+	proposer := authorizers[0]
+
 	flowTx := flow.NewTransaction()
 	flowTx.
 		SetReferenceBlockID(*latestBlockID).
 		SetProposalKey(proposer.Address, proposer.Key.Index, proposer.Key.SequenceNumber).
 		SetPayer(payer.Address).
-		SetGasLimit(maxGasLimit).
-		SetScript([]byte(code))
+		SetGasLimit(computeLimit).
+		SetScript(script)
 
 	for _, arg := range arguments {
-		cv, err := ArgAsCadence(arg)
-		if err != nil {
-			return nil, err
-		}
-
-		err = flowTx.AddArgument(cv)
-		if err != nil {
-			return nil, err
-		}
+		flowTx.AddRawArgument(arg)
 	}
 
-	// Add authorizers. We assume proposer is always the sole authorizer
-	// https://github.com/flow-hydraulics/flow-wallet-api/issues/79
-	flowTx.AddAuthorizer(proposer.Address)
+	for _, a := range accounts {
+		flowTx.AddAuthorizer(a)
+	}
 
-	// Proposer signs the payload (unless proposer == payer).
-	if !proposer.Equals(payer) {
-		if err := flowTx.SignPayload(proposer.Address, proposer.Key.Index, proposer.Signer); err != nil {
+	// Each key of the authorizer sets signs the payload (unless key == payer key).
+	// Multi-signature accounts require more than one signature to meet the weight threshold.
+	for _, a := range authorizers {
+		if a.Equals(payer) {
+			continue
+		}
+		if err := flowTx.SignPayload(a.Address, a.Key.Index, a.Signer); err != nil {
+			if !external {
+				keys.ReleaseProposalKey(s.km, flowTx)
+			}
 			return nil, err
 		}
 	}
 
 	// Payer signs the envelope
 	if err := flowTx.SignEnvelope(payer.Address, payer.Key.Index, payer.Signer); err != nil {
+		if !external {
+			keys.ReleaseProposalKey(s.km, flowTx)
+		}
 		return nil, err
 	}
 
 	return flowTx, nil
 }
 
-func (s *ServiceImpl) newTransaction(ctx context.Context, proposerAddress string, code string, args []Argument, tType Type) (*Transaction, error) {
+func (s *ServiceImpl) newTransaction(ctx context.Context, authorizers []string, code string, args []Argument, tType Type, opts ...TransactionOption) (*Transaction, error) {
+	if len(authorizers) == 0 {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("at least one authorizer is required"),
+		}
+	}
+
+	if err := s.checkAuthorizers(authorizers, opts...); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkCode(authorizers, code, opts...); err != nil {
+		return nil, err
+	}
+
+	computeLimit, err := s.computeLimit(tType, opts...)
+	if err != nil {
+		return nil, err
+	}
+
 	tx := &Transaction{
-		ProposerAddress: proposerAddress,
+		ProposerAddress: authorizers[0],
 		TransactionType: tType,
+		ComputeLimit:    computeLimit,
 	}
 
-	flowTx, err := s.buildFlowTransaction(ctx, proposerAddress, code, args)
+	flowTx, err := s.buildFlowTransaction(ctx, authorizers, code, args, computeLimit, false)
 	if err != nil {
 		return nil, fmt.Errorf("error while building transaction: %w", err)
 	}
 
 	tx.TransactionId = flowTx.ID().Hex()
 	tx.FlowTransaction = flowTx.Encode()
+	tx.Attempts = []TransactionAttempt{{
+		TransactionId:     tx.TransactionId,
+		FlowTransactionId: tx.TransactionId,
+	}}
 
 	return tx, nil
 }
 
-func (s *ServiceImpl) getProposalAuthorizer(ctx context.Context, proposerAddress string) (keys.Authorizer, error) {
-	// Validate the input address.
-	proposerAddress, err := flow_helpers.ValidateAddress(proposerAddress, s.cfg.ChainID)
+// getPayer returns the payer of a transaction. Admin pays the transaction fees
+// unless a payer provider (pool) has been configured.
+func (s *ServiceImpl) getPayer(ctx context.Context) (keys.Authorizer, error) {
+	if s.payers != nil {
+		payer, err := s.payers.PayerAuthorizer(ctx)
+		if err != nil {
+			return keys.Authorizer{}, fmt.Errorf("error while getting payer authorizer: %w", err)
+		}
+		return payer, nil
+	}
+
+	payer, err := s.km.AdminAuthorizer(ctx)
 	if err != nil {
-		return keys.Authorizer{}, err
+		return keys.Authorizer{}, fmt.Errorf("error while getting admin authorizer for payer: %w", err)
 	}
 
-	var proposer keys.Authorizer
-	if proposerAddress == s.cfg.AdminAddress {
-		proposer, err = s.km.AdminProposalKey(ctx)
+	return payer, nil
+}
+
+// getAuthorizers returns the keys required to sign on behalf of each of the
+// given addresses and the authorizer accounts of the transaction. The first
+// key of the first address should be used as the proposer.
+func (s *ServiceImpl) getAuthorizers(ctx context.Context, addresses []string, external bool) ([]keys.Authorizer, []flow.Address, error) {
+	accounts := make([]flow.Address, len(addresses))
+	seen := make(map[string]bool, len(addresses))
+
+	for i, address := range addresses {
+		// Validate the input address.
+		address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
 		if err != nil {
-			return keys.Authorizer{}, fmt.Errorf("error while getting admin authorizer: %w", err)
+			return nil, nil, err
 		}
-	} else {
-		proposer, err = s.km.UserAuthorizer(ctx, flow.HexToAddress(proposerAddress))
+
+		if seen[address] {
+			return nil, nil, &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("duplicate authorizer: %s", address),
+			}
+		}
+
+		seen[address] = true
+		accounts[i] = flow.HexToAddress(address)
+	}
+
+	// Keys of the additional authorizers are resolved before the proposer so a
+	// failure will not leave an allocated proposal key sequence number unused.
+	signers := []keys.Authorizer{}
+	for _, address := range accounts[1:] {
+		aa, err := s.getSigners(ctx, address)
 		if err != nil {
-			return keys.Authorizer{}, fmt.Errorf("error while getting user authorizer: %w", err)
+			return nil, nil, err
 		}
+		signers = append(signers, aa...)
+	}
+
+	proposerSet, err := s.getProposerSet(ctx, accounts[0], external)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return proposer, nil
+	return append(proposerSet, signers...), accounts, nil
+}
+
+// getProposerSet returns the set of authorizers required to sign on behalf of
+// the proposer. The first authorizer of the set should be used as the proposer.
+func (s *ServiceImpl) getProposerSet(ctx context.Context, address flow.Address, external bool) ([]keys.Authorizer, error) {
+	if external {
+		authorizers, err := s.km.ExternalProposerSet(ctx, address)
+		if err != nil {
+			return nil, fmt.Errorf("error while getting proposer authorizers: %w", err)
+		}
+		return authorizers, nil
+	}
+
+	if address == flow.HexToAddress(s.cfg.AdminAddress) {
+		proposer, err := s.km.AdminProposalKey(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error while getting admin authorizer: %w", err)
+		}
+		return []keys.Authorizer{proposer}, nil
+	}
+
+	authorizers, err := s.km.UserAuthorizers(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting user authorizers: %w", err)
+	}
+
+	return authorizers, nil
+}
+
+// getSigners returns the set of authorizers required to sign on behalf of an
+// additional authorizer of a transaction.
+func (s *ServiceImpl) getSigners(ctx context.Context, address flow.Address) ([]keys.Authorizer, error) {
+	if address == flow.HexToAddress(s.cfg.AdminAddress) {
+		admin, err := s.km.AdminAuthorizer(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error while getting admin authorizer: %w", err)
+		}
+		return []keys.Authorizer{admin}, nil
+	}
+
+	signers, err := s.km.UserSigners(ctx, address)
+	if err != nil {
+		if keys.IsInsufficientKeyWeightError(err) {
+			return nil, &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("authorizer %s is not a custodial account with sufficient key weight", flow_helpers.FormatAddress(address)),
+			}
+		}
+		return nil, fmt.Errorf("error while getting user signers: %w", err)
+	}
+
+	return signers, nil
 }
 
 func (s *ServiceImpl) sendTransaction(ctx context.Context, tx *Transaction) error {
-	// TODO: we should "recreate" the transaction as proposal key sequence numbering
-	// might have gotten out of sync by now (in async situations)
+	flowTx, sent, err := s.prepareAttempt(ctx, tx)
+	if err != nil {
+		return err
+	}
 
-	flowTx, err := flow.DecodeTransaction(tx.FlowTransaction)
+	if !sent {
+		// Ratelimit
+		s.txRateLimiter.Take()
+
+		if err := s.fc.SendTransaction(ctx, *flowTx); err != nil {
+			// The sequence number may have been left unused
+			keys.ReleaseProposalKey(s.km, flowTx)
+			return s.attemptFailed(ctx, tx, flowTx, nil, err)
+		}
+	}
+
+	result, err := flow_helpers.WaitForSeal(ctx, s.fc, flowTx.ID(), s.cfg.TransactionTimeout)
 	if err != nil {
+		return s.attemptFailed(ctx, tx, flowTx, result, err)
+	}
+
+	if err := storeResult(ctx, s.store, s.fc, tx, result); err != nil {
 		return err
 	}
 
-	// Check if transaction has been sent already.
-	_, err = s.fc.GetTransaction(ctx, flowTx.ID())
+	return setAttemptStatus(s.store, tx, StatusSealed, nil)
+}
+
+// submitTransaction sends the transaction without waiting for its result and
+// marks it as pending for the seal tracker.
+func (s *ServiceImpl) submitTransaction(ctx context.Context, tx *Transaction) error {
+	flowTx, sent, err := s.prepareAttempt(ctx, tx)
 	if err != nil {
+		return err
+	}
+
+	if !sent {
+		// Ratelimit
+		s.txRateLimiter.Take()
+
+		if err := s.fc.SendTransaction(ctx, *flowTx); err != nil {
+			// The sequence number may have been left unused
+			keys.ReleaseProposalKey(s.km, flowTx)
+			return s.attemptFailed(ctx, tx, flowTx, nil, err)
+		}
+	}
+
+	return setAttemptStatus(s.store, tx, StatusPending, nil)
+}
+
+// prepareAttempt returns the Flow transaction to send or to wait for and
+// whether it has already been sent. If the latest attempt can no longer be
+// sealed, because it expired or failed with a sequence number error, the
+// transaction is rebuilt as a new attempt. Attempts are only rebuilt once the
+// previous attempt is known not to seal, so at most one attempt can seal.
+func (s *ServiceImpl) prepareAttempt(ctx context.Context, tx *Transaction) (*flow.Transaction, bool, error) {
+	flowTx, err := flow.DecodeTransaction(tx.FlowTransaction)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if tx.rebuildable() {
+		flowTx, err = s.rebuildTransaction(ctx, tx, flowTx)
+		return flowTx, false, err
+	}
+
+	// Check if transaction has been sent already.
+	if _, err := s.fc.GetTransaction(ctx, flowTx.ID()); err != nil {
 		rpcErr, ok := err.(grpc.RPCError)
 		if !ok {
 			// The error wasn't from gRPC.
-			return err
+			return nil, false, err
 		}
 
 		if rpcErr.GRPCStatus().Code() != codes.NotFound {
 			// Something unexpected went wrong in the gRPC call or in the Access API.
-			return err
+			return nil, false, err
 		}
 
 		// The Flow transaction was not found. All good. Continue.
+		return flowTx, false, nil
+	}
+
+	result, err := s.fc.GetTransactionResult(ctx, flowTx.ID())
+	if err != nil {
+		return nil, false, err
+	}
+
+	if result.Error != nil || result.Status == flow.TransactionStatusExpired {
+		// Record the outcome of the previous attempt
+		err := s.attemptFailed(ctx, tx, flowTx, result, result.Error)
+		if !tx.rebuildable() {
+			// Previous attempt failed for good, retrying will not help
+			return nil, false, jobs.PermanentFailure(err)
+		}
+
+		flowTx, err = s.rebuildTransaction(ctx, tx, flowTx)
+		return flowTx, false, err
+	}
+
+	return flowTx, true, nil
+}
+
+// rebuildTransaction builds and signs the script and arguments of prev again
+// with a fresh reference block and proposal key sequence number, and stores
+// it as the latest attempt of the transaction.
+func (s *ServiceImpl) rebuildTransaction(ctx context.Context, tx *Transaction, prev *flow.Transaction) (*flow.Transaction, error) {
+	authorizers := make([]string, len(prev.Authorizers))
+	for i, a := range prev.Authorizers {
+		authorizers[i] = flow_helpers.FormatAddress(a)
+	}
+
+	flowTx, err := s.buildRawFlowTransaction(ctx, authorizers, prev.Script, prev.Arguments, prev.GasLimit, false)
+	if err != nil {
+		return nil, fmt.Errorf("error while rebuilding transaction: %w", err)
+	}
+
+	attempt := TransactionAttempt{
+		TransactionId:     tx.TransactionId,
+		FlowTransactionId: flowTx.ID().Hex(),
+	}
+
+	if err := s.store.InsertAttempt(&attempt); err != nil {
+		keys.ReleaseProposalKey(s.km, flowTx)
+		return nil, err
 	}
 
-	// Ratelimit
-	s.txRateLimiter.Take()
+	tx.Attempts = append(tx.Attempts, attempt)
+	tx.FlowTransaction = flowTx.Encode()
 
-	resp, err := flow_helpers.SendAndWait(ctx, s.fc, *flowTx, s.cfg.TransactionTimeout)
+	if err := s.store.UpdateTransaction(tx); err != nil {
+		keys.ReleaseProposalKey(s.km, flowTx)
+		return nil, err
+	}
+
+	return flowTx, nil
+}
+
+// releaseProposalKey releases the proposal key sequence number of a
+// transaction which was built but never sent.
+func (s *ServiceImpl) releaseProposalKey(tx *Transaction) {
+	flowTx, err := flow.DecodeTransaction(tx.FlowTransaction)
 	if err != nil {
+		return
+	}
+	keys.ReleaseProposalKey(s.km, flowTx)
+}
+
+// attemptFailed records the outcome of a failed attempt and returns err.
+// Attempts that may still be sealed, e.g. when waiting for the result timed
+// out, are left as they are.
+func (s *ServiceImpl) attemptFailed(ctx context.Context, tx *Transaction, flowTx *flow.Transaction, result *flow.TransactionResult, err error) error {
+	keys.ResyncOnSequenceNumberError(ctx, s.km, flowTx, err)
+
+	var status string
+	switch {
+	case result != nil && result.Error != nil:
+		status = StatusFailed
+		if storeErr := storeResult(ctx, s.store, s.fc, tx, result); storeErr != nil {
+			return storeErr
+		}
+	case keys.IsSequenceNumberError(err):
+		status = StatusFailed
+	case result != nil && result.Status == flow.TransactionStatusExpired:
+		status = StatusExpired
+		if err == nil {
+			err = fmt.Errorf("transaction expired")
+		}
+		// The sequence number of an expired transaction is left unused
+		keys.ReleaseProposalKey(s.km, flowTx)
+	default:
 		return err
 	}
 
-	tx.Events = resp.Events
+	if updateErr := setAttemptStatus(s.store, tx, status, err); updateErr != nil {
+		return updateErr
+	}
 
-	return nil
+	return err
+}
+
+// setAttemptStatus updates the status of the transaction and its latest attempt.
+func setAttemptStatus(store Store, tx *Transaction, status string, attemptErr error) error {
+	tx.Status = status
+	tx.ResultError = ""
+	if attemptErr != nil {
+		tx.ResultError = attemptErr.Error()
+	}
+
+	if a := tx.latestAttempt(); a != nil {
+		a.Status = status
+		if attemptErr != nil {
+			a.Error = attemptErr.Error()
+		}
+		if err := store.UpdateAttempt(a); err != nil {
+			return err
+		}
+	}
+
+	return store.UpdateTransaction(tx)
 }
@@ -0,0 +1,131 @@
+package transactions
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/onflow/flow-go-sdk"
+	"golang.org/x/crypto/sha3"
+)
+
+// ScriptHash returns the hex encoded SHA3-256 hash of a transaction script
+// as used in the sponsorship policy.
+func ScriptHash(script []byte) string {
+	h := sha3.Sum256(script)
+	return hex.EncodeToString(h[:])
+}
+
+func (s *ServiceImpl) Sponsor(ctx context.Context, sync bool, encoded []byte) (*jobs.Job, *Transaction, error) {
+	flowTx, err := flow.DecodeTransaction(encoded)
+	if err != nil {
+		return nil, nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid transaction encoding"),
+		}
+	}
+
+	if err := s.checkSponsorshipPolicy(flowTx); err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := s.store.Transaction(flowTx.ID().Hex()); err == nil {
+		return nil, nil, &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("transaction has already been sponsored"),
+		}
+	}
+
+	admin, err := s.km.AdminAuthorizer(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while getting admin authorizer for payer: %w", err)
+	}
+
+	// Envelope signature does not change the transaction ID
+	if err := flowTx.SignEnvelope(admin.Address, admin.Key.Index, admin.Signer); err != nil {
+		return nil, nil, err
+	}
+
+	transaction := &Transaction{
+		TransactionId:   flowTx.ID().Hex(),
+		TransactionType: Sponsored,
+		ProposerAddress: flow_helpers.FormatAddress(flowTx.ProposalKey.Address),
+		FlowTransaction: flowTx.Encode(),
+		ComputeLimit:    flowTx.GasLimit,
+	}
+	transaction.Attempts = []TransactionAttempt{{
+		TransactionId:     transaction.TransactionId,
+		FlowTransactionId: transaction.TransactionId,
+	}}
+
+	return s.insertAndSend(ctx, sync, transaction)
+}
+
+// checkSponsorshipPolicy checks that a transaction signed by non-custodial
+// accounts may be paid for by the admin account.
+func (s *ServiceImpl) checkSponsorshipPolicy(flowTx *flow.Transaction) error {
+	admin := flow.HexToAddress(s.cfg.AdminAddress)
+
+	if flowTx.Payer != admin {
+		return &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("payer must be the admin account %s", flow_helpers.FormatAddress(admin)),
+		}
+	}
+
+	if len(flowTx.EnvelopeSignatures) > 0 {
+		return &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("transaction should not have envelope signatures"),
+		}
+	}
+
+	if len(flowTx.PayloadSignatures) == 0 {
+		return &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("transaction payload has not been signed"),
+		}
+	}
+
+	// The admin envelope signature would authorize the transaction on behalf
+	// of the admin account if it was the proposer or an authorizer
+	if flowTx.ProposalKey.Address == admin {
+		return &errors.RequestError{
+			StatusCode: http.StatusForbidden,
+			Err:        fmt.Errorf("admin account can not be the proposer of a sponsored transaction"),
+		}
+	}
+
+	for _, a := range flowTx.Authorizers {
+		if a == admin {
+			return &errors.RequestError{
+				StatusCode: http.StatusForbidden,
+				Err:        fmt.Errorf("admin account can not authorize a sponsored transaction"),
+			}
+		}
+	}
+
+	if flowTx.GasLimit > s.cfg.SponsorMaxGasLimit {
+		return &errors.RequestError{
+			StatusCode: http.StatusForbidden,
+			Err:        fmt.Errorf("gas limit %d exceeds the maximum of %d", flowTx.GasLimit, s.cfg.SponsorMaxGasLimit),
+		}
+	}
+
+	hash := ScriptHash(flowTx.Script)
+	for _, allowed := range s.cfg.SponsorAllowedScriptHashes {
+		if strings.EqualFold(strings.TrimPrefix(strings.TrimSpace(allowed), "0x"), hash) {
+			return nil
+		}
+	}
+
+	return &errors.RequestError{
+		StatusCode: http.StatusForbidden,
+		Err:        fmt.Errorf("script %s is not allowed to be sponsored", hash),
+	}
+}
@@ -0,0 +1,74 @@
+package transactions
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/onflow/flow-go-sdk"
+)
+
+func TestSponsorshipPolicy(t *testing.T) {
+	admin := flow.HexToAddress("0xf8d6e0586b0a20c7")
+	user := flow.HexToAddress("0x01cf0e2f2f715450")
+	script := []byte("transaction() { prepare(signer: AuthAccount) {} }")
+
+	svc := &ServiceImpl{cfg: &configs.Config{
+		AdminAddress:               admin.Hex(),
+		SponsorAllowedScriptHashes: []string{ScriptHash(script)},
+		SponsorMaxGasLimit:         9999,
+	}}
+
+	newTx := func() *flow.Transaction {
+		tx := flow.NewTransaction().
+			SetScript(script).
+			SetGasLimit(9999).
+			SetProposalKey(user, 0, 0).
+			SetPayer(admin).
+			AddAuthorizer(user)
+		tx.PayloadSignatures = []flow.TransactionSignature{{Address: user}}
+		return tx
+	}
+
+	cases := []struct {
+		name   string
+		modify func(tx *flow.Transaction)
+		status int
+	}{
+		{"valid", func(tx *flow.Transaction) {}, 0},
+		{"payer is not admin", func(tx *flow.Transaction) { tx.SetPayer(user) }, http.StatusBadRequest},
+		{"envelope signed", func(tx *flow.Transaction) {
+			tx.EnvelopeSignatures = []flow.TransactionSignature{{Address: admin}}
+		}, http.StatusBadRequest},
+		{"payload not signed", func(tx *flow.Transaction) { tx.PayloadSignatures = nil }, http.StatusBadRequest},
+		{"admin proposer", func(tx *flow.Transaction) { tx.SetProposalKey(admin, 0, 0) }, http.StatusForbidden},
+		{"admin authorizer", func(tx *flow.Transaction) { tx.AddAuthorizer(admin) }, http.StatusForbidden},
+		{"gas limit exceeded", func(tx *flow.Transaction) { tx.SetGasLimit(10000) }, http.StatusForbidden},
+		{"script not allowed", func(tx *flow.Transaction) { tx.SetScript([]byte("transaction() {}")) }, http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tx := newTx()
+			c.modify(tx)
+
+			err := svc.checkSponsorshipPolicy(tx)
+
+			if c.status == 0 {
+				if err != nil {
+					t.Fatalf("expected no error, got %s", err)
+				}
+				return
+			}
+
+			reqErr, ok := err.(*errors.RequestError)
+			if !ok {
+				t.Fatalf("expected a request error, got %v", err)
+			}
+			if reqErr.StatusCode != c.status {
+				t.Fatalf("expected status %d, got %d: %s", c.status, reqErr.StatusCode, reqErr.Err)
+			}
+		})
+	}
+}
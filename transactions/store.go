@@ -8,9 +8,23 @@ import (
 type Store interface {
 	Transactions(opt datastore.ListOptions) ([]Transaction, error)
 	Transaction(txId string) (Transaction, error)
+	// TransactionsByEventType returns transactions which emitted an event of the given type.
+	TransactionsByEventType(eventType string, opt datastore.ListOptions) ([]Transaction, error)
 	TransactionsForAccount(tType Type, address string, opt datastore.ListOptions) ([]Transaction, error)
 	TransactionForAccount(tType Type, address, txId string) (Transaction, error)
 	GetOrCreateTransaction(txId string) *Transaction
 	InsertTransaction(*Transaction) error
 	UpdateTransaction(*Transaction) error
+	// PendingTransactions returns transactions which have been sent but whose
+	// result is still pending, least recently checked first.
+	PendingTransactions(limit int) ([]Transaction, error)
+	InsertAttempt(*TransactionAttempt) error
+	UpdateAttempt(*TransactionAttempt) error
+	// ReplaceEvents replaces the stored events of a transaction.
+	ReplaceEvents(txId string, events []TransactionEvent) error
+	// AllowedCodes returns the code allowlist entries of a hash, or all
+	// entries if hash is empty.
+	AllowedCodes(hash string) ([]AllowedCode, error)
+	InsertAllowedCode(*AllowedCode) error
+	DeleteAllowedCode(id uint64) error
 }
@@ -2,7 +2,9 @@ package transactions
 
 import (
 	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/flow-hydraulics/flow-wallet-api/datastore/lib"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GormStore struct {
@@ -18,6 +20,7 @@ func NewGormStore(db *gorm.DB) Store {
 func (s *GormStore) Transactions(o datastore.ListOptions) (tt []Transaction, err error) {
 	q := &Transaction{}
 	err = s.db.
+		Preload("StoredEvents", orderEvents).
 		Where(q).
 		Order("created_at desc").
 		Limit(o.Limit).
@@ -28,7 +31,26 @@ func (s *GormStore) Transactions(o datastore.ListOptions) (tt []Transaction, err
 
 func (s *GormStore) Transaction(txId string) (t Transaction, err error) {
 	q := &Transaction{TransactionId: txId}
-	err = s.db.Where(q).First(&t).Error
+	err = s.db.
+		Preload("Attempts", orderAttempts).
+		Preload("StoredEvents", orderEvents).
+		Where(q).
+		First(&t).Error
+	return
+}
+
+func (s *GormStore) TransactionsByEventType(eventType string, o datastore.ListOptions) (tt []Transaction, err error) {
+	withEvent := s.db.
+		Model(&TransactionEvent{}).
+		Select("transaction_id").
+		Where("type = ?", eventType)
+	err = s.db.
+		Preload("StoredEvents", orderEvents).
+		Where("transaction_id IN (?)", withEvent).
+		Order("created_at desc").
+		Limit(o.Limit).
+		Offset(o.Offset).
+		Find(&tt).Error
 	return
 }
 
@@ -37,6 +59,7 @@ func (s *GormStore) Transaction(txId string) (t Transaction, err error) {
 func (s *GormStore) TransactionsForAccount(tType Type, address string, o datastore.ListOptions) (tt []Transaction, err error) {
 	q := &Transaction{ProposerAddress: address, TransactionType: tType}
 	err = s.db.
+		Preload("StoredEvents", orderEvents).
 		Where(q).
 		Order("created_at desc").
 		Limit(o.Limit).
@@ -47,7 +70,11 @@ func (s *GormStore) TransactionsForAccount(tType Type, address string, o datasto
 
 func (s *GormStore) TransactionForAccount(tType Type, address, txId string) (t Transaction, err error) {
 	q := &Transaction{ProposerAddress: address, TransactionType: tType, TransactionId: txId}
-	err = s.db.Where(q).First(&t).Error
+	err = s.db.
+		Preload("Attempts", orderAttempts).
+		Preload("StoredEvents", orderEvents).
+		Where(q).
+		First(&t).Error
 	return
 }
 
@@ -66,5 +93,74 @@ func (s *GormStore) InsertTransaction(t *Transaction) error {
 }
 
 func (s *GormStore) UpdateTransaction(t *Transaction) error {
-	return s.db.Save(t).Error
+	// Attempts are updated separately
+	return s.db.Omit(clause.Associations).Save(t).Error
+}
+
+func (s *GormStore) PendingTransactions(limit int) (tt []Transaction, err error) {
+	err = s.db.
+		Preload("Attempts", orderAttempts).
+		Where(&Transaction{Status: StatusPending}).
+		Order("updated_at asc").
+		Limit(limit).
+		Find(&tt).Error
+	return
+}
+
+// -- Attempts
+
+func orderAttempts(db *gorm.DB) *gorm.DB {
+	return db.Order("id asc")
+}
+
+func (s *GormStore) InsertAttempt(a *TransactionAttempt) error {
+	return s.db.Create(a).Error
+}
+
+func (s *GormStore) UpdateAttempt(a *TransactionAttempt) error {
+	return s.db.Save(a).Error
+}
+
+// -- Events
+
+func orderEvents(db *gorm.DB) *gorm.DB {
+	return db.Order("event_index asc")
+}
+
+func (s *GormStore) ReplaceEvents(txId string, events []TransactionEvent) error {
+	return lib.GormTransaction(s.db, func(tx *gorm.DB) error {
+		if err := tx.Where("transaction_id = ?", txId).Delete(&TransactionEvent{}).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		return tx.Create(&events).Error
+	})
+}
+
+// -- Code allowlist
+
+func (s *GormStore) AllowedCodes(hash string) (aa []AllowedCode, err error) {
+	q := s.db.Order("id asc")
+	if hash != "" {
+		q = q.Where(&AllowedCode{Hash: hash})
+	}
+	err = q.Find(&aa).Error
+	return
+}
+
+func (s *GormStore) InsertAllowedCode(a *AllowedCode) error {
+	return s.db.Omit("ID").Create(a).Error
+}
+
+func (s *GormStore) DeleteAllowedCode(id uint64) error {
+	res := s.db.Delete(&AllowedCode{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
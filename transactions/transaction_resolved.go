@@ -0,0 +1,40 @@
+package transactions
+
+import (
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+)
+
+type TransactionResolvedPayload struct {
+	TransactionId string
+	Status        string
+	Error         error
+	Events        []flow.Event
+}
+
+type transactionResolvedHandler interface {
+	Handle(TransactionResolvedPayload)
+}
+
+type transactionResolved struct {
+	handlers []transactionResolvedHandler
+}
+
+var TransactionResolved transactionResolved // singleton of type transactionResolved
+
+// Register adds an event handler for this event
+func (e *transactionResolved) Register(handler transactionResolvedHandler) {
+	log.Debug("Registering TransactionResolved event handler")
+	e.handlers = append(e.handlers, handler)
+}
+
+// Trigger sends out an event with the payload
+func (e *transactionResolved) Trigger(payload TransactionResolvedPayload) {
+	log.
+		WithFields(log.Fields{"payload": payload}).
+		Trace("Handling TransactionResolved event")
+
+	for _, handler := range e.handlers {
+		go handler.Handle(payload)
+	}
+}
@@ -1,14 +1,25 @@
 package transactions
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
 	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
-const maxGasLimit = 9999
+// Transaction statuses as tracked by the seal tracker.
+const (
+	StatusPending = "PENDING"
+	StatusSealed  = "SEALED"
+	StatusExpired = "EXPIRED"
+	StatusFailed  = "FAILED"
+)
 
 type SignedTransaction struct {
 	flow.Transaction
@@ -86,36 +97,212 @@ type Transaction struct {
 	TransactionType Type           `gorm:"column:transaction_type;index"`
 	ProposerAddress string         `gorm:"column:proposer_address;index"`
 	FlowTransaction []byte         `gorm:"column:flow_transaction;type:bytes"`
+	Status          string         `gorm:"column:status;index"`
+	ResultError     string         `gorm:"column:result_error"`
+	BlockId         string         `gorm:"column:block_id"`
+	BlockHeight     uint64         `gorm:"column:block_height"`
+	ComputeLimit    uint64         `gorm:"column:compute_limit"`
+	ExecutionEffort uint64         `gorm:"column:execution_effort"`
 	CreatedAt       time.Time      `gorm:"column:created_at"`
 	UpdatedAt       time.Time      `gorm:"column:updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"column:deleted_at;index"`
 	Events          []flow.Event   `gorm:"-"`
+	// Attempts holds the Flow transactions sent on behalf of this
+	// transaction, oldest first. FlowTransaction is the latest attempt.
+	Attempts []TransactionAttempt `gorm:"foreignKey:TransactionId;references:TransactionId"`
+	// StoredEvents holds the events of the transaction once its result has
+	// been stored.
+	StoredEvents []TransactionEvent `gorm:"foreignKey:TransactionId;references:TransactionId"`
 }
 
 func (Transaction) TableName() string {
 	return "transactions"
 }
 
+// TransactionAttempt is the database model for a single Flow transaction
+// sent on behalf of a Transaction.
+type TransactionAttempt struct {
+	ID                uint64    `gorm:"column:id;primaryKey"`
+	TransactionId     string    `gorm:"column:transaction_id;index"`
+	FlowTransactionId string    `gorm:"column:flow_transaction_id;uniqueIndex"`
+	Status            string    `gorm:"column:status"`
+	Error             string    `gorm:"column:error"`
+	CreatedAt         time.Time `gorm:"column:created_at"`
+	UpdatedAt         time.Time `gorm:"column:updated_at"`
+}
+
+func (TransactionAttempt) TableName() string {
+	return "transaction_attempts"
+}
+
+// TransactionEvent is the database model for an event emitted by a
+// sealed transaction.
+type TransactionEvent struct {
+	ID                uint64    `gorm:"column:id;primaryKey"`
+	TransactionId     string    `gorm:"column:transaction_id;index"`
+	FlowTransactionId string    `gorm:"column:flow_transaction_id"`
+	Type              string    `gorm:"column:type;index"`
+	TransactionIndex  int       `gorm:"column:transaction_index"`
+	EventIndex        int       `gorm:"column:event_index"`
+	Payload           []byte    `gorm:"column:payload;type:bytes"`
+	CreatedAt         time.Time `gorm:"column:created_at"`
+}
+
+func (TransactionEvent) TableName() string {
+	return "transaction_events"
+}
+
+// FlowEvent decodes the stored event.
+func (e TransactionEvent) FlowEvent() (flow.Event, error) {
+	value, err := jsoncdc.Decode(nil, e.Payload)
+	if err != nil {
+		return flow.Event{}, err
+	}
+
+	event, ok := value.(cadence.Event)
+	if !ok {
+		return flow.Event{}, fmt.Errorf("stored payload of event %d is not an event", e.ID)
+	}
+
+	return flow.Event{
+		Type:             e.Type,
+		TransactionID:    flow.HexToID(e.FlowTransactionId),
+		TransactionIndex: e.TransactionIndex,
+		EventIndex:       e.EventIndex,
+		Value:            event,
+		Payload:          e.Payload,
+	}, nil
+}
+
+// events returns the events of the transaction, decoding stored events if
+// the events have not been fetched from chain.
+func (t Transaction) events() []flow.Event {
+	if len(t.Events) > 0 || len(t.StoredEvents) == 0 {
+		return t.Events
+	}
+
+	events := make([]flow.Event, 0, len(t.StoredEvents))
+	for _, e := range t.StoredEvents {
+		event, err := e.FlowEvent()
+		if err != nil {
+			log.
+				WithFields(log.Fields{"error": err, "transactionId": t.TransactionId}).
+				Warn("Could not decode stored event")
+			continue
+		}
+		events = append(events, event)
+	}
+
+	return events
+}
+
+// rebuildable tells if the attempt can no longer be sealed and the
+// transaction should be rebuilt with a fresh reference block and
+// proposal key sequence number.
+func (a TransactionAttempt) rebuildable() bool {
+	switch a.Status {
+	case StatusExpired:
+		return true
+	case StatusFailed:
+		return keys.IsSequenceNumberError(errors.New(a.Error))
+	default:
+		return false
+	}
+}
+
+// rebuildable tells if the transaction should be rebuilt as a new attempt.
+// Sponsored transactions are signed by their users and can not be rebuilt.
+func (t *Transaction) rebuildable() bool {
+	if t.TransactionType == Sponsored {
+		return false
+	}
+	a := t.latestAttempt()
+	return a != nil && a.rebuildable()
+}
+
+// latestAttempt returns the latest attempt of the transaction or nil for
+// transactions created before attempts were tracked.
+func (t *Transaction) latestAttempt() *TransactionAttempt {
+	if len(t.Attempts) == 0 {
+		return nil
+	}
+	return &t.Attempts[len(t.Attempts)-1]
+}
+
+// flowID returns the ID of the latest Flow transaction sent on behalf of
+// the transaction.
+func (t *Transaction) flowID() flow.Identifier {
+	if a := t.latestAttempt(); a != nil {
+		return flow.HexToID(a.FlowTransactionId)
+	}
+	return flow.HexToID(t.TransactionId)
+}
+
 // Transaction JSON HTTP request
 type JSONRequest struct {
 	Code      string     `json:"code"`
 	Arguments []Argument `json:"arguments"`
+	// Authorizers are the addresses of additional custodial accounts
+	// authorizing the transaction
+	Authorizers []string `json:"authorizers,omitempty"`
+	// ComputeLimit overrides the default compute limit of the transaction,
+	// bounded by the configured maximum
+	ComputeLimit uint64 `json:"computeLimit,omitempty"`
+}
+
+// Sponsored transaction JSON HTTP request
+type SponsorJSONRequest struct {
+	// Transaction is the hex encoded RLP of a transaction with the admin
+	// account as payer and a signed payload
+	Transaction string `json:"transaction"`
 }
 
 // Transaction JSON HTTP response
 type JSONResponse struct {
-	TransactionId   string       `json:"transactionId"`
-	TransactionType Type         `json:"transactionType"`
-	Events          []flow.Event `json:"events,omitempty"`
-	CreatedAt       time.Time    `json:"createdAt"`
-	UpdatedAt       time.Time    `json:"updatedAt"`
+	TransactionId   string                `json:"transactionId"`
+	TransactionType Type                  `json:"transactionType"`
+	Status          string                `json:"status,omitempty"`
+	Error           string                `json:"error,omitempty"`
+	BlockId         string                `json:"blockId,omitempty"`
+	BlockHeight     uint64                `json:"blockHeight,omitempty"`
+	ComputeLimit    uint64                `json:"computeLimit,omitempty"`
+	ExecutionEffort uint64                `json:"executionEffort,omitempty"`
+	Events          []flow.Event          `json:"events,omitempty"`
+	Attempts        []AttemptJSONResponse `json:"attempts,omitempty"`
+	CreatedAt       time.Time             `json:"createdAt"`
+	UpdatedAt       time.Time             `json:"updatedAt"`
+}
+
+// Transaction attempt JSON HTTP response
+type AttemptJSONResponse struct {
+	FlowTransactionId string    `json:"flowTransactionId"`
+	Status            string    `json:"status,omitempty"`
+	Error             string    `json:"error,omitempty"`
+	CreatedAt         time.Time `json:"createdAt"`
 }
 
 func (t Transaction) ToJSONResponse() JSONResponse {
+	var attempts []AttemptJSONResponse
+	for _, a := range t.Attempts {
+		attempts = append(attempts, AttemptJSONResponse{
+			FlowTransactionId: a.FlowTransactionId,
+			Status:            a.Status,
+			Error:             a.Error,
+			CreatedAt:         a.CreatedAt,
+		})
+	}
+
 	return JSONResponse{
 		TransactionId:   t.TransactionId,
 		TransactionType: t.TransactionType,
-		Events:          t.Events,
+		Status:          t.Status,
+		Error:           t.ResultError,
+		BlockId:         t.BlockId,
+		BlockHeight:     t.BlockHeight,
+		ComputeLimit:    t.ComputeLimit,
+		ExecutionEffort: t.ExecutionEffort,
+		Events:          t.events(),
+		Attempts:        attempts,
 		CreatedAt:       t.CreatedAt,
 		UpdatedAt:       t.UpdatedAt,
 	}
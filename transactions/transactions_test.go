@@ -0,0 +1,177 @@
+package transactions
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+func TestAttemptRebuildable(t *testing.T) {
+	cases := []struct {
+		attempt  TransactionAttempt
+		expected bool
+	}{
+		{TransactionAttempt{}, false},
+		{TransactionAttempt{Status: StatusPending}, false},
+		{TransactionAttempt{Status: StatusSealed}, false},
+		{TransactionAttempt{Status: StatusExpired}, true},
+		{TransactionAttempt{Status: StatusFailed, Error: "[Error Code: 1101] cadence runtime error"}, false},
+		{TransactionAttempt{Status: StatusFailed, Error: "[Error Code: 1007] invalid proposal key: public key 0 on account f8d6e0586b0a20c7 has sequence number 7, but given 6"}, true},
+	}
+
+	for _, c := range cases {
+		if got := c.attempt.rebuildable(); got != c.expected {
+			t.Errorf("expected rebuildable() == %t for %+v, got %t", c.expected, c.attempt, got)
+		}
+	}
+}
+
+func TestTransactionFlowID(t *testing.T) {
+	tx := Transaction{TransactionId: "0000000000000000000000000000000000000000000000000000000000000001"}
+
+	if tx.flowID().Hex() != tx.TransactionId {
+		t.Fatalf("expected flow ID of a transaction without attempts to match its ID, got %s", tx.flowID().Hex())
+	}
+
+	tx.Attempts = []TransactionAttempt{
+		{FlowTransactionId: tx.TransactionId},
+		{FlowTransactionId: "0000000000000000000000000000000000000000000000000000000000000002"},
+	}
+
+	if tx.flowID().Hex() != tx.Attempts[1].FlowTransactionId {
+		t.Fatalf("expected flow ID to match the latest attempt, got %s", tx.flowID().Hex())
+	}
+}
+
+func TestNormalizeCode(t *testing.T) {
+	code := "transaction(greeting: String) {\n  prepare(signer: AuthAccount) {}\n  execute { log(greeting.concat(\",  World!\")) }\n}"
+
+	formatted := "// Say hello\ntransaction(greeting: String) {\r\n\tprepare(signer: AuthAccount) {} /* nothing /* nested */ */\n\n\texecute { log(greeting.concat(\",  World!\")) } // log it\n}\n"
+
+	if CodeHash(code) != CodeHash(formatted) {
+		t.Fatalf("expected formatting and comments to not change the hash:\n%s\n%s", NormalizeCode(code), NormalizeCode(formatted))
+	}
+
+	changed := "transaction(greeting: String) {\n  prepare(signer: AuthAccount) {}\n  execute { log(greeting.concat(\", World!\")) }\n}"
+
+	if CodeHash(code) == CodeHash(changed) {
+		t.Fatal("expected whitespace inside string literals to change the hash")
+	}
+}
+
+func TestCodeAllowedFor(t *testing.T) {
+	scoped := []AllowedCode{{Address: "0x01cf0e2f2f715450"}}
+
+	if !codeAllowedFor(scoped, "0x01cf0e2f2f715450") {
+		t.Error("expected code to be allowed for the scoped account")
+	}
+
+	if codeAllowedFor(scoped, "0x179b6b1cb6755e31") {
+		t.Error("expected code to not be allowed for other accounts")
+	}
+
+	if !codeAllowedFor(append(scoped, AllowedCode{}), "0x179b6b1cb6755e31") {
+		t.Error("expected an entry without an address to allow all accounts")
+	}
+}
+
+func TestComputeLimit(t *testing.T) {
+	svc := &ServiceImpl{cfg: &configs.Config{
+		DefaultComputeLimit: 9999,
+		MaxComputeLimit:     5000,
+		ComputeLimits:       []string{"fttransfer:200"},
+	}}
+
+	cases := []struct {
+		name     string
+		tType    Type
+		opts     []TransactionOption
+		expected uint64
+		err      bool
+	}{
+		{"default", General, nil, 9999, false},
+		{"configured type", FtTransfer, nil, 200, false},
+		{"requested", FtTransfer, []TransactionOption{WithComputeLimit(1000)}, 1000, false},
+		{"requested zero", General, []TransactionOption{WithComputeLimit(0)}, 9999, false},
+		{"requested above maximum", General, []TransactionOption{WithComputeLimit(5001)}, 0, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := svc.computeLimit(c.tType, c.opts...)
+			if c.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.expected {
+				t.Errorf("expected compute limit %d, got %d", c.expected, got)
+			}
+		})
+	}
+}
+
+type closedAccounts map[string]bool
+
+func (c closedAccounts) CheckAuthorizer(address string) error {
+	if c[address] {
+		return fmt.Errorf("account %s is closed", address)
+	}
+	return nil
+}
+
+func TestCheckAuthorizers(t *testing.T) {
+	svc := &ServiceImpl{guard: closedAccounts{"0x02": true}}
+
+	if err := svc.checkAuthorizers([]string{"0x01"}); err != nil {
+		t.Fatalf("expected an open account to pass, got %s", err)
+	}
+
+	if err := svc.checkAuthorizers([]string{"0x01", "0x02"}); err == nil {
+		t.Fatal("expected a closed authorizer to be rejected")
+	}
+
+	if err := svc.checkAuthorizers([]string{"0x02"}, IgnoreAccountGuard()); err != nil {
+		t.Fatalf("expected the guard to be ignored, got %s", err)
+	}
+
+	svc.guard = nil
+	if err := svc.checkAuthorizers([]string{"0x02"}); err != nil {
+		t.Fatalf("expected no check without a guard, got %s", err)
+	}
+}
+
+func TestExecutionEffort(t *testing.T) {
+	feesDeducted := cadence.NewEvent([]cadence.Value{
+		cadence.UFix64(1),
+		cadence.UFix64(100_000_000),
+		cadence.UFix64(42),
+	}).WithType(&cadence.EventType{
+		QualifiedIdentifier: "FlowFees.FeesDeducted",
+		Fields: []cadence.Field{
+			{Identifier: "amount", Type: cadence.UFix64Type{}},
+			{Identifier: "inclusionEffort", Type: cadence.UFix64Type{}},
+			{Identifier: "executionEffort", Type: cadence.UFix64Type{}},
+		},
+	})
+
+	events := []flow.Event{
+		{Type: "A.f8d6e0586b0a20c7.FlowToken.TokensWithdrawn"},
+		{Type: "A.e5a8b7f23e8b548f.FlowFees.FeesDeducted", Value: feesDeducted},
+	}
+
+	if got := executionEffort(events); got != 42 {
+		t.Errorf("expected execution effort 42, got %d", got)
+	}
+
+	if got := executionEffort(events[:1]); got != 0 {
+		t.Errorf("expected execution effort 0 without fees, got %d", got)
+	}
+}
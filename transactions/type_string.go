@@ -14,11 +14,15 @@ func _() {
 	_ = x[FtTransfer-3]
 	_ = x[NftSetup-4]
 	_ = x[NftTransfer-5]
+	_ = x[Sponsored-6]
+	_ = x[ContractDeploy-7]
+	_ = x[ContractUpdate-8]
+	_ = x[ContractRemove-9]
 }
 
-const _Type_name = "UnknownGeneralFtSetupFtTransferNftSetupNftTransfer"
+const _Type_name = "UnknownGeneralFtSetupFtTransferNftSetupNftTransferSponsoredContractDeployContractUpdateContractRemove"
 
-var _Type_index = [...]uint8{0, 7, 14, 21, 31, 39, 50}
+var _Type_index = [...]uint8{0, 7, 14, 21, 31, 39, 50, 59, 73, 87, 101}
 
 func (i Type) String() string {
 	if i < 0 || i >= Type(len(_Type_index)-1) {
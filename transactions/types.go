@@ -12,6 +12,10 @@ const (
 	FtTransfer
 	NftSetup
 	NftTransfer
+	Sponsored
+	ContractDeploy
+	ContractUpdate
+	ContractRemove
 )
 
 func (s Type) MarshalText() ([]byte, error) {
@@ -37,5 +41,13 @@ func StatusFromText(text string) Type {
 		return NftSetup
 	case "nfttransfer":
 		return NftTransfer
+	case "sponsored":
+		return Sponsored
+	case "contractdeploy":
+		return ContractDeploy
+	case "contractupdate":
+		return ContractUpdate
+	case "contractremove":
+		return ContractRemove
 	}
 }